@@ -5,37 +5,109 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/github"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/gitlab"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/health"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/httpclient"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/metrics"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/notify"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/report"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/state"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/yaml.v3"
 )
 
 var version = "dev"
 
 func main() {
-	configPath := flag.String("config", "", "Path to configuration file")
+	os.Exit(run())
+}
+
+// run runs the scanner and returns the process exit code, so its deferred cleanup (closing the
+// scanner and log files, flushing traces) completes before main exits.
+func run() int {
+	var configPaths configFiles
+	flag.Var(&configPaths, "config", "Path to a configuration file; repeat or comma-separate to overlay files in order")
 	showVersion := flag.Bool("version", false, "Show version and exit")
+	check := flag.Bool("check", false, "Verify the nova binary is available, print its version and exit")
+	validateOnly := flag.Bool("validate-config", false, "Load and validate the config, print the effective settings and exit")
+	printOnly := flag.Bool("print-config", false, "Print the effective config (files, environment and defaults) as YAML with secrets redacted and exit")
 	flag.Parse()
 
 	if *showVersion {
 		println("nova-scanner version:", version)
-		os.Exit(0)
+		return exitOK
+	}
+
+	// Validate without touching the cluster or GitHub, e.g. in CI before deploying
+	if *validateOnly {
+		if err := validateConfig(configPaths, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid config:", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	// Show the merged config, e.g. to find out which setting a behavior comes from
+	if *printOnly {
+		if err := printConfig(configPaths, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid config:", err)
+			return exitError
+		}
+		return exitOK
 	}
 
-	// Load configuration
-	cfg, err := config.Load(*configPath)
+	// Load configuration (--check only runs nova, so it needs no issue tracker credentials)
+	var cfg *config.Config
+	var err error
+	if *check {
+		cfg, err = config.LoadForCheck(configPaths...)
+	} else {
+		cfg, err = loadConfig(configPaths)
+	}
 	if err != nil {
 		println("Error loading config:", err.Error())
-		os.Exit(1)
+		return exitError
+	}
+
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "nova-scanner/" + version
 	}
 
-	// Initialize logger
-	logger := logging.NewLogger(cfg.LogLevel)
+	// Initialize logger (stderr keeps stdout clean for markdown reports)
+	logger := logging.NewLoggerWithWriter(cfg.LogLevel, cfg.LogFormat, logging.Output(cfg.HumanLogTo)).
+		WithComponentLevels(cfg.ComponentLogLevels)
+	if cfg.LogFile != "" {
+		f, err := logging.OpenLogFile(cfg.LogFile)
+		if err != nil {
+			logger.Warn().Err(err).Str("file", cfg.LogFile).Msg("Failed to open log file, logging without it")
+		} else {
+			defer f.Close()
+			logger = logger.WithLogFile(f)
+		}
+	}
+	if cfg.EventLogFile != "" {
+		f, err := logging.OpenLogFile(cfg.EventLogFile)
+		if err != nil {
+			logger.Warn().Err(err).Str("file", cfg.EventLogFile).Msg("Failed to open event log file, continuing without it")
+		} else {
+			defer f.Close()
+			logger = logger.WithEventLog(f)
+		}
+	}
 	logger.Info().
 		Str("version", version).
 		Bool("dry_run", cfg.DryRun).
@@ -44,136 +116,769 @@ func main() {
 		Str("min_severity", cfg.MinSeverity).
 		Str("output_mode", cfg.OutputMode).
 		Msg("Nova scanner starting")
+	if cfg.InsecureSkipVerify {
+		logger.Warn().Msg("TLS certificate verification is DISABLED for all outbound requests (insecureSkipVerify); never use this in production")
+	}
+
+	// Initialize tracing (a no-op unless otlpEndpoint is set)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.OTLPEndpoint, version)
+	if err != nil {
+		logger.Warn().Err(err).Str("endpoint", cfg.OTLPEndpoint).Msg("Failed to set up tracing, continuing without it")
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+	defer flushTracing(shutdownTracing, logger)
 
 	// Initialize metrics
-	m := metrics.NewMetrics(cfg.PushgatewayURL, cfg.JobName)
+	m := metrics.NewMetrics(cfg.PushgatewayURL, cfg.JobName, cfg.MetricPrefix)
+	if cfg.PushgatewayUsername != "" {
+		m.SetBasicAuth(cfg.PushgatewayUsername, cfg.PushgatewayPassword)
+	}
+	if cfg.PushgatewayBearerToken != "" {
+		m.SetBearerToken(cfg.PushgatewayBearerToken)
+	}
+	m.SetTransport(httpclient.FromConfig(cfg))
 	m.Reset() // Clear any stale version info metrics
+	m.RecordScannerInfo(version, cfg.MinSeverity, cfg.ScanHelm, cfg.ScanContainers)
+
+	// In dry-run, render metrics for the log instead of pushing them
+	var dryRunMetrics strings.Builder
+	if cfg.DryRun {
+		m.SetDryRun(&dryRunMetrics)
+	}
 
 	// Initialize scanner
 	scanner, err := nova.NewScanner(cfg, logger)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create scanner")
-		os.Exit(1)
+		return exitError
 	}
+	defer func() {
+		if err := scanner.Close(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to close scanner")
+		}
+	}()
 
 	ctx := context.Background()
-	var hadError bool
+
+	if *check {
+		novaVersion, err := scanner.Version(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("Nova check failed")
+			return exitError
+		}
+		fmt.Println(novaVersion)
+		return exitOK
+	}
 
 	// Handle markdown output mode
 	if cfg.IsMarkdownMode() {
-		if err := runMarkdownMode(ctx, cfg, scanner, logger); err != nil {
+		outdated, err := runMarkdownMode(ctx, cfg, scanner, logger)
+		if err != nil {
 			logger.Error().Err(err).Msg("Failed to generate markdown output")
-			os.Exit(1)
+			return exitError
 		}
-		return
+		pushHeartbeat(ctx, cfg, m, logger, &dryRunMetrics)
+		return exitCode(true, outdated, cfg.FailOnOutdated)
+	}
+
+	// Handle SARIF output mode
+	if cfg.IsSARIFMode() {
+		outdated, err := runSARIFMode(ctx, cfg, scanner, logger)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to generate SARIF output")
+			return exitError
+		}
+		pushHeartbeat(ctx, cfg, m, logger, &dryRunMetrics)
+		return exitCode(true, outdated, cfg.FailOnOutdated)
+	}
+
+	// Handle CSV output mode
+	if cfg.IsCSVMode() {
+		outdated, err := runCSVMode(ctx, cfg, scanner, logger)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to generate CSV output")
+			return exitError
+		}
+		pushHeartbeat(ctx, cfg, m, logger, &dryRunMetrics)
+		return exitCode(true, outdated, cfg.FailOnOutdated)
+	}
+
+	// GitHub mode: scan once, or on every scanInterval until SIGTERM/SIGINT. A signal cancels
+	// the scan in flight; runScan still pushes the metrics it recorded.
+	ctx, stop := shutdownContext(ctx)
+	defer stop()
+
+	// Serve the probes from the start, so the liveness probe passes during the start jitter
+	var checker *health.Checker
+	if cfg.ScanInterval > 0 {
+		checker = health.NewChecker(cfg.HealthFailureThreshold)
+		if cfg.HealthAddr != "" {
+			go func() {
+				if err := checker.Serve(ctx, cfg.HealthAddr); err != nil {
+					logger.Error().Err(err).Str("addr", cfg.HealthAddr).Msg("Health server stopped")
+				}
+			}()
+		}
+	}
+
+	// Spread out scanners sharing a schedule before they hit GitHub and the Pushgateway
+	if cfg.StartJitter > 0 {
+		delay := startDelay(cfg.StartJitter, rand.Int63n)
+		logger.Info().Dur("delay", delay).Msg("Delaying start")
+		if err := sleepContext(ctx, delay); err != nil {
+			logger.Info().Msg("Nova scanner stopped")
+			return exitOK
+		}
+	}
+
+	if cfg.ScanInterval > 0 {
+		logger.Info().Dur("interval", cfg.ScanInterval).Str("health_addr", cfg.HealthAddr).Msg("Running in daemon mode")
+		runEvery(ctx, cfg.ScanInterval, func(ctx context.Context) {
+			m.Reset() // Info metrics describe the current cycle only; counters accumulate
+			dryRunMetrics.Reset()
+			ok, _ := runScan(ctx, cfg, scanner, m, logger, &dryRunMetrics)
+			checker.RecordScan(ok)
+		})
+		logger.Info().Msg("Nova scanner stopped")
+		return exitOK
+	}
+
+	ok, outdated := runScan(ctx, cfg, scanner, m, logger, &dryRunMetrics)
+	return exitCode(ok, outdated, cfg.FailOnOutdated)
+}
+
+// Exit codes of a one-shot run.
+const (
+	exitOK       = 0
+	exitError    = 1 // a scan failed, or the scanner could not start
+	exitOutdated = 2 // all scans succeeded and found outdated components (with failOnOutdated)
+)
+
+// exitCode returns the exit code of a one-shot run: scan failures take precedence over
+// outdated components, which only fail the run with failOnOutdated.
+func exitCode(ok bool, outdated int, failOnOutdated bool) int {
+	switch {
+	case !ok:
+		return exitError
+	case failOnOutdated && outdated > 0:
+		return exitOutdated
+	default:
+		return exitOK
+	}
+}
+
+// configFiles collects the config files given by repeated or comma-separated -config flags.
+type configFiles []string
+
+func (f *configFiles) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *configFiles) Set(value string) error {
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			*f = append(*f, path)
+		}
+	}
+	return nil
+}
+
+// loadConfig loads the config from paths and checks its issue templates, whose functions are
+// provided by the github package.
+func loadConfig(paths []string) (*config.Config, error) {
+	cfg, err := config.Load(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if err := github.ValidateIssueTemplates(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// printConfig loads the config from paths and writes it to w as YAML, with secrets redacted.
+func printConfig(paths []string, w io.Writer) error {
+	cfg, err := loadConfig(paths)
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	_, err = w.Write(out)
+	return err
+}
 
-	// GitHub mode: Initialize issue manager
-	issueManager := github.NewIssueManager(
-		cfg.GitHubToken,
-		cfg.GitHubOwner,
-		cfg.GitHubRepo,
-		cfg.DryRun,
-		logger,
+// validateConfig loads and validates the config from paths and prints a summary of the effective
+// settings to w. Credentials and endpoint URLs are reported as set or not set, never printed.
+func validateConfig(paths []string, w io.Writer) error {
+	cfg, err := loadConfig(paths)
+	if err != nil {
+		return err
+	}
+
+	source := strings.Join(paths, ", ")
+	if source == "" {
+		source = "environment only"
+	}
+	contexts := strings.Join(cfg.Contexts, ", ")
+	if contexts == "" {
+		contexts = cfg.Context
+	}
+	if contexts == "" {
+		contexts = "current context"
+	}
+	scanInterval := "run once"
+	if cfg.ScanInterval > 0 {
+		scanInterval = cfg.ScanInterval.String()
+	}
+	githubToken := setOrNot(cfg.GitHubToken)
+	if cfg.GitHubTokenFile != "" {
+		githubToken += " (from " + cfg.GitHubTokenFile + ")"
+	}
+
+	fmt.Fprintf(w, "Config OK (%s)\n\n", source)
+	settings := [][2]string{
+		{"outputMode", cfg.OutputMode},
+		{"contexts", contexts},
+		{"scanHelm", fmt.Sprint(cfg.ScanHelm)},
+		{"scanContainers", fmt.Sprint(cfg.ScanContainers)},
+		{"minSeverity", cfg.MinSeverity},
+		{"scanInterval", scanInterval},
+		{"scanTimeout", cfg.ScanTimeout.String()},
+	}
+	if !cfg.IsMarkdownMode() && !cfg.IsSARIFMode() && !cfg.IsCSVMode() {
+		if cfg.IsGitLabBackend() {
+			settings = append(settings,
+				[2]string{"issueBackend", cfg.IssueBackend},
+				[2]string{"gitlabProject", strings.TrimSuffix(cfg.GitLabURL, "/") + " " + cfg.GitLabProjectID},
+				[2]string{"gitlabToken", setOrNot(cfg.GitLabToken)},
+			)
+		} else {
+			settings = append(settings,
+				[2]string{"githubRepo", cfg.GitHubOwner + "/" + cfg.GitHubRepo},
+				[2]string{"githubToken", githubToken},
+				[2]string{"repoRouting", fmt.Sprintf("%d namespaces", len(cfg.RepoRouting))},
+			)
+		}
+		settings = append(settings,
+			[2]string{"groupBy", cfg.GroupBy},
+			[2]string{"gitOpsTool", cfg.GitOpsTool},
+			[2]string{"dryRun", fmt.Sprint(cfg.DryRun)},
+		)
+	}
+	settings = append(settings,
+		[2]string{"pushgatewayUrl", setOrNot(cfg.PushgatewayURL)},
+		[2]string{"slackWebhookUrl", setOrNot(cfg.SlackWebhookURL)},
+		[2]string{"webhookUrl", setOrNot(cfg.WebhookURL)},
+		[2]string{"otlpEndpoint", setOrNot(cfg.OTLPEndpoint)},
+		[2]string{"logLevel", cfg.LogLevel},
 	)
+	for _, setting := range settings {
+		fmt.Fprintf(w, "  %-16s %s\n", setting[0]+":", setting[1])
+	}
+	return nil
+}
+
+// setOrNot describes whether a secret or endpoint is configured without revealing it.
+func setOrNot(value string) string {
+	if value == "" {
+		return "not set"
+	}
+	return "set"
+}
+
+// flushTracing exports any buffered spans before the process exits.
+func flushTracing(shutdown func(context.Context) error, logger *logging.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		logger.Warn().Err(err).Msg("Failed to flush traces")
+	}
+}
+
+// startDelay returns a random delay in [0, maxDelay], drawn with randInt63n (e.g. rand.Int63n).
+func startDelay(maxDelay time.Duration, randInt63n func(n int64) int64) time.Duration {
+	return time.Duration(randInt63n(int64(maxDelay) + 1))
+}
+
+// sleepContext waits for d, returning early with the context error if ctx is canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	// Track namespaces with outdated Helm releases for container deduplication
-	var outdatedHelmNamespaces map[string]bool
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// runEvery calls run immediately and then every interval until ctx is canceled.
+func runEvery(ctx context.Context, interval time.Duration, run func(ctx context.Context)) {
+	for {
+		run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
 
-	// Scan Helm charts
+// IssueBackend creates and deduplicates the issues of outdated components in an issue tracker.
+type IssueBackend interface {
+	// CreateHelmIssue, CreateContainerIssue and CreateNamespaceIssue create the issue of a
+	// component unless it already exists, returning the URL of a created issue.
+	CreateHelmIssue(ctx context.Context, release nova.ReleaseOutput) (string, error)
+	CreateContainerIssue(ctx context.Context, container nova.ContainerOutput) (string, error)
+	CreateNamespaceIssue(ctx context.Context, group github.NamespaceGroup) (string, error)
+	CreateSummaryIssue(ctx context.Context, now time.Time) (string, error)
+	// CloseResolvedIssues closes the issues of components not outdated in a successful run's result.
+	CloseResolvedIssues(ctx context.Context, result *nova.RunResult) error
+	// SkippedIssues returns the number of issues not created because they exist or are suppressed.
+	SkippedIssues() int
+	// RateLimit returns the API quota reported by the latest response, if tracked.
+	RateLimit() (remaining, limit int, ok bool)
+}
+
+var (
+	_ IssueBackend = (*github.IssueManager)(nil)
+	_ IssueBackend = (*gitlab.IssueManager)(nil)
+)
+
+// newIssueBackend returns the issue manager of the configured issue backend.
+func newIssueBackend(ctx context.Context, cfg *config.Config, logger *logging.Logger) IssueBackend {
+	if cfg.IsGitLabBackend() {
+		return gitlab.NewIssueManager(cfg, logger)
+	}
+	return github.NewIssueManager(ctx, cfg, logger)
+}
+
+// metricsFlushTimeout bounds the final metrics push of a run canceled by a shutdown signal.
+const metricsFlushTimeout = 5 * time.Second
+
+// shutdownContext returns a copy of ctx that is canceled on SIGTERM or SIGINT.
+func shutdownContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+}
+
+// pushHeartbeat pushes the heartbeat of a successful markdown, SARIF or CSV run if heartbeatOnly
+// is set. These modes push no other metrics.
+func pushHeartbeat(ctx context.Context, cfg *config.Config, m *metrics.Metrics, logger *logging.Logger, dryRunMetrics *strings.Builder) {
+	if !cfg.HeartbeatOnly {
+		return
+	}
 	if cfg.ScanHelm {
-		result, err := scanner.ScanHelm(ctx)
-		if err != nil {
-			m.RecordError()
-			hadError = true
-		} else {
-			m.RecordHelmScan(len(result.Outdated), result.Duration)
-
-			// Get namespaces with outdated releases for container deduplication
-			outdatedHelmNamespaces = result.OutdatedNamespaces()
-
-			// Record version info metrics for all outdated releases
-			for _, release := range result.Outdated {
-				m.RecordHelmChartInfo(
-					release.ReleaseName,
-					release.Namespace,
-					release.ChartName,
-					release.Installed.Version,
-					release.Latest.Version,
-					release.Deprecated,
-				)
-			}
+		m.RecordScanSuccess("helm")
+	}
+	if cfg.ScanContainers {
+		m.RecordScanSuccess("container")
+	}
+	pushMetrics(ctx, cfg, m, logger, dryRunMetrics)
+}
+
+// pushMetrics pushes the run's metrics to the Pushgateway, or only the heartbeat with
+// heartbeatOnly. If ctx was canceled by a shutdown signal, whatever was recorded before is still
+// pushed, within metricsFlushTimeout.
+func pushMetrics(ctx context.Context, cfg *config.Config, m *metrics.Metrics, logger *logging.Logger, dryRunMetrics *strings.Builder) {
+	if cfg.PushgatewayURL == "" {
+		return
+	}
+	if ctx.Err() != nil {
+		logger.Info().Msg("Scan interrupted, flushing metrics")
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), metricsFlushTimeout)
+		defer cancel()
+	}
+
+	push := m.Push
+	if cfg.HeartbeatOnly {
+		push = m.PushHeartbeat
+	}
+	if err := push(ctx); err != nil {
+		logger.Error().Err(err).Msg("Failed to push metrics")
+	} else if cfg.DryRun {
+		logger.MetricsDryRun(cfg.PushgatewayURL, dryRunMetrics.String())
+	} else {
+		logger.MetricsPushed(cfg.PushgatewayURL)
+	}
+}
+
+// runScan scans all configured kube contexts, creates issues, sends notifications and
+// pushes metrics. Returns false if any scan failed, and the number of outdated releases and images.
+func runScan(ctx context.Context, cfg *config.Config, scanner *nova.Scanner, m *metrics.Metrics, logger *logging.Logger, dryRunMetrics *strings.Builder) (bool, int) {
+	start := time.Now()
+	ok := true
+
+	ctx, span := tracing.Tracer().Start(ctx, "nova-scanner.Run")
+	defer span.End()
+
+	// A fresh issue manager re-lists open issues, so each run sees issues closed since the last one
+	issueManager := newIssueBackend(ctx, cfg, logger)
+
+	// Collect results for notifications
+	var summary notify.Summary
+
+	// Scan each configured kube context; failed scans are logged by the scanner
+	result, err := scanner.Run(ctx)
+	if err != nil {
+		ok = false
+	}
+
+	// Compare with the previous run's outdated set; onlyNew limits issues to the difference
+	var newOnly *state.Diff
+	var current state.State
+	if cfg.StateFile != "" {
+		var diff state.Diff
+		diff, current = compareState(cfg, result, start, logger)
+		m.RecordOutdatedDiff(len(diff.NewlyOutdated), len(diff.Resolved))
+		recordFirstSeen(result, current, containerKeysPerWorkload(cfg), start, m)
+		if cfg.OnlyNew {
+			newOnly = &diff
+		}
+	}
+
+	var failedKeys []string
+	for _, contextResult := range result.Contexts {
+		failedKeys = append(failedKeys, processContext(ctx, cfg, contextResult, issueManager, m, logger, &summary, newOnly)...)
+	}
+
+	// Save the state only now, so components whose issue failed are retried next run
+	if cfg.StateFile != "" {
+		saveState(cfg, current, ok, failedKeys, logger)
+	}
+
+	if cfg.CloseResolved {
+		closeResolved(ctx, issueManager, result, ok, logger)
+	}
+
+	// Link this run's issues from a daily summary issue
+	if cfg.CreateSummaryIssue {
+		if _, err := issueManager.CreateSummaryIssue(ctx, start); err != nil {
+			logger.Error().Err(err).Msg("Failed to create summary issue")
+		}
+	}
+
+	if remaining, limit, ok := issueManager.RateLimit(); ok {
+		m.RecordRateLimit(remaining, limit)
+	}
+
+	// Send notifications (failures never fail the scan)
+	for _, notifier := range notify.NewNotifiers(cfg, logger) {
+		if err := notifier.Notify(ctx, summary); err != nil {
+			logger.Warn().Err(err).Msg("Failed to send notification")
+		}
+	}
+
+	pushMetrics(ctx, cfg, m, logger, dryRunMetrics)
+
+	logger.ScanSummary(
+		len(summary.HelmReleases),
+		len(summary.Containers),
+		summary.SkippedContainers,
+		len(summary.CreatedIssueURLs),
+		issueManager.SkippedIssues(),
+		time.Since(start),
+	)
+	span.SetAttributes(
+		attribute.Int("nova.releases.outdated", len(summary.HelmReleases)),
+		attribute.Int("nova.containers.outdated", len(summary.Containers)),
+		attribute.Int("nova.issues.created", len(summary.CreatedIssueURLs)),
+		attribute.Bool("nova.success", ok),
+	)
 
-			// Create issues for outdated releases
-			for _, release := range result.Outdated {
-				url, err := issueManager.CreateHelmIssue(ctx, release)
-				if err != nil {
-					logger.Error().Err(err).
-						Str("release", release.ReleaseName).
-						Msg("Failed to create issue")
-				} else if url != "" {
-					m.RecordIssueCreated("helm")
+	return ok, len(summary.HelmReleases) + len(summary.Containers)
+}
+
+// closeResolved closes the issues of components no longer outdated. After a failed scan every
+// component of that scan would look resolved, so nothing is closed.
+func closeResolved(ctx context.Context, issueManager IssueBackend, result *nova.RunResult, scanOK bool, logger *logging.Logger) {
+	if !scanOK {
+		logger.Warn().Msg("Not closing resolved issues: a scan failed")
+		return
+	}
+	if err := issueManager.CloseResolvedIssues(ctx, result); err != nil {
+		logger.Error().Err(err).Msg("Failed to close resolved issues")
+	}
+}
+
+// compareState diffs the outdated components of this run against the state file. A missing or
+// unreadable state file makes every outdated component new. Returns the diff and this run's
+// state, with first-seen times, for saveState.
+func compareState(cfg *config.Config, result *nova.RunResult, now time.Time, logger *logging.Logger) (state.Diff, state.State) {
+	previous, err := state.Load(cfg.StateFile)
+	if err != nil {
+		logger.Warn().Err(err).Str("path", cfg.StateFile).Msg("Ignoring state file, treating all outdated components as new")
+	}
+
+	keys := state.Keys(result, containerKeysPerWorkload(cfg))
+	diff := state.Compare(previous, keys)
+	logger.OutdatedDiff(diff.NewlyOutdated, diff.Resolved)
+	return diff, state.Next(previous, keys, now.UTC())
+}
+
+// saveState saves this run's state for the next run, unless the scan failed or in dry-run mode.
+// The components of failedKeys, whose issue could not be created or updated, are left out, so
+// they are newly outdated again next run and onlyNew retries them.
+func saveState(cfg *config.Config, current state.State, scanOK bool, failedKeys []string, logger *logging.Logger) {
+	switch {
+	case !scanOK:
+		// A failed scan would mark its components as resolved
+		logger.Warn().Str("path", cfg.StateFile).Msg("Scan failed, not updating state file")
+	case cfg.DryRun:
+		logger.Info().Str("path", cfg.StateFile).Msg("Dry-run mode, not updating state file")
+	default:
+		if err := state.Save(cfg.StateFile, current.Without(failedKeys)); err != nil {
+			logger.Error().Err(err).Str("path", cfg.StateFile).Msg("Failed to save state file")
+		}
+	}
+}
+
+// recordFirstSeen sets the first-seen time of the outdated components in result, for their
+// issue bodies, and records how long each has been outdated. An image keyed per workload was
+// first seen with its earliest workload.
+func recordFirstSeen(result *nova.RunResult, current state.State, perWorkload bool, now time.Time, m *metrics.Metrics) {
+	for _, ctx := range result.Contexts {
+		if ctx.Helm != nil {
+			for i := range ctx.Helm.Outdated {
+				release := &ctx.Helm.Outdated[i]
+				release.FirstSeen = current.FirstSeen[state.HelmKey(ctx.Context, *release)]
+				m.RecordOutdatedAge("helm", ctx.Context, release.Namespace, release.ReleaseName, now.Sub(release.FirstSeen))
+			}
+		}
+		if ctx.Containers != nil {
+			for i := range ctx.Containers.Outdated {
+				container := &ctx.Containers.Outdated[i]
+				container.FirstSeen = time.Time{}
+				for _, key := range state.ContainerKeys(ctx.Context, *container, perWorkload) {
+					if seen := current.FirstSeen[key]; container.FirstSeen.IsZero() || seen.Before(container.FirstSeen) {
+						container.FirstSeen = seen
+					}
 				}
+				m.RecordOutdatedAge("container", ctx.Context, "", container.Name, now.Sub(container.FirstSeen))
 			}
 		}
 	}
+}
 
-	// Scan containers
-	if cfg.ScanContainers {
-		// Pass outdated Helm namespaces to skip containers that will be updated with Helm charts
-		result, err := scanner.ScanContainers(ctx, outdatedHelmNamespaces)
-		if err != nil {
-			m.RecordError()
-			hadError = true
-		} else {
-			m.RecordContainerScan(len(result.Outdated), result.Duration)
-
-			// Record version info metrics for all outdated containers
-			for _, container := range result.Outdated {
-				m.RecordContainerInfo(
-					container.Name,
-					container.CurrentTag,
-					container.LatestTag,
-				)
+// processContext records metrics and creates issues for the scan results of one kube context.
+// With newOnly set, issues are only created for newly outdated components. Returns the state
+// keys of the components whose issue failed.
+func processContext(ctx context.Context, cfg *config.Config, result nova.ContextResult, issueManager IssueBackend, m *metrics.Metrics, logger *logging.Logger, summary *notify.Summary, newOnly *state.Diff) []string {
+	kubeContext := result.Context
+
+	// Collect outdated components for namespace-grouped issues
+	var outdatedReleases []nova.ReleaseOutput
+	var outdatedContainers []nova.ContainerOutput
+
+	// Issues are created in parallel once all jobs are known
+	var jobs []issueJob
+
+	// Helm charts
+	if result.HelmErr != nil {
+		m.RecordError(nova.ErrorReason(result.HelmErr))
+	} else if result.Helm != nil {
+		m.RecordHelmScan(kubeContext, len(result.Helm.Outdated), result.Helm.Duration)
+		m.RecordOutdatedHelmByNamespace(kubeContext, result.Helm.OutdatedByNamespace())
+		m.RecordUnknownSeverity(kubeContext, "helm", result.Helm.UnknownSeverity())
+		m.RecordInvalidRecords("helm", result.Helm.InvalidRecords)
+		summary.HelmReleases = append(summary.HelmReleases, result.Helm.Outdated...)
+		outdatedReleases = result.Helm.Outdated
+
+		// Record version info metrics for all outdated releases
+		for _, release := range result.Helm.Outdated {
+			m.RecordHelmChartInfo(
+				kubeContext,
+				release.ReleaseName,
+				release.Namespace,
+				release.ChartName,
+				release.Installed.Version,
+				release.Latest.Version,
+				release.Deprecated,
+				release.HelmVersion,
+			)
+		}
+
+		// Create issues for outdated releases (namespace-grouped issues are created below)
+		if !cfg.IsGroupedByNamespace() {
+			for _, release := range result.Helm.Outdated {
+				key := state.HelmKey(kubeContext, release)
+				if newOnly != nil && !newOnly.IsNew(key) {
+					continue
+				}
+				jobs = append(jobs, issueJob{
+					issueType: "helm",
+					logKey:    "release",
+					logValue:  release.ReleaseName,
+					keys:      []string{key},
+					create: func(ctx context.Context) (string, error) {
+						return issueManager.CreateHelmIssue(ctx, release)
+					},
+				})
 			}
+		}
+	}
 
-			// Create issues for outdated containers
-			for _, container := range result.Outdated {
-				url, err := issueManager.CreateContainerIssue(ctx, container)
-				if err != nil {
-					logger.Error().Err(err).
-						Str("image", container.Name).
-						Msg("Failed to create issue")
-				} else if url != "" {
-					m.RecordIssueCreated("container")
+	// Containers (already deduplicated against outdated Helm releases)
+	if result.ContainersErr != nil {
+		m.RecordError(nova.ErrorReason(result.ContainersErr))
+	} else if result.Containers != nil {
+		m.RecordContainerScan(kubeContext, len(result.Containers.Outdated), result.Containers.Duration)
+		m.RecordOutdatedContainersByNamespace(kubeContext, result.Containers.OutdatedByNamespace())
+		m.RecordUnknownSeverity(kubeContext, "container", result.Containers.UnknownSeverity())
+		m.RecordSkippedContainers(kubeContext, len(result.Containers.Skipped))
+		m.RecordInvalidRecords("container", result.Containers.InvalidRecords)
+		summary.Containers = append(summary.Containers, result.Containers.Outdated...)
+		summary.SkippedContainers += len(result.Containers.Skipped)
+		outdatedContainers = result.Containers.Outdated
+
+		// Record version info metrics for all outdated containers
+		for _, container := range result.Containers.Outdated {
+			m.RecordContainerInfo(
+				kubeContext,
+				container.Name,
+				container.CurrentTag,
+				container.LatestTag,
+			)
+		}
+
+		// Create issues for outdated containers (namespace-grouped issues are created below)
+		if !cfg.IsGroupedByNamespace() {
+			for _, container := range result.Containers.Outdated {
+				for _, issueContainer := range github.SplitContainerIssues(cfg, container) {
+					// Per-workload issues have one key each, so new workloads of an outdated image are new
+					keys := state.ContainerKeys(kubeContext, issueContainer, containerKeysPerWorkload(cfg))
+					if newOnly != nil && !slices.ContainsFunc(keys, newOnly.IsNew) {
+						continue
+					}
+					logValue := issueContainer.Name
+					if containerKeysPerWorkload(cfg) && len(issueContainer.AffectedWorkloads) > 0 {
+						w := issueContainer.AffectedWorkloads[0]
+						logValue += " (" + w.Namespace + "/" + w.Kind + "/" + w.Name + ")"
+					}
+					jobs = append(jobs, issueJob{
+						issueType: "container",
+						logKey:    "image",
+						logValue:  logValue,
+						keys:      keys,
+						create: func(ctx context.Context) (string, error) {
+							return issueManager.CreateContainerIssue(ctx, issueContainer)
+						},
+					})
 				}
 			}
 		}
 	}
 
-	// Push metrics to Pushgateway
-	if cfg.PushgatewayURL != "" {
-		if err := m.Push(); err != nil {
-			logger.Error().Err(err).Msg("Failed to push metrics")
-		} else {
-			logger.MetricsPushed(cfg.PushgatewayURL)
+	// Create one issue per namespace
+	if cfg.IsGroupedByNamespace() {
+		for _, group := range github.GroupByNamespace(outdatedReleases, outdatedContainers) {
+			if newOnly != nil && !hasNewlyOutdated(*newOnly, kubeContext, group) {
+				continue
+			}
+			jobs = append(jobs, issueJob{
+				issueType: "namespace",
+				logKey:    "namespace",
+				logValue:  group.Namespace,
+				keys:      groupKeys(kubeContext, group),
+				create: func(ctx context.Context) (string, error) {
+					return issueManager.CreateNamespaceIssue(ctx, group)
+				},
+			})
 		}
 	}
 
-	logger.Info().Msg("Nova scanner completed")
+	// Record results in job order, so metrics and the notification list don't depend on scheduling
+	var failedKeys []string
+	for i, res := range runIssueJobs(ctx, jobs, cfg.IssueConcurrency) {
+		job := jobs[i]
+		if res.err != nil {
+			logger.Error().Err(res.err).
+				Str(job.logKey, job.logValue).
+				Msg("Failed to create issue")
+			failedKeys = append(failedKeys, job.keys...)
+		} else if res.url != "" {
+			m.RecordIssueCreated(job.issueType)
+			summary.CreatedIssueURLs = append(summary.CreatedIssueURLs, res.url)
+		}
+	}
+	return failedKeys
+}
+
+// containerKeysPerWorkload reports whether the state keys container images per workload,
+// i.e. whether container issues are filed per workload (namespace issues never are).
+func containerKeysPerWorkload(cfg *config.Config) bool {
+	return cfg.IsContainerIssuePerWorkload() && !cfg.IsGroupedByNamespace()
+}
 
-	if hadError {
-		os.Exit(1)
+// groupKeys returns the state keys of the components of a namespace group.
+func groupKeys(kubeContext string, group github.NamespaceGroup) []string {
+	var keys []string
+	for _, release := range group.Releases {
+		keys = append(keys, state.HelmKey(kubeContext, release))
+	}
+	for _, container := range group.Containers {
+		keys = append(keys, state.ContainerKey(kubeContext, container))
 	}
+	return keys
+}
+
+// hasNewlyOutdated reports whether a namespace group contains a newly outdated component.
+func hasNewlyOutdated(diff state.Diff, kubeContext string, group github.NamespaceGroup) bool {
+	return slices.ContainsFunc(groupKeys(kubeContext, group), diff.IsNew)
 }
 
-// runMarkdownMode handles the markdown output mode for local testing.
-func runMarkdownMode(ctx context.Context, cfg *config.Config, scanner *nova.Scanner, logger *logging.Logger) error {
+// issueJob creates the issue for one outdated component or namespace.
+type issueJob struct {
+	issueType string
+	// logKey and logValue identify the component when logging a failure.
+	logKey   string
+	logValue string
+	// keys are the state keys of the components the issue covers.
+	keys   []string
+	create func(ctx context.Context) (string, error)
+}
+
+// issueResult is the outcome of an issueJob: the created issue URL (empty if skipped) or an error.
+type issueResult struct {
+	url string
+	err error
+}
+
+// runIssueJobs runs the jobs with at most concurrency of them in flight and returns
+// their results in job order.
+func runIssueJobs(ctx context.Context, jobs []issueJob, concurrency int) []issueResult {
+	results := make([]issueResult, len(jobs))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			url, err := job.create(ctx)
+			results[i] = issueResult{url: url, err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runMarkdownMode handles the markdown output mode for local testing. Returns the number of
+// outdated components, for failOnOutdated.
+func runMarkdownMode(ctx context.Context, cfg *config.Config, scanner *nova.Scanner, logger *logging.Logger) (int, error) {
 	var output io.Writer = os.Stdout
 	if cfg.MarkdownOutput != "" {
 		f, err := os.Create(cfg.MarkdownOutput)
 		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
+			return 0, fmt.Errorf("failed to create output file: %w", err)
 		}
 		defer f.Close()
 		output = f
@@ -185,68 +890,117 @@ func runMarkdownMode(ctx context.Context, cfg *config.Config, scanner *nova.Scan
 	sb.WriteString("_Preview of issues that would be created_\n\n")
 	sb.WriteString("---\n\n")
 
+	result, err := scanner.Run(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	issueCount := 0
-	var outdatedHelmNamespaces map[string]bool
 
-	// Scan Helm charts
-	if cfg.ScanHelm {
-		result, err := scanner.ScanHelm(ctx)
-		if err != nil {
-			return fmt.Errorf("helm scan failed: %w", err)
+	for _, contextResult := range result.Contexts {
+		if len(cfg.Contexts) > 0 {
+			sb.WriteString(fmt.Sprintf("## Context: %s\n\n", contextResult.Context))
 		}
 
-		// Get namespaces with outdated releases for container deduplication
-		outdatedHelmNamespaces = result.OutdatedNamespaces()
-
-		if len(result.Outdated) > 0 {
-			sb.WriteString(fmt.Sprintf("## Helm Charts (%d outdated)\n\n", len(result.Outdated)))
+		// Helm charts
+		if helm := contextResult.Helm; helm != nil {
+			if len(helm.Outdated) > 0 {
+				sb.WriteString(fmt.Sprintf("## Helm Charts (%d outdated)\n\n", len(helm.Outdated)))
+
+				for _, release := range helm.Outdated {
+					issueCount++
+					title := github.FormatHelmIssueTitle(cfg.TitlePrefix(), release)
+					body, err := github.RenderHelmIssueBody(cfg, release)
+					if err != nil {
+						return 0, err
+					}
+
+					sb.WriteString(fmt.Sprintf("### Issue %d: %s\n\n", issueCount, title))
+					sb.WriteString(body)
+					sb.WriteString("\n\n---\n\n")
+				}
+			} else {
+				sb.WriteString("## Helm Charts\n\n_No outdated Helm charts found._\n\n")
+			}
+		}
 
-			for _, release := range result.Outdated {
-				issueCount++
-				title := github.FormatHelmIssueTitle(release)
-				body := github.FormatHelmIssueBody(release)
+		// Containers
+		if containers := contextResult.Containers; containers != nil {
+			if len(containers.Outdated) > 0 {
+				sb.WriteString(fmt.Sprintf("## Container Images (%d outdated)\n\n", len(containers.Outdated)))
+
+				for _, outdated := range containers.Outdated {
+					for _, container := range github.SplitContainerIssues(cfg, outdated) {
+						issueCount++
+						title := github.ContainerIssueTitle(cfg, container)
+						body, err := github.RenderContainerIssueBody(cfg, container)
+						if err != nil {
+							return 0, err
+						}
+
+						sb.WriteString(fmt.Sprintf("### Issue %d: %s\n\n", issueCount, title))
+						sb.WriteString(body)
+						sb.WriteString("\n\n---\n\n")
+					}
+				}
+			} else {
+				sb.WriteString("## Container Images\n\n_No outdated container images found._\n\n")
+			}
 
-				sb.WriteString(fmt.Sprintf("### Issue %d: %s\n\n", issueCount, title))
-				sb.WriteString(body)
-				sb.WriteString("\n\n---\n\n")
+			// List skipped containers and the outdated Helm namespaces that caused the skip
+			if len(containers.Skipped) > 0 {
+				sb.WriteString(fmt.Sprintf("## Skipped Containers (%d)\n\n", len(containers.Skipped)))
+				sb.WriteString("_These images are in namespaces with outdated Helm releases (updating the chart will update the containers)._\n\n")
+				sb.WriteString(github.FormatSkippedContainerTable(containers.Skipped, contextResult.Helm.OutdatedNamespaces()))
+				sb.WriteString("\n")
 			}
-		} else {
-			sb.WriteString("## Helm Charts\n\n_No outdated Helm charts found._\n\n")
 		}
 	}
 
-	// Scan containers
-	if cfg.ScanContainers {
-		// Pass outdated Helm namespaces to skip containers that will be updated with Helm charts
-		result, err := scanner.ScanContainers(ctx, outdatedHelmNamespaces)
-		if err != nil {
-			return fmt.Errorf("container scan failed: %w", err)
-		}
-
-		if len(result.Outdated) > 0 {
-			sb.WriteString(fmt.Sprintf("## Container Images (%d outdated)\n\n", len(result.Outdated)))
+	sb.WriteString(fmt.Sprintf("**Total issues that would be created: %d**\n", issueCount))
 
-			for _, container := range result.Outdated {
-				issueCount++
-				title := github.FormatContainerIssueTitle(container)
-				body := github.FormatContainerIssueBody(container)
+	_, err = output.Write([]byte(sb.String()))
+	return len(result.Releases()) + len(result.Containers()), err
+}
 
-				sb.WriteString(fmt.Sprintf("### Issue %d: %s\n\n", issueCount, title))
-				sb.WriteString(body)
-				sb.WriteString("\n\n---\n\n")
-			}
-		} else {
-			sb.WriteString("## Container Images\n\n_No outdated container images found._\n\n")
+// runSARIFMode writes all outdated releases and images as a SARIF document for security tooling.
+// Returns their number, for failOnOutdated.
+func runSARIFMode(ctx context.Context, cfg *config.Config, scanner *nova.Scanner, logger *logging.Logger) (int, error) {
+	var output io.Writer = os.Stdout
+	if cfg.SARIFOutput != "" {
+		f, err := os.Create(cfg.SARIFOutput)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create output file: %w", err)
 		}
+		defer f.Close()
+		output = f
+		logger.Info().Str("file", cfg.SARIFOutput).Msg("Writing SARIF output to file")
+	}
 
-		// Note skipped containers
-		if len(result.Skipped) > 0 {
-			sb.WriteString(fmt.Sprintf("\n_Note: %d container images were skipped because they are in namespaces with outdated Helm releases (updating the chart will update the containers)._\n\n", len(result.Skipped)))
-		}
+	result, err := scanner.Run(ctx)
+	if err != nil {
+		return 0, err
 	}
+	return len(result.Releases()) + len(result.Containers()), report.WriteSARIF(output, report.BuildSARIF(version, result.Releases(), result.Containers()))
+}
 
-	sb.WriteString(fmt.Sprintf("**Total issues that would be created: %d**\n", issueCount))
+// runCSVMode writes all outdated releases and images as CSV rows for spreadsheets.
+// Returns their number, for failOnOutdated.
+func runCSVMode(ctx context.Context, cfg *config.Config, scanner *nova.Scanner, logger *logging.Logger) (int, error) {
+	var output io.Writer = os.Stdout
+	if cfg.CSVOutput != "" {
+		f, err := os.Create(cfg.CSVOutput)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		output = f
+		logger.Info().Str("file", cfg.CSVOutput).Msg("Writing CSV output to file")
+	}
 
-	_, err := output.Write([]byte(sb.String()))
-	return err
+	result, err := scanner.Run(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(result.Releases()) + len(result.Containers()), report.WriteCSV(output, result.Releases(), result.Containers())
 }
@@ -0,0 +1,585 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/github"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/gitlab"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/metrics"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/notify"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/state"
+)
+
+func TestRunEvery_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		runEvery(ctx, time.Hour, func(context.Context) {
+			runs++
+			cancel()
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runEvery did not return after the context was canceled")
+	}
+	if runs != 1 {
+		t.Errorf("expected exactly one run, got %d", runs)
+	}
+}
+
+func TestRunEvery_RepeatsOnInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	runEvery(ctx, time.Millisecond, func(context.Context) {
+		runs++
+		if runs == 3 {
+			cancel()
+		}
+	})
+
+	if runs != 3 {
+		t.Errorf("expected 3 runs before cancellation, got %d", runs)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret-token")
+	t.Setenv("GITHUB_OWNER", "owner")
+	t.Setenv("GITHUB_REPO", "repo")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("minSeverity: major\ncontexts: [prod, staging]\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	var out strings.Builder
+	if err := validateConfig([]string{path}, &out); err != nil {
+		t.Fatalf("validateConfig() error = %v", err)
+	}
+	for _, want := range []string{"Config OK", "minSeverity:     major", "contexts:        prod, staging", "githubRepo:      owner/repo", "githubToken:     set"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "secret-token") {
+		t.Errorf("expected the token not to be printed, got:\n%s", out.String())
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret-token")
+	t.Setenv("GITHUB_OWNER", "owner")
+	t.Setenv("GITHUB_REPO", "repo")
+	t.Setenv("PUSHGATEWAY_PASSWORD", "secret-password")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "minSeverity: major\nslackWebhookUrl: https://hooks.slack.com/services/secret-hook\nwebhookHeaders:\n  Authorization: Bearer secret-header\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	var out strings.Builder
+	if err := printConfig([]string{path}, &out); err != nil {
+		t.Fatalf("printConfig() error = %v", err)
+	}
+	for _, want := range []string{"minSeverity: major", "githubOwner: owner", "githubToken: '***'", "pushgatewayPassword: '***'", "slackWebhookUrl: '***'", "Authorization: '***'", "scanTimeout: 5m0s"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Errorf("expected secrets to be redacted, got:\n%s", out.String())
+	}
+}
+
+func TestConfigFiles_Set(t *testing.T) {
+	var files configFiles
+	for _, value := range []string{"base.yaml", "prod.yaml, secrets.yaml", ""} {
+		if err := files.Set(value); err != nil {
+			t.Fatalf("Set(%q) error = %v", value, err)
+		}
+	}
+	if want := []string{"base.yaml", "prod.yaml", "secrets.yaml"}; !slices.Equal(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+	if files.String() != "base.yaml,prod.yaml,secrets.yaml" {
+		t.Errorf("unexpected String() %q", files.String())
+	}
+}
+
+func TestValidateConfig_Invalid(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("GITHUB_OWNER", "owner")
+	t.Setenv("GITHUB_REPO", "repo")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("minSeverty: major\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	var out strings.Builder
+	err := validateConfig([]string{path}, &out)
+	if err == nil || !strings.Contains(err.Error(), "minSeverty") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no summary for an invalid config, got:\n%s", out.String())
+	}
+}
+
+func TestValidateConfig_UnknownTemplateFunction(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("GITHUB_OWNER", "owner")
+	t.Setenv("GITHUB_REPO", "repo")
+	t.Setenv("HELM_ISSUE_TEMPLATE", "{{ upper .ReleaseName }}")
+
+	var out strings.Builder
+	err := validateConfig(nil, &out)
+	if err == nil || !strings.Contains(err.Error(), `function "upper" not defined`) {
+		t.Errorf("expected unknown function error, got %v", err)
+	}
+}
+
+func TestValidateConfig_MissingTokenForMode(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("OUTPUT_MODE", "markdown")
+
+	// Report modes don't need GitHub credentials
+	var out strings.Builder
+	if err := validateConfig(nil, &out); err != nil {
+		t.Fatalf("validateConfig() error = %v", err)
+	}
+	if strings.Contains(out.String(), "githubToken") {
+		t.Errorf("expected no GitHub settings in markdown mode, got:\n%s", out.String())
+	}
+
+	t.Setenv("OUTPUT_MODE", "github")
+	if err := validateConfig(nil, &out); err == nil || !strings.Contains(err.Error(), "github token is required") {
+		t.Errorf("expected missing token error in github mode, got %v", err)
+	}
+}
+
+func TestValidateConfig_GitLabBackend(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("ISSUE_BACKEND", "gitlab")
+	t.Setenv("GITLAB_TOKEN", "secret-token")
+	t.Setenv("GITLAB_PROJECT_ID", "platform/updates")
+
+	var out strings.Builder
+	if err := validateConfig(nil, &out); err != nil {
+		t.Fatalf("validateConfig() error = %v", err)
+	}
+	for _, want := range []string{"issueBackend:    gitlab", "gitlabProject:   https://gitlab.com platform/updates", "gitlabToken:     set"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "githubToken") || strings.Contains(out.String(), "secret-token") {
+		t.Errorf("expected no GitHub settings and no token, got:\n%s", out.String())
+	}
+}
+
+func TestCompareState_FirstSeen(t *testing.T) {
+	cfg := &config.Config{StateFile: filepath.Join(t.TempDir(), "state.json")}
+	logger := logging.NewLogger("error", "json")
+	run := func() *nova.RunResult {
+		return &nova.RunResult{Contexts: []nova.ContextResult{{
+			Context: "prod",
+			Helm: &nova.HelmScanResult{Outdated: []nova.ReleaseOutput{
+				{ReleaseName: "app", Namespace: "default"},
+			}},
+		}}}
+	}
+	day1 := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	day3 := day1.Add(48 * time.Hour)
+
+	_, first := compareState(cfg, run(), day1, logger)
+	saveState(cfg, first, true, nil, logger)
+
+	result := run()
+	m := metrics.NewMetrics("", "test", "")
+	_, current := compareState(cfg, result, day3, logger)
+	recordFirstSeen(result, current, false, day3, m)
+
+	if got := result.Contexts[0].Helm.Outdated[0].FirstSeen; !got.Equal(day1) {
+		t.Errorf("expected the release to be first seen on the first run, got %s", got)
+	}
+	var out strings.Builder
+	if err := m.Render(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := `nova_outdated_age_seconds{context="prod",name="app",namespace="default",type="helm"} 172800`
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("expected %q in metrics, got:\n%s", want, out.String())
+	}
+}
+
+// fakeBackend records issue creation attempts, failing them while fail is set.
+type fakeBackend struct {
+	fail       bool
+	attempts   []string
+	closeCalls int
+}
+
+func (f *fakeBackend) create(name string) (string, error) {
+	f.attempts = append(f.attempts, name)
+	if f.fail {
+		return "", errors.New("502 Bad Gateway")
+	}
+	return "https://github.com/o/r/issues/" + fmt.Sprint(len(f.attempts)), nil
+}
+
+func (f *fakeBackend) CreateHelmIssue(ctx context.Context, release nova.ReleaseOutput) (string, error) {
+	return f.create(release.ReleaseName)
+}
+
+func (f *fakeBackend) CreateContainerIssue(ctx context.Context, container nova.ContainerOutput) (string, error) {
+	return f.create(container.Name)
+}
+
+func (f *fakeBackend) CreateNamespaceIssue(ctx context.Context, group github.NamespaceGroup) (string, error) {
+	return f.create(group.Namespace)
+}
+
+func (f *fakeBackend) CreateSummaryIssue(ctx context.Context, now time.Time) (string, error) {
+	return "", nil
+}
+
+func (f *fakeBackend) CloseResolvedIssues(ctx context.Context, result *nova.RunResult) error {
+	f.closeCalls++
+	return nil
+}
+
+func (f *fakeBackend) SkippedIssues() int { return 0 }
+
+func (f *fakeBackend) RateLimit() (remaining, limit int, ok bool) { return 0, 0, false }
+
+func TestOnlyNew_RetriesFailedIssue(t *testing.T) {
+	cfg := &config.Config{StateFile: filepath.Join(t.TempDir(), "state.json"), OnlyNew: true, IssueConcurrency: 1}
+	logger := logging.NewLogger("error", "json")
+	m := metrics.NewMetrics("", "test", "")
+	result := &nova.RunResult{Contexts: []nova.ContextResult{{
+		Context: "prod",
+		Helm: &nova.HelmScanResult{Outdated: []nova.ReleaseOutput{
+			{ReleaseName: "app", Namespace: "default"},
+		}},
+	}}}
+	backend := &fakeBackend{fail: true}
+
+	// run mirrors runScan: compare with the state, file issues, then save the state
+	run := func(now time.Time) {
+		diff, current := compareState(cfg, result, now, logger)
+		var summary notify.Summary
+		failed := processContext(context.Background(), cfg, result.Contexts[0], backend, m, logger, &summary, &diff)
+		saveState(cfg, current, true, failed, logger)
+	}
+	day1 := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	run(day1)
+	backend.fail = false
+	run(day1.Add(24 * time.Hour))
+	run(day1.Add(48 * time.Hour))
+
+	if want := []string{"app", "app"}; !slices.Equal(backend.attempts, want) {
+		t.Errorf("expected the failed issue to be retried once, got attempts %v", backend.attempts)
+	}
+	saved, err := state.Load(cfg.StateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.FirstSeen["helm/prod/default/app"]; !got.Equal(day1) {
+		t.Errorf("expected the release to keep its first-seen time, got %s", got)
+	}
+}
+
+func TestOnlyNew_NewWorkloadOfOutdatedImage(t *testing.T) {
+	cfg := &config.Config{StateFile: filepath.Join(t.TempDir(), "state.json"), OnlyNew: true, IssueConcurrency: 1,
+		ContainerIssueGranularity: "workload"}
+	logger := logging.NewLogger("error", "json")
+	m := metrics.NewMetrics("", "test", "")
+	backend := &fakeBackend{}
+	cache := nova.WorkloadOutput{Name: "cache", Namespace: "apps", Kind: "StatefulSet", Container: "redis"}
+	queue := nova.WorkloadOutput{Name: "queue", Namespace: "jobs", Kind: "Deployment", Container: "redis"}
+
+	run := func(now time.Time, workloads ...nova.WorkloadOutput) {
+		result := &nova.RunResult{Contexts: []nova.ContextResult{{
+			Context: "prod",
+			Containers: &nova.ContainerScanResult{Outdated: []nova.ContainerOutput{
+				{Name: "redis", CurrentTag: "7.0.0", LatestTag: "7.2.0", AffectedWorkloads: workloads},
+			}},
+		}}}
+		diff, current := compareState(cfg, result, now, logger)
+		var summary notify.Summary
+		failed := processContext(context.Background(), cfg, result.Contexts[0], backend, m, logger, &summary, &diff)
+		saveState(cfg, current, true, failed, logger)
+	}
+	day1 := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	run(day1, cache)
+	run(day1.Add(24*time.Hour), cache, queue)
+
+	if len(backend.attempts) != 2 {
+		t.Errorf("expected an issue for the first workload and one for the new workload, got attempts %v", backend.attempts)
+	}
+	saved, err := state.Load(cfg.StateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"container/prod/redis#apps/StatefulSet/cache", "container/prod/redis#jobs/Deployment/queue"}
+	if !slices.Equal(saved.Outdated, want) {
+		t.Errorf("expected workload state keys %v, got %v", want, saved.Outdated)
+	}
+}
+
+func TestRunScan_FlushesMetricsOnSignal(t *testing.T) {
+	var pushes atomic.Int32
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushes.Add(1)
+	}))
+	defer pushgateway.Close()
+
+	// nova receives SIGTERM mid-scan and is killed with the canceled scan context
+	runner := nova.CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+			t.Fatalf("failed to send SIGTERM: %v", err)
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	cfg := &config.Config{ScanHelm: true, MinSeverity: "minor", PushgatewayURL: pushgateway.URL, JobName: "test"}
+	logger := logging.NewLogger("error", "json")
+	scanner, err := nova.NewScanner(cfg, logger, nova.WithCommandRunner(runner))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, stop := shutdownContext(context.Background())
+	defer stop()
+	var dryRunMetrics strings.Builder
+	ok, _ := runScan(ctx, cfg, scanner, metrics.NewMetrics(cfg.PushgatewayURL, cfg.JobName, ""), logger, &dryRunMetrics)
+	if ok {
+		t.Error("expected the interrupted scan to fail")
+	}
+	if ctx.Err() == nil {
+		t.Error("expected SIGTERM to cancel the scan context")
+	}
+	if pushes.Load() != 1 {
+		t.Errorf("expected a final metrics push, got %d", pushes.Load())
+	}
+}
+
+func TestReportModes_ReturnOutdatedCount(t *testing.T) {
+	output := `{"container_images":[
+		{"name":"redis","current_version":"7.0.0","latest_version":"7.0.1","outdated":true},
+		{"name":"nginx","current_version":"1.27.0","latest_version":"1.27.0","outdated":false}
+	]}`
+	runner := nova.CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte(output), nil
+	})
+	dir := t.TempDir()
+	cfg := &config.Config{ScanContainers: true, MinSeverity: "minor", MarkdownOutput: filepath.Join(dir, "issues.md"),
+		SARIFOutput: filepath.Join(dir, "results.sarif"), CSVOutput: filepath.Join(dir, "outdated.csv")}
+	logger := logging.NewLogger("error", "json")
+	scanner, err := nova.NewScanner(cfg, logger, nova.WithCommandRunner(runner))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modes := map[string]func(context.Context, *config.Config, *nova.Scanner, *logging.Logger) (int, error){
+		"markdown": runMarkdownMode,
+		"sarif":    runSARIFMode,
+		"csv":      runCSVMode,
+	}
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			outdated, err := mode(context.Background(), cfg, scanner, logger)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if outdated != 1 {
+				t.Errorf("expected 1 outdated component, got %d", outdated)
+			}
+			if code := exitCode(true, outdated, true); code != exitOutdated {
+				t.Errorf("expected exit code %d with failOnOutdated, got %d", exitOutdated, code)
+			}
+		})
+	}
+}
+
+func TestPushHeartbeat(t *testing.T) {
+	var pushes atomic.Int32
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushes.Add(1)
+	}))
+	defer pushgateway.Close()
+	logger := logging.NewLogger("error", "json")
+
+	for _, heartbeatOnly := range []bool{false, true} {
+		cfg := &config.Config{ScanHelm: true, PushgatewayURL: pushgateway.URL, JobName: "test", HeartbeatOnly: heartbeatOnly}
+		m := metrics.NewMetrics(cfg.PushgatewayURL, cfg.JobName, "")
+		pushes.Store(0)
+
+		var dryRunMetrics strings.Builder
+		pushHeartbeat(context.Background(), cfg, m, logger, &dryRunMetrics)
+		if want := map[bool]int32{false: 0, true: 1}[heartbeatOnly]; pushes.Load() != want {
+			t.Errorf("heartbeatOnly=%v: expected %d pushes, got %d", heartbeatOnly, want, pushes.Load())
+		}
+		var rendered strings.Builder
+		if err := m.Render(&rendered); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(rendered.String(), `nova_scan_last_success_timestamp{type="helm"}`); got != heartbeatOnly {
+			t.Errorf("heartbeatOnly=%v: expected the helm timestamp recorded = %v, got:\n%s", heartbeatOnly, heartbeatOnly, rendered.String())
+		}
+	}
+}
+
+func TestNewIssueBackend(t *testing.T) {
+	logger := logging.NewLogger("error", "json")
+	if _, ok := newIssueBackend(context.Background(), &config.Config{IssueBackend: "github"}, logger).(*github.IssueManager); !ok {
+		t.Error("expected the GitHub issue manager by default")
+	}
+	if _, ok := newIssueBackend(context.Background(), &config.Config{IssueBackend: "gitlab"}, logger).(*gitlab.IssueManager); !ok {
+		t.Error("expected the GitLab issue manager for issueBackend gitlab")
+	}
+}
+
+func TestRunIssueJobs_BoundsConcurrency(t *testing.T) {
+	const concurrency, n = 3, 20
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	jobs := make([]issueJob, n)
+	for i := range jobs {
+		jobs[i] = issueJob{issueType: "helm", create: func(context.Context) (string, error) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			if i == 4 {
+				return "", errors.New("boom")
+			}
+			return fmt.Sprintf("https://github.com/owner/repo/issues/%d", i), nil
+		}}
+	}
+
+	results := runIssueJobs(context.Background(), jobs, concurrency)
+
+	if len(results) != n {
+		t.Fatalf("expected %d results, got %d", n, len(results))
+	}
+	if maxInFlight > concurrency {
+		t.Errorf("expected at most %d jobs in flight, got %d", concurrency, maxInFlight)
+	}
+	for i, res := range results {
+		if i == 4 {
+			if res.err == nil {
+				t.Errorf("expected error for job 4, got %+v", res)
+			}
+			continue
+		}
+		if want := fmt.Sprintf("https://github.com/owner/repo/issues/%d", i); res.url != want || res.err != nil {
+			t.Errorf("result %d = %+v, want url %q", i, res, want)
+		}
+	}
+}
+
+func TestStartDelay_WithinBounds(t *testing.T) {
+	const maxDelay = 5 * time.Minute
+
+	// The extremes of the random source map to 0 and maxDelay
+	if got := startDelay(maxDelay, func(int64) int64 { return 0 }); got != 0 {
+		t.Errorf("expected no delay for the lowest draw, got %s", got)
+	}
+	if got := startDelay(maxDelay, func(n int64) int64 { return n - 1 }); got != maxDelay {
+		t.Errorf("expected %s for the highest draw, got %s", maxDelay, got)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if got := startDelay(maxDelay, rand.Int63n); got < 0 || got > maxDelay {
+			t.Fatalf("delay %s outside [0, %s]", got, maxDelay)
+		}
+	}
+}
+
+func TestSleepContext_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := sleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected sleep to stop on cancel, took %s", elapsed)
+	}
+
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("expected nil after sleeping, got %v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name           string
+		ok             bool
+		outdated       int
+		failOnOutdated bool
+		want           int
+	}{
+		{"clean", true, 0, true, exitOK},
+		{"outdated without failOnOutdated", true, 3, false, exitOK},
+		{"outdated with failOnOutdated", true, 3, true, exitOutdated},
+		{"scan failed", false, 0, false, exitError},
+		{"scan failed takes precedence", false, 3, true, exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.ok, tt.outdated, tt.failOnOutdated); got != tt.want {
+				t.Errorf("exitCode(%v, %d, %v) = %d, want %d", tt.ok, tt.outdated, tt.failOnOutdated, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloseResolved_SkipsFailedScan(t *testing.T) {
+	logger := logging.NewLogger("error", "json")
+	for _, scanOK := range []bool{true, false} {
+		backend := &fakeBackend{}
+		closeResolved(context.Background(), backend, &nova.RunResult{}, scanOK, logger)
+		if want := map[bool]int{true: 1, false: 0}[scanOK]; backend.closeCalls != want {
+			t.Errorf("scanOK=%v: expected %d close calls, got %d", scanOK, want, backend.closeCalls)
+		}
+	}
+}
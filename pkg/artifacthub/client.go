@@ -0,0 +1,179 @@
+package artifacthub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const defaultBaseURL = "https://artifacthub.io"
+
+// SecurityReportSummary holds ArtifactHub's vulnerability counts for a package version.
+type SecurityReportSummary struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+// Client queries the ArtifactHub API for Helm chart security reports, release dates and versions.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+
+	mu    sync.Mutex
+	repos map[string]string // chart name -> repository name
+}
+
+// NewClient creates a new ArtifactHub client sending its requests through transport.
+func NewClient(transport http.RoundTripper) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		baseURL:    defaultBaseURL,
+		repos:      make(map[string]string),
+	}
+}
+
+type searchResponse struct {
+	Packages []struct {
+		Name       string `json:"name"`
+		Repository struct {
+			Name string `json:"name"`
+		} `json:"repository"`
+	} `json:"packages"`
+}
+
+type packageResponse struct {
+	SecurityReportSummary *SecurityReportSummary `json:"security_report_summary"`
+	TS                    int64                  `json:"ts"` // publish time (unix seconds)
+	AvailableVersions     []struct {
+		Version string `json:"version"`
+	} `json:"available_versions"`
+}
+
+// SecuritySummary returns the security report summary for a chart version.
+// Returns nil without error when ArtifactHub has no data for the chart or version.
+func (c *Client) SecuritySummary(ctx context.Context, chartName, version string) (*SecurityReportSummary, error) {
+	pkg, err := c.packageVersion(ctx, chartName, version)
+	if err != nil || pkg == nil {
+		return nil, err
+	}
+	return pkg.SecurityReportSummary, nil
+}
+
+// ReleaseTime returns when a chart version was published.
+// Returns the zero time without error when ArtifactHub has no data for the chart or version.
+func (c *Client) ReleaseTime(ctx context.Context, chartName, version string) (time.Time, error) {
+	pkg, err := c.packageVersion(ctx, chartName, version)
+	if err != nil || pkg == nil || pkg.TS == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(pkg.TS, 0), nil
+}
+
+// VersionsBehind returns how many published chart versions are newer than installedVersion, up
+// to and including latestVersion. Returns 0 without error when ArtifactHub has no data for the
+// chart or version, or either version is not semver.
+func (c *Client) VersionsBehind(ctx context.Context, chartName, installedVersion, latestVersion string) (int, error) {
+	installed, err := semver.NewVersion(installedVersion)
+	if err != nil {
+		return 0, nil
+	}
+	latest, err := semver.NewVersion(latestVersion)
+	if err != nil {
+		return 0, nil
+	}
+	pkg, err := c.packageVersion(ctx, chartName, latestVersion)
+	if err != nil || pkg == nil {
+		return 0, err
+	}
+
+	behind := 0
+	for _, available := range pkg.AvailableVersions {
+		v, err := semver.NewVersion(available.Version)
+		if err == nil && v.GreaterThan(installed) && !v.GreaterThan(latest) {
+			behind++
+		}
+	}
+	return behind, nil
+}
+
+// packageVersion fetches the ArtifactHub package for a chart version.
+// Returns nil without error when the chart or version is unknown.
+func (c *Client) packageVersion(ctx context.Context, chartName, version string) (*packageResponse, error) {
+	repo, err := c.repository(ctx, chartName)
+	if err != nil || repo == "" {
+		return nil, err
+	}
+
+	var pkg packageResponse
+	path := fmt.Sprintf("/api/v1/packages/helm/%s/%s/%s",
+		url.PathEscape(repo), url.PathEscape(chartName), url.PathEscape(version))
+	found, err := c.get(ctx, path, &pkg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// repository resolves the ArtifactHub repository hosting a chart, caching the result.
+func (c *Client) repository(ctx context.Context, chartName string) (string, error) {
+	c.mu.Lock()
+	repo, ok := c.repos[chartName]
+	c.mu.Unlock()
+	if ok {
+		return repo, nil
+	}
+
+	var result searchResponse
+	path := "/api/v1/packages/search?kind=0&limit=20&ts_query_web=" + url.QueryEscape(chartName)
+	if _, err := c.get(ctx, path, &result); err != nil {
+		return "", err
+	}
+	for _, pkg := range result.Packages {
+		if pkg.Name == chartName {
+			repo = pkg.Repository.Name
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.repos[chartName] = repo
+	c.mu.Unlock()
+	return repo, nil
+}
+
+// get performs a GET request and decodes the JSON response into v.
+// Returns false without error when the resource does not exist.
+func (c *Client) get(ctx context.Context, path string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("artifacthub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("artifacthub returned status %d for %s", resp.StatusCode, path)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse artifacthub response: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,184 @@
+package artifacthub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(nil)
+	client.baseURL = server.URL
+	return client
+}
+
+func TestClient_SecuritySummary(t *testing.T) {
+	searches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/packages/search", func(w http.ResponseWriter, r *http.Request) {
+		searches++
+		if got := r.URL.Query().Get("ts_query_web"); got != "ingress-nginx" {
+			t.Errorf("unexpected search query %q", got)
+		}
+		fmt.Fprint(w, `{"packages":[
+			{"name":"ingress-nginx-fork","repository":{"name":"someone"}},
+			{"name":"ingress-nginx","repository":{"name":"ingress-nginx"}}
+		]}`)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/ingress-nginx/ingress-nginx/4.0.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"security_report_summary":{"critical":1,"high":4,"medium":2,"low":0,"unknown":0}}`)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/ingress-nginx/ingress-nginx/4.1.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"ingress-nginx"}`)
+	})
+	client := newTestClient(t, mux)
+
+	summary, err := client.SecuritySummary(context.Background(), "ingress-nginx", "4.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary == nil || summary.Critical != 1 || summary.High != 4 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	summary, err = client.SecuritySummary(context.Background(), "ingress-nginx", "4.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != nil {
+		t.Errorf("expected nil summary when report is absent, got %+v", summary)
+	}
+
+	if searches != 1 {
+		t.Errorf("expected repository lookup to be cached, got %d searches", searches)
+	}
+}
+
+func TestClient_SecuritySummary_NotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/packages/search", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"packages":[{"name":"my-chart","repository":{"name":"my-repo"}}]}`)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/my-repo/my-chart/9.9.9", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	client := newTestClient(t, mux)
+
+	summary, err := client.SecuritySummary(context.Background(), "my-chart", "9.9.9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != nil {
+		t.Errorf("expected nil summary for unknown version, got %+v", summary)
+	}
+
+	summary, err = client.SecuritySummary(context.Background(), "unknown-chart", "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != nil {
+		t.Errorf("expected nil summary for unknown chart, got %+v", summary)
+	}
+}
+
+func TestClient_SecuritySummary_ServerError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	if _, err := client.SecuritySummary(context.Background(), "my-chart", "1.0.0"); err == nil {
+		t.Fatal("expected error for server failure")
+	}
+}
+
+func TestClient_ReleaseTime(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/packages/search", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"packages":[{"name":"cert-manager","repository":{"name":"cert-manager"}}]}`)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/cert-manager/cert-manager/1.14.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"cert-manager","ts":1706745600}`)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/cert-manager/cert-manager/1.15.0", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	client := newTestClient(t, mux)
+
+	released, err := client.ReleaseTime(context.Background(), "cert-manager", "1.14.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !released.Equal(time.Unix(1706745600, 0)) {
+		t.Errorf("unexpected release time %v", released)
+	}
+
+	released, err = client.ReleaseTime(context.Background(), "cert-manager", "1.15.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !released.IsZero() {
+		t.Errorf("expected zero time for unknown version, got %v", released)
+	}
+}
+
+func TestClient_VersionsBehind(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/packages/search", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"packages":[{"name":"cert-manager","repository":{"name":"cert-manager"}}]}`)
+	})
+	mux.HandleFunc("/api/v1/packages/helm/cert-manager/cert-manager/1.14.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"cert-manager","available_versions":[
+			{"version":"1.15.0"},{"version":"1.14.0"},{"version":"1.13.2"},{"version":"1.13.1"},
+			{"version":"1.13.0"},{"version":"1.12.0"},{"version":"not-semver"}]}`)
+	})
+	client := newTestClient(t, mux)
+
+	behind, err := client.VersionsBehind(context.Background(), "cert-manager", "1.13.0", "1.14.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 1.13.1, 1.13.2 and 1.14.0; newer and older versions don't count
+	if behind != 3 {
+		t.Errorf("expected 3 versions behind, got %d", behind)
+	}
+
+	behind, err = client.VersionsBehind(context.Background(), "cert-manager", "1.13.0", "1.16.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if behind != 0 {
+		t.Errorf("expected 0 for an unknown version, got %d", behind)
+	}
+}
+
+func TestSecuritySeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		current *SecurityReportSummary
+		latest  *SecurityReportSummary
+		want    int
+	}{
+		{"no data", nil, nil, 0},
+		{"no data for current", nil, &SecurityReportSummary{Critical: 1}, 0},
+		{"critical fixed in latest", &SecurityReportSummary{Critical: 2}, &SecurityReportSummary{}, 3},
+		{"high fixed in latest", &SecurityReportSummary{High: 3}, &SecurityReportSummary{High: 1}, 2},
+		{"critical with no data for latest", &SecurityReportSummary{Critical: 1}, nil, 3},
+		{"latest not better", &SecurityReportSummary{High: 2}, &SecurityReportSummary{High: 2}, 0},
+		{"latest worse", &SecurityReportSummary{High: 1}, &SecurityReportSummary{Critical: 1, High: 1}, 0},
+		{"only medium and low", &SecurityReportSummary{Medium: 5, Low: 3}, &SecurityReportSummary{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SecuritySeverity(tt.current, tt.latest); got != tt.want {
+				t.Errorf("SecuritySeverity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
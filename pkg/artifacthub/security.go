@@ -0,0 +1,30 @@
+package artifacthub
+
+// Severity levels match config.SeverityLevel (3 = critical, 2 = major, 1 = minor).
+const (
+	severityNone     = 0
+	severityMajor    = 2
+	severityCritical = 3
+)
+
+// SecuritySeverity maps the security reports of the installed and latest chart versions
+// to a severity level that an upgrade should be escalated to (0 = no escalation).
+// An upgrade is escalated when the installed version has critical or high vulnerabilities
+// that the latest version reduces. Missing data for the installed version never escalates;
+// missing data for the latest version is treated as a fix.
+func SecuritySeverity(current, latest *SecurityReportSummary) int {
+	if current == nil {
+		return severityNone
+	}
+	if latest != nil && latest.Critical+latest.High >= current.Critical+current.High {
+		return severityNone
+	}
+	switch {
+	case current.Critical > 0:
+		return severityCritical
+	case current.High > 0:
+		return severityMajor
+	default:
+		return severityNone
+	}
+}
@@ -1,52 +1,242 @@
 package config
 
 import (
+	"bytes"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/Masterminds/semver/v3"
 	"gopkg.in/yaml.v3"
 )
 
+// RepoRoute is the GitHub repository receiving the issues of a routed namespace.
+type RepoRoute struct {
+	Owner string `yaml:"owner"`
+	Repo  string `yaml:"repo"`
+}
+
+// DefaultIssueTitlePrefix is the issue title prefix used unless issueTitlePrefix is set.
+const DefaultIssueTitlePrefix = "[Nova]"
+
 // Config holds all configuration for the nova-scanner.
 type Config struct {
 	// Kubernetes
 	Kubeconfig string   `yaml:"kubeconfig"`
 	Context    string   `yaml:"context"`
+	Contexts   []string `yaml:"contexts"`   // scan each context in turn; overrides context
 	Namespaces []string `yaml:"namespaces"` // empty = all namespaces
 
 	// Scanning
-	ScanHelm              bool     `yaml:"scanHelm"`
-	ScanContainers        bool     `yaml:"scanContainers"`
-	IgnoreReleases        []string `yaml:"ignoreReleases"`
-	IgnoreCharts          []string `yaml:"ignoreCharts"`
-	IgnoreImages          []string `yaml:"ignoreImages"`
+	ScanHelm                   bool                `yaml:"scanHelm"`
+	ScanContainers             bool                `yaml:"scanContainers"`
+	SkipDigestPinned           bool                `yaml:"skipDigestPinned"` // Don't report images referenced by digest (image@sha256:...)
+	IgnoreReleases             []string            `yaml:"ignoreReleases"`   // Release names, or "namespace/release" to ignore in one namespace only
+	IgnoreCharts               []string            `yaml:"ignoreCharts"`
+	IgnoreImages               []string            `yaml:"ignoreImages"`
+	IgnoreNamespaces           []string            `yaml:"ignoreNamespaces"`           // Glob patterns like ignoreImages, or "re:<regexp>"
+	IgnoreWorkloadKinds        []string            `yaml:"ignoreWorkloadKinds"`        // Workload kinds whose containers are dropped, e.g. "CronJob"
+	OnlyCharts                 []string            `yaml:"onlyCharts"`                 // Allowlist of chart name globs (empty = all charts)
+	OnlyImages                 []string            `yaml:"onlyImages"`                 // Allowlist of image globs (empty = all images)
+	OnlyRegistries             []string            `yaml:"onlyRegistries"`             // Allowlist of image registry host globs (path.Match), e.g. "*.azurecr.io" (empty = all registries)
+	IgnoreRegistries           []string            `yaml:"ignoreRegistries"`           // Image registry globs to ignore, e.g. "docker.io"
 	IgnoreVersionPatterns      []string            `yaml:"ignoreVersionPatterns"`      // Patterns to blacklist in target versions (e.g., "-develop", "-rc", "-alpha")
 	ChartVersionIgnorePatterns map[string][]string `yaml:"chartVersionIgnorePatterns"` // Per-chart version ignore patterns (chart name -> patterns)
+	ChartAliases               map[string]string   `yaml:"chartAliases"`               // Chart renames (old name -> canonical name) used for issue dedup
+
+	// SkipAllHelmManagedContainers drops images whose workloads all belong to a release found by
+	// the Helm scan, whether or not it is outdated; they are updated with the chart. Requires
+	// scanHelm and includeAllReleases
+	SkipAllHelmManagedContainers bool `yaml:"skipAllHelmManagedContainers"`
+
+	// DeprecatedAlwaysReport reports outdated releases of deprecated charts regardless of minSeverity
+	DeprecatedAlwaysReport bool `yaml:"deprecatedAlwaysReport"`
+
+	// IgnoreHelmV2 drops releases nova reports as installed by Helm v2, which GitOps tools can't manage
+	IgnoreHelmV2 bool `yaml:"ignoreHelmV2"`
+
+	// ScanTimeout bounds each nova invocation (0 = no timeout)
+	ScanTimeout time.Duration `yaml:"scanTimeout"`
+
+	// ScanInterval runs the scanner as a daemon, scanning on this interval (0 = run once and exit)
+	ScanInterval time.Duration `yaml:"scanInterval"`
+
+	// HealthAddr serves /healthz and /readyz probes in daemon mode, e.g. ":8080" (empty = disabled)
+	HealthAddr string `yaml:"healthAddr"`
+	// HealthFailureThreshold is the number of consecutive failed scans after which /readyz fails
+	HealthFailureThreshold int `yaml:"healthFailureThreshold"`
+
+	// StartJitter delays the first scan by a random duration up to this long, so scanners
+	// sharing a schedule don't hit GitHub and the Pushgateway at once (0 = start immediately)
+	StartJitter time.Duration `yaml:"startJitter"`
+
+	// MinReleaseAge skips Helm releases whose latest chart version was published more recently
+	// (0 = report immediately). Publish times come from ArtifactHub and require pollArtifactHub.
+	MinReleaseAge time.Duration `yaml:"minReleaseAge"`
+
+	// MinVersionsBehind skips Helm releases fewer than this many published chart versions behind
+	// latest (0 = report any). Versions come from ArtifactHub and require pollArtifactHub.
+	MinVersionsBehind int `yaml:"minVersionsBehind"`
 
 	// Severity filtering: minor, major, critical
 	MinSeverity string `yaml:"minSeverity"`
+	// SkipUnknownSeverity drops Helm releases and images whose versions aren't semver, so their upgrade
+	// can't be classified; by default they are reported whatever minSeverity is
+	SkipUnknownSeverity bool `yaml:"skipUnknownSeverity"`
+
+	// CalverCharts lists chart name globs versioned by date (e.g. 2024.06.18). Their upgrades are
+	// classified by the changed date component, mapped by calverSeverity (year/month/day ->
+	// patch/minor/major) instead of as semver bumps.
+	CalverCharts   []string          `yaml:"calverCharts"`
+	CalverSeverity map[string]string `yaml:"calverSeverity"`
 
 	// GitHub
 	GitHubToken string `yaml:"githubToken"`
-	GitHubOwner string `yaml:"githubOwner"`
-	GitHubRepo  string `yaml:"githubRepo"`
-	DryRun      bool   `yaml:"dryRun"`
+	// GitHubTokenFile is read for the token at load time (e.g. a mounted Secret), taking precedence
+	// over githubToken and GITHUB_TOKEN; surrounding whitespace is trimmed
+	GitHubTokenFile string   `yaml:"githubTokenFile"`
+	GitHubOwner     string   `yaml:"githubOwner"`
+	GitHubRepo      string   `yaml:"githubRepo"`
+	DryRun          bool     `yaml:"dryRun"`
+	IssueLabels     []string `yaml:"issueLabels"` // "nova-scan" is always added (dedup relies on it)
+	// GitHubBaseURL points the client at a GitHub Enterprise Server, e.g. https://github.example.com
+	// (empty = github.com); the API path "/api/v3/" is appended unless the URL already ends with it
+	GitHubBaseURL string `yaml:"githubBaseUrl"`
+
+	// FailOnOutdated exits a one-shot run with code 2 when outdated components were found
+	FailOnOutdated bool `yaml:"failOnOutdated"`
+
+	// IssueTitlePrefix starts every issue title, e.g. "[Nova prod]" to tell apart scanners
+	// sharing a repository; a custom prefix also scopes the dedup fingerprints
+	IssueTitlePrefix string `yaml:"issueTitlePrefix"`
+
+	// GitHubMaxAttempts bounds the attempts of a GitHub or GitLab request failing with a 5xx or
+	// network error; creates aren't retried, as they may have succeeded
+	GitHubMaxAttempts int `yaml:"githubMaxAttempts"`
+
+	// IssueBackend is the issue tracker used in github output mode: "github" or "gitlab"
+	IssueBackend string `yaml:"issueBackend"`
+
+	// GitLab (issueBackend: gitlab)
+	GitLabURL       string `yaml:"gitlabUrl"`       // Instance URL, e.g. https://gitlab.example.com
+	GitLabToken     string `yaml:"gitlabToken"`     // Access token with the api scope
+	GitLabProjectID string `yaml:"gitlabProjectId"` // Numeric project ID or full path (group/project)
 
-	// Output mode: "github" or "markdown"
+	// IssueConcurrency bounds how many issues are created in parallel
+	IssueConcurrency int `yaml:"issueConcurrency"`
+
+	// RepoRouting files issues for a namespace into that team's repository (namespace -> owner/repo);
+	// other namespaces use githubOwner/githubRepo. An empty owner defaults to githubOwner.
+	RepoRouting map[string]RepoRoute `yaml:"repoRouting"`
+
+	// Per-issue labels: the severity prefix gets "major", "minor" or "patch" appended (empty = disabled)
+	SeverityLabelPrefix string `yaml:"severityLabelPrefix"`
+	DeprecatedLabel     string `yaml:"deprecatedLabel"` // added to issues for deprecated charts (empty = disabled)
+	GroupBy             string `yaml:"groupBy"`         // "component" (one issue per release/image) or "namespace"
+	GitOpsTool          string `yaml:"gitOpsTool"`      // Update instructions in Helm issues: "flux", "argocd" or "none"
+	// ContainerIssueGranularity files one issue per outdated image ("image") or one per affected
+	// workload ("workload"), so each team owns its workload's bump; only applies with groupBy component
+	ContainerIssueGranularity string `yaml:"containerIssueGranularity"`
+
+	// ReopenClosed reopens recently closed issues for components that are still outdated
+	ReopenClosed bool `yaml:"reopenClosed"`
+
+	// CloseResolved closes the open issues of components no longer reported as outdated
+	CloseResolved bool `yaml:"closeResolved"`
+
+	// CreateSummaryIssue opens a daily summary issue linking the issues created by each run
+	CreateSummaryIssue bool `yaml:"createSummaryIssue"`
+
+	// StateFile persists each run's outdated components, so the next run reports what became
+	// outdated or was resolved since (empty = disabled). OnlyNew limits issues to newly outdated components.
+	StateFile string `yaml:"stateFile"`
+	OnlyNew   bool   `yaml:"onlyNew"`
+
+	// SuppressLabel marks issues (open or closed) whose component should never be reported again (empty = disabled)
+	SuppressLabel string `yaml:"suppressLabel"`
+
+	// Issue body templates (Go text/template): an inline template or a file path (empty = built-in body).
+	// Values without template actions ("{{") are read as file paths.
+	HelmIssueTemplate      string `yaml:"helmIssueTemplate"`
+	ContainerIssueTemplate string `yaml:"containerIssueTemplate"`
+
+	// MaxWorkloadRows caps the affected workloads listed in a container issue (0 = unlimited)
+	MaxWorkloadRows int `yaml:"maxWorkloadRows"`
+
+	// Issue assignees: namespaceAssignees (namespace -> users) takes precedence over issueAssignees
+	IssueAssignees     []string            `yaml:"issueAssignees"`
+	NamespaceAssignees map[string][]string `yaml:"namespaceAssignees"`
+
+	// Output mode: "github", "markdown", "sarif" or "csv"
 	OutputMode     string `yaml:"outputMode"`
 	MarkdownOutput string `yaml:"markdownOutput"` // file path, empty = stdout
+	SARIFOutput    string `yaml:"sarifOutput"`    // file path, empty = stdout
+	CSVOutput      string `yaml:"csvOutput"`      // file path, empty = stdout
+
+	// Notifications
+	SlackWebhookURL string            `yaml:"slackWebhookUrl"`
+	WebhookURL      string            `yaml:"webhookUrl"`     // generic JSON webhook receiving the full scan summary
+	WebhookHeaders  map[string]string `yaml:"webhookHeaders"` // extra headers, e.g. Authorization
 
 	// Metrics
 	PushgatewayURL string `yaml:"pushgatewayUrl"`
 	JobName        string `yaml:"jobName"`
+	MetricPrefix   string `yaml:"metricPrefix"`  // starts every metric name, e.g. "nova" for nova_scan_info
+	HeartbeatOnly  bool   `yaml:"heartbeatOnly"` // push only the last success timestamps, in every output mode
+
+	// Pushgateway credentials: basic auth or a bearer token (never logged)
+	PushgatewayUsername    string `yaml:"pushgatewayUsername"`
+	PushgatewayPassword    string `yaml:"pushgatewayPassword"`
+	PushgatewayBearerToken string `yaml:"pushgatewayBearerToken"`
+
+	// Outbound proxy for GitHub, GitLab, ArtifactHub, Pushgateway and notification requests,
+	// e.g. "http://proxy:3128" (empty = HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment)
+	HTTPProxy string `yaml:"httpProxy"`
+
+	// UserAgent is sent with every outbound HTTP request, so API traffic can be attributed to
+	// the scanner (empty = "nova-scanner/<version>" when run as the nova-scanner command)
+	UserAgent string `yaml:"userAgent"`
+
+	// TLS for outbound requests: a PEM bundle of CAs trusted in addition to the system roots, e.g.
+	// an internal CA of GitHub Enterprise, and skipping certificate verification (dev only)
+	CACertFile         string `yaml:"caCertFile"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+
+	// Tracing: OTLP/HTTP endpoint receiving spans, e.g. "http://otel-collector:4318" (empty = disabled)
+	OTLPEndpoint string `yaml:"otlpEndpoint"`
 
 	// Logging
-	LogLevel string `yaml:"logLevel"`
+	LogLevel   string `yaml:"logLevel"`
+	LogFormat  string `yaml:"logFormat"`  // "json" or "console"
+	HumanLogTo string `yaml:"humanLogTo"` // "stdout" or "stderr"; stderr keeps stdout free for reports
+	LogFile    string `yaml:"logFile"`    // Optional file that also receives log lines (appended)
+	// Per-component log levels overriding logLevel, e.g. {"github": "debug", "nova": "warn"}
+	ComponentLogLevels map[string]string `yaml:"componentLogLevels"`
+	// Optional file receiving every scan event (scan_start, outdated_found, issue_created, ...)
+	// as JSON lines, appended, whatever logLevel is
+	EventLogFile string `yaml:"eventLogFile"`
 
 	// Nova options
-	DesiredVersions map[string]string `yaml:"desiredVersions"`
-	PollArtifactHub bool              `yaml:"pollArtifactHub"`
+	NovaBinary           string            `yaml:"novaBinary"`     // name or path of the nova executable
+	NovaExtraArgs        []string          `yaml:"novaExtraArgs"`  // appended to every nova find command, e.g. ["--wide"]
+	DumpNovaOutput       string            `yaml:"dumpNovaOutput"` // directory receiving each nova invocation's raw stdout and argv, unredacted
+	DesiredVersions      map[string]string `yaml:"desiredVersions"`
+	DesiredImageVersions map[string]string `yaml:"desiredImageVersions"` // Pin image upgrade targets (image name without tag -> tag)
+	PinMajor             []string          `yaml:"pinMajor"`             // Chart and image name globs reported only for upgrades within their current major version
+	SkipOverridden       bool              `yaml:"skipOverridden"`       // Drop outdated Helm releases whose version nova reports as overridden
+	PollArtifactHub      bool              `yaml:"pollArtifactHub"`
+	IncludeAllReleases   bool              `yaml:"includeAllReleases"`  // Pass --include-all so nova reports up-to-date releases too
+	ArtifactHubSecurity  bool              `yaml:"artifactHubSecurity"` // Escalate severity using ArtifactHub security reports (requires pollArtifactHub)
 }
 
 // IsMarkdownMode returns true if output mode is markdown.
@@ -54,113 +244,717 @@ func (c *Config) IsMarkdownMode() bool {
 	return c.OutputMode == "markdown"
 }
 
-// Load reads configuration from a YAML file and applies environment variable overrides.
-func Load(path string) (*Config, error) {
+// TitlePrefix returns the issue title prefix, DefaultIssueTitlePrefix if unset.
+func (c *Config) TitlePrefix() string {
+	if c.IssueTitlePrefix == "" {
+		return DefaultIssueTitlePrefix
+	}
+	return c.IssueTitlePrefix
+}
+
+// IsGroupedByNamespace returns true if issues aggregate all outdated components of a namespace.
+func (c *Config) IsGroupedByNamespace() bool {
+	return c.GroupBy == "namespace"
+}
+
+// IsContainerIssuePerWorkload returns true if container issues are filed per affected workload.
+func (c *Config) IsContainerIssuePerWorkload() bool {
+	return c.ContainerIssueGranularity == "workload"
+}
+
+// IsGitLabBackend returns true if issues are filed into GitLab instead of GitHub.
+func (c *Config) IsGitLabBackend() bool {
+	return c.IssueBackend == "gitlab"
+}
+
+// IsSARIFMode returns true if output mode is sarif.
+func (c *Config) IsSARIFMode() bool {
+	return c.OutputMode == "sarif"
+}
+
+// IsCSVMode returns true if output mode is csv.
+func (c *Config) IsCSVMode() bool {
+	return c.OutputMode == "csv"
+}
+
+// Load reads configuration from YAML files and applies environment variable overrides.
+// Files are applied in order, each overlaying the ones before: scalars and lists it sets replace
+// earlier values, while maps are merged key by key. Empty paths are skipped.
+func Load(paths ...string) (*Config, error) {
+	return load(true, paths)
+}
+
+// LoadForCheck is Load for --check, which only runs nova: the issue tracker credentials
+// are not required.
+func LoadForCheck(paths ...string) (*Config, error) {
+	return load(false, paths)
+}
+
+// load implements Load, requiring the issue tracker credentials if requireIssueTracker is set.
+func load(requireIssueTracker bool, paths []string) (*Config, error) {
 	cfg := &Config{
 		// Defaults
-		ScanHelm:        true,
-		ScanContainers:  false,
-		MinSeverity:     "minor",
-		PollArtifactHub: true,
-		LogLevel:        "info",
-		JobName:         "nova-scanner",
-		OutputMode:      "github",
+		ScanHelm:                  true,
+		ScanContainers:            false,
+		SkipDigestPinned:          true,
+		DeprecatedAlwaysReport:    true,
+		MinSeverity:               "minor",
+		PollArtifactHub:           true,
+		IncludeAllReleases:        true,
+		LogLevel:                  "info",
+		LogFormat:                 "json",
+		HumanLogTo:                "stdout",
+		JobName:                   "nova-scanner",
+		MetricPrefix:              "nova",
+		OutputMode:                "github",
+		IssueLabels:               []string{"nova-scan"},
+		ScanTimeout:               5 * time.Minute,
+		NovaBinary:                "nova",
+		GroupBy:                   "component",
+		ContainerIssueGranularity: "image",
+		GitOpsTool:                "flux",
+		SuppressLabel:             "nova-ignore",
+		SeverityLabelPrefix:       "nova-severity:",
+		DeprecatedLabel:           "nova-deprecated",
+		GitHubMaxAttempts:         3,
+		IssueTitlePrefix:          DefaultIssueTitlePrefix,
+		IssueBackend:              "github",
+		GitLabURL:                 "https://gitlab.com",
+		IssueConcurrency:          3,
+		HealthFailureThreshold:    3,
+		MaxWorkloadRows:           50,
+		CalverSeverity:            map[string]string{"year": "minor", "month": "patch", "day": "patch"},
 	}
 
-	if path != "" {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+	for _, path := range paths {
+		if path == "" {
+			continue
 		}
-
-		if err := yaml.Unmarshal(data, cfg); err != nil {
-			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
 		}
 	}
 
 	// Apply environment variable overrides
-	cfg.applyEnvOverrides()
+	if err := cfg.applyEnvOverrides(); err != nil {
+		return nil, err
+	}
+
+	// Read the GitHub token from its file, so it needn't be in the environment
+	if err := cfg.loadGitHubToken(); err != nil {
+		return nil, err
+	}
+
+	// Check the CA bundle now rather than failing every request after a full scan
+	if err := cfg.checkCACertFile(); err != nil {
+		return nil, err
+	}
+
+	// Read issue templates given as file paths
+	if err := cfg.loadIssueTemplates(); err != nil {
+		return nil, err
+	}
 
 	// Validate required fields
-	if err := cfg.validate(); err != nil {
+	if err := cfg.validate(requireIssueTracker); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
 
-func (c *Config) applyEnvOverrides() {
+// loadFile decodes a YAML config file over c. Keys absent from the file keep their value.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Unknown keys are errors, so a typo doesn't silently fall back to a default
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnvOverrides applies the settings given as environment variables, returning the
+// errors of those that can't be parsed.
+func (c *Config) applyEnvOverrides() error {
+	var errs []error
 	if v := os.Getenv("KUBECONFIG"); v != "" {
 		c.Kubeconfig = v
 	}
 	if v := os.Getenv("KUBE_CONTEXT"); v != "" {
 		c.Context = v
 	}
+	if v := os.Getenv("KUBE_CONTEXTS"); v != "" {
+		c.Contexts = splitList(v)
+	}
 	if v := os.Getenv("GITHUB_TOKEN"); v != "" {
 		c.GitHubToken = v
 	}
+	if v := os.Getenv("GITHUB_TOKEN_FILE"); v != "" {
+		c.GitHubTokenFile = v
+	}
 	if v := os.Getenv("GITHUB_OWNER"); v != "" {
 		c.GitHubOwner = v
 	}
 	if v := os.Getenv("GITHUB_REPO"); v != "" {
 		c.GitHubRepo = v
 	}
+	if v := os.Getenv("GITHUB_BASE_URL"); v != "" {
+		c.GitHubBaseURL = v
+	}
+	if v := os.Getenv("ISSUE_BACKEND"); v != "" {
+		c.IssueBackend = v
+	}
+	if v := os.Getenv("GITLAB_URL"); v != "" {
+		c.GitLabURL = v
+	}
+	if v := os.Getenv("GITLAB_TOKEN"); v != "" {
+		c.GitLabToken = v
+	}
+	if v := os.Getenv("GITLAB_PROJECT_ID"); v != "" {
+		c.GitLabProjectID = v
+	}
+	if v := os.Getenv("ISSUE_TITLE_PREFIX"); v != "" {
+		c.IssueTitlePrefix = v
+	}
+	envInt(&errs, "GITHUB_MAX_ATTEMPTS", &c.GitHubMaxAttempts)
+	if v := os.Getenv("STATE_FILE"); v != "" {
+		c.StateFile = v
+	}
+	if v := os.Getenv("ONLY_NEW"); v != "" {
+		c.OnlyNew = strings.ToLower(v) == "true" || v == "1"
+	}
+	envInt(&errs, "ISSUE_CONCURRENCY", &c.IssueConcurrency)
+	envInt(&errs, "MAX_WORKLOAD_ROWS", &c.MaxWorkloadRows)
+	if v := os.Getenv("REOPEN_CLOSED"); v != "" {
+		c.ReopenClosed = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("CLOSE_RESOLVED"); v != "" {
+		c.CloseResolved = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("CREATE_SUMMARY_ISSUE"); v != "" {
+		c.CreateSummaryIssue = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("SEVERITY_LABEL_PREFIX"); v != "" {
+		c.SeverityLabelPrefix = v
+	}
+	if v := os.Getenv("DEPRECATED_LABEL"); v != "" {
+		c.DeprecatedLabel = v
+	}
+	if v := os.Getenv("SUPPRESS_LABEL"); v != "" {
+		c.SuppressLabel = v
+	}
+	if v := os.Getenv("GITOPS_TOOL"); v != "" {
+		c.GitOpsTool = v
+	}
+	if v := os.Getenv("HELM_ISSUE_TEMPLATE"); v != "" {
+		c.HelmIssueTemplate = v
+	}
+	if v := os.Getenv("CONTAINER_ISSUE_TEMPLATE"); v != "" {
+		c.ContainerIssueTemplate = v
+	}
+	if v := os.Getenv("ISSUE_LABELS"); v != "" {
+		c.IssueLabels = splitList(v)
+	}
+	if v := os.Getenv("IGNORE_RELEASES"); v != "" {
+		c.IgnoreReleases = splitList(v)
+	}
+	if v := os.Getenv("IGNORE_CHARTS"); v != "" {
+		c.IgnoreCharts = splitList(v)
+	}
+	if v := os.Getenv("IGNORE_IMAGES"); v != "" {
+		c.IgnoreImages = splitList(v)
+	}
+	if v := os.Getenv("IGNORE_VERSION_PATTERNS"); v != "" {
+		c.IgnoreVersionPatterns = splitList(v)
+	}
+	if v := os.Getenv("IGNORE_NAMESPACES"); v != "" {
+		c.IgnoreNamespaces = splitList(v)
+	}
+	if v := os.Getenv("IGNORE_WORKLOAD_KINDS"); v != "" {
+		c.IgnoreWorkloadKinds = splitList(v)
+	}
+	if v := os.Getenv("ONLY_CHARTS"); v != "" {
+		c.OnlyCharts = splitList(v)
+	}
+	if v := os.Getenv("ONLY_IMAGES"); v != "" {
+		c.OnlyImages = splitList(v)
+	}
+	if v := os.Getenv("PIN_MAJOR"); v != "" {
+		c.PinMajor = splitList(v)
+	}
+	if v := os.Getenv("ONLY_REGISTRIES"); v != "" {
+		c.OnlyRegistries = splitList(v)
+	}
+	if v := os.Getenv("IGNORE_REGISTRIES"); v != "" {
+		c.IgnoreRegistries = splitList(v)
+	}
+	if v := os.Getenv("GROUP_BY"); v != "" {
+		c.GroupBy = v
+	}
+	if v := os.Getenv("CONTAINER_ISSUE_GRANULARITY"); v != "" {
+		c.ContainerIssueGranularity = v
+	}
+	if v := os.Getenv("ISSUE_ASSIGNEES"); v != "" {
+		c.IssueAssignees = splitList(v)
+	}
+	if v := os.Getenv("SLACK_WEBHOOK_URL"); v != "" {
+		c.SlackWebhookURL = v
+	}
+	if v := os.Getenv("WEBHOOK_URL"); v != "" {
+		c.WebhookURL = v
+	}
+	if v := os.Getenv("USER_AGENT"); v != "" {
+		c.UserAgent = v
+	}
+	if v := os.Getenv("CA_CERT_FILE"); v != "" {
+		c.CACertFile = v
+	}
+	if v := os.Getenv("INSECURE_SKIP_VERIFY"); v != "" {
+		c.InsecureSkipVerify = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("OTLP_ENDPOINT"); v != "" {
+		c.OTLPEndpoint = v
+	}
 	if v := os.Getenv("PUSHGATEWAY_URL"); v != "" {
 		c.PushgatewayURL = v
 	}
+	if v := os.Getenv("PUSHGATEWAY_USERNAME"); v != "" {
+		c.PushgatewayUsername = v
+	}
+	if v := os.Getenv("PUSHGATEWAY_PASSWORD"); v != "" {
+		c.PushgatewayPassword = v
+	}
+	if v := os.Getenv("PUSHGATEWAY_BEARER_TOKEN"); v != "" {
+		c.PushgatewayBearerToken = v
+	}
+	if v := os.Getenv("HEARTBEAT_ONLY"); v != "" {
+		c.HeartbeatOnly = strings.ToLower(v) == "true" || v == "1"
+	}
 	if v := os.Getenv("JOB_NAME"); v != "" {
 		c.JobName = v
 	}
+	if v := os.Getenv("METRIC_PREFIX"); v != "" {
+		c.MetricPrefix = v
+	}
 	if v := os.Getenv("LOG_LEVEL"); v != "" {
 		c.LogLevel = v
 	}
+	if v := os.Getenv("COMPONENT_LOG_LEVELS"); v != "" {
+		c.ComponentLogLevels = make(map[string]string)
+		for _, pair := range splitList(v) {
+			component, level, _ := strings.Cut(pair, "=")
+			c.ComponentLogLevels[strings.TrimSpace(component)] = strings.TrimSpace(level)
+		}
+	}
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		c.LogFormat = v
+	}
+	if v := os.Getenv("LOG_FILE"); v != "" {
+		c.LogFile = v
+	}
+	if v := os.Getenv("EVENT_LOG_FILE"); v != "" {
+		c.EventLogFile = v
+	}
+	if v := os.Getenv("HUMAN_LOG_TO"); v != "" {
+		c.HumanLogTo = v
+	}
 	if v := os.Getenv("DRY_RUN"); v != "" {
 		c.DryRun = strings.ToLower(v) == "true" || v == "1"
 	}
+	if v := os.Getenv("FAIL_ON_OUTDATED"); v != "" {
+		c.FailOnOutdated = strings.ToLower(v) == "true" || v == "1"
+	}
 	if v := os.Getenv("SCAN_HELM"); v != "" {
 		c.ScanHelm = strings.ToLower(v) == "true" || v == "1"
 	}
 	if v := os.Getenv("SCAN_CONTAINERS"); v != "" {
 		c.ScanContainers = strings.ToLower(v) == "true" || v == "1"
 	}
+	if v := os.Getenv("SKIP_DIGEST_PINNED"); v != "" {
+		c.SkipDigestPinned = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("SKIP_ALL_HELM_MANAGED_CONTAINERS"); v != "" {
+		c.SkipAllHelmManagedContainers = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("DEPRECATED_ALWAYS_REPORT"); v != "" {
+		c.DeprecatedAlwaysReport = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("IGNORE_HELM_V2"); v != "" {
+		c.IgnoreHelmV2 = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("POLL_ARTIFACTHUB"); v != "" {
+		c.PollArtifactHub = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("INCLUDE_ALL_RELEASES"); v != "" {
+		c.IncludeAllReleases = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("SKIP_OVERRIDDEN"); v != "" {
+		c.SkipOverridden = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("ARTIFACTHUB_SECURITY"); v != "" {
+		c.ArtifactHubSecurity = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("NOVA_BINARY"); v != "" {
+		c.NovaBinary = v
+	}
+	if v := os.Getenv("NOVA_EXTRA_ARGS"); v != "" {
+		c.NovaExtraArgs = splitList(v)
+	}
+	if v := os.Getenv("DUMP_NOVA_OUTPUT"); v != "" {
+		c.DumpNovaOutput = v
+	}
+	envDuration(&errs, "SCAN_TIMEOUT", &c.ScanTimeout)
+	envDuration(&errs, "SCAN_INTERVAL", &c.ScanInterval)
+	if v := os.Getenv("HEALTH_ADDR"); v != "" {
+		c.HealthAddr = v
+	}
+	envInt(&errs, "HEALTH_FAILURE_THRESHOLD", &c.HealthFailureThreshold)
+	envDuration(&errs, "START_JITTER", &c.StartJitter)
+	envDuration(&errs, "MIN_RELEASE_AGE", &c.MinReleaseAge)
+	envInt(&errs, "MIN_VERSIONS_BEHIND", &c.MinVersionsBehind)
 	if v := os.Getenv("MIN_SEVERITY"); v != "" {
 		c.MinSeverity = v
 	}
+	if v := os.Getenv("SKIP_UNKNOWN_SEVERITY"); v != "" {
+		c.SkipUnknownSeverity = strings.ToLower(v) == "true" || v == "1"
+	}
+	if v := os.Getenv("CALVER_CHARTS"); v != "" {
+		c.CalverCharts = splitList(v)
+	}
 	if v := os.Getenv("OUTPUT_MODE"); v != "" {
 		c.OutputMode = v
 	}
 	if v := os.Getenv("MARKDOWN_OUTPUT"); v != "" {
 		c.MarkdownOutput = v
 	}
+	if v := os.Getenv("SARIF_OUTPUT"); v != "" {
+		c.SARIFOutput = v
+	}
+	if v := os.Getenv("CSV_OUTPUT"); v != "" {
+		c.CSVOutput = v
+	}
+	return errors.Join(errs...)
+}
+
+// envInt sets dst to the integer in the environment variable name, if set, recording an
+// error in errs if it isn't one.
+func envInt(errs *[]error, name string, dst *int) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("invalid %s: %s (must be an integer)", name, v))
+		return
+	}
+	*dst = n
+}
+
+// envDuration sets dst to the duration in the environment variable name, if set, recording
+// an error in errs if it isn't one.
+func envDuration(errs *[]error, name string, dst *time.Duration) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("invalid %s: %s (must be a duration, e.g. 5m)", name, v))
+		return
+	}
+	*dst = d
 }
 
-func (c *Config) validate() error {
-	// GitHub credentials only required in github output mode
-	if !c.IsMarkdownMode() {
-		if c.GitHubToken == "" {
-			return fmt.Errorf("github token is required (set GITHUB_TOKEN or githubToken in config)")
+// loadIssueTemplates replaces issue template file paths with the file contents.
+func (c *Config) loadIssueTemplates() error {
+	for _, tmpl := range c.issueTemplates() {
+		if *tmpl.text == "" || strings.Contains(*tmpl.text, "{{") {
+			continue
 		}
-		if c.GitHubOwner == "" {
-			return fmt.Errorf("github owner is required (set GITHUB_OWNER or githubOwner in config)")
+		data, err := os.ReadFile(*tmpl.text)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", tmpl.name, err)
 		}
-		if c.GitHubRepo == "" {
-			return fmt.Errorf("github repo is required (set GITHUB_REPO or githubRepo in config)")
+		*tmpl.text = string(data)
+	}
+	return nil
+}
+
+// loadGitHubToken replaces the GitHub token with the trimmed contents of githubTokenFile, if set.
+func (c *Config) loadGitHubToken() error {
+	if c.GitHubTokenFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.GitHubTokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read githubTokenFile: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return fmt.Errorf("githubTokenFile %s is empty", c.GitHubTokenFile)
+	}
+	c.GitHubToken = token
+	return nil
+}
+
+// checkCACertFile verifies that caCertFile, if set, holds at least one PEM certificate.
+func (c *Config) checkCACertFile() error {
+	if c.CACertFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.CACertFile)
+	if err != nil {
+		return fmt.Errorf("failed to read caCertFile: %w", err)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return fmt.Errorf("caCertFile %s contains no PEM certificates", c.CACertFile)
+	}
+	return nil
+}
+
+// issueTemplate names an issue body template setting.
+type issueTemplate struct {
+	name string
+	text *string
+}
+
+func (c *Config) issueTemplates() []issueTemplate {
+	return []issueTemplate{
+		{"helmIssueTemplate", &c.HelmIssueTemplate},
+		{"containerIssueTemplate", &c.ContainerIssueTemplate},
+	}
+}
+
+// splitList parses a comma-separated environment value, trimming whitespace and dropping empty entries.
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
+	return items
+}
+
+func (c *Config) validate(requireIssueTracker bool) error {
+	validIssueBackends := map[string]bool{"github": true, "gitlab": true}
+	if !validIssueBackends[c.IssueBackend] {
+		return fmt.Errorf("invalid issueBackend: %s (must be github or gitlab)", c.IssueBackend)
+	}
+
+	// Issue tracker credentials only required in github output mode
+	if requireIssueTracker && !c.IsMarkdownMode() && !c.IsSARIFMode() && !c.IsCSVMode() {
+		if c.IsGitLabBackend() {
+			if c.GitLabToken == "" {
+				return fmt.Errorf("gitlab token is required (set GITLAB_TOKEN or gitlabToken in config)")
+			}
+			if c.GitLabProjectID == "" {
+				return fmt.Errorf("gitlab project is required (set GITLAB_PROJECT_ID or gitlabProjectId in config)")
+			}
+		} else {
+			if c.GitHubToken == "" {
+				return fmt.Errorf("github token is required (set GITHUB_TOKEN, GITHUB_TOKEN_FILE, githubToken or githubTokenFile in config)")
+			}
+			if c.GitHubOwner == "" {
+				return fmt.Errorf("github owner is required (set GITHUB_OWNER or githubOwner in config)")
+			}
+			if c.GitHubRepo == "" {
+				return fmt.Errorf("github repo is required (set GITHUB_REPO or githubRepo in config)")
+			}
+		}
+	}
+
+	if c.GitHubMaxAttempts < 1 {
+		return fmt.Errorf("invalid githubMaxAttempts: %d (must be at least 1)", c.GitHubMaxAttempts)
+	}
+	if c.IssueConcurrency < 1 {
+		return fmt.Errorf("invalid issueConcurrency: %d (must be at least 1)", c.IssueConcurrency)
+	}
+	if c.HealthFailureThreshold < 1 {
+		return fmt.Errorf("invalid healthFailureThreshold: %d (must be at least 1)", c.HealthFailureThreshold)
+	}
+	if c.MaxWorkloadRows < 0 {
+		return fmt.Errorf("invalid maxWorkloadRows: %d (must be at least 0)", c.MaxWorkloadRows)
+	}
+
+	if c.OnlyNew && c.StateFile == "" {
+		return fmt.Errorf("onlyNew requires stateFile")
+	}
+	if c.SkipAllHelmManagedContainers && !c.ScanHelm {
+		return fmt.Errorf("skipAllHelmManagedContainers requires scanHelm")
+	}
+	if c.SkipAllHelmManagedContainers && !c.IncludeAllReleases {
+		// Without --include-all nova only reports outdated releases, so up-to-date ones can't be matched
+		return fmt.Errorf("skipAllHelmManagedContainers requires includeAllReleases")
+	}
+
+	// The GitLab backend files all issues into one project and only deduplicates open issues
+	if c.IsGitLabBackend() {
+		unsupported := []struct {
+			name string
+			set  bool
+		}{
+			{"repoRouting", len(c.RepoRouting) > 0},
+			{"reopenClosed", c.ReopenClosed},
+			{"closeResolved", c.CloseResolved},
+			{"createSummaryIssue", c.CreateSummaryIssue},
+		}
+		for _, option := range unsupported {
+			if option.set {
+				return fmt.Errorf("%s is not supported with issueBackend gitlab", option.name)
+			}
+		}
+	}
+
+	for namespace, route := range c.RepoRouting {
+		if route.Repo == "" {
+			return fmt.Errorf("invalid repoRouting for namespace %s: repo is required", namespace)
+		}
+	}
+
+	if c.ScanInterval < 0 {
+		return fmt.Errorf("invalid scanInterval: %s (must not be negative)", c.ScanInterval)
+	}
+	if c.StartJitter < 0 {
+		return fmt.Errorf("invalid startJitter: %s (must not be negative)", c.StartJitter)
+	}
+	if c.MinReleaseAge < 0 {
+		return fmt.Errorf("invalid minReleaseAge: %s (must not be negative)", c.MinReleaseAge)
+	}
+	if c.MinReleaseAge > 0 && !c.PollArtifactHub {
+		// Without ArtifactHub there are no publish dates, so every release would be reported
+		return fmt.Errorf("minReleaseAge requires pollArtifactHub")
+	}
+	if c.MinVersionsBehind < 0 {
+		return fmt.Errorf("invalid minVersionsBehind: %d (must be at least 0)", c.MinVersionsBehind)
+	}
+	if c.MinVersionsBehind > 0 && !c.PollArtifactHub {
+		// Version counts come from ArtifactHub, so without it every release would be reported
+		return fmt.Errorf("minVersionsBehind requires pollArtifactHub")
+	}
+	if c.ArtifactHubSecurity && !c.PollArtifactHub {
+		// Security reports come from ArtifactHub, so without it nothing would be escalated
+		return fmt.Errorf("artifactHubSecurity requires pollArtifactHub")
+	}
 
 	validSeverities := map[string]bool{"minor": true, "major": true, "critical": true}
 	if !validSeverities[c.MinSeverity] {
 		return fmt.Errorf("invalid minSeverity: %s (must be minor, major, or critical)", c.MinSeverity)
 	}
 
-	validOutputModes := map[string]bool{"github": true, "markdown": true}
+	validCalverComponents := map[string]bool{"year": true, "month": true, "day": true}
+	validBumps := map[string]bool{"patch": true, "minor": true, "major": true}
+	for component, bump := range c.CalverSeverity {
+		if !validCalverComponents[component] {
+			return fmt.Errorf("invalid calverSeverity component: %s (must be year, month, or day)", component)
+		}
+		if !validBumps[bump] {
+			return fmt.Errorf("invalid calverSeverity for %s: %s (must be patch, minor, or major)", component, bump)
+		}
+	}
+
+	for _, pattern := range c.IgnoreNamespaces {
+		if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("invalid ignoreNamespaces regexp %q: %w", expr, err)
+			}
+		}
+	}
+
+	for name, patterns := range map[string][]string{"onlyRegistries": c.OnlyRegistries, "ignoreRegistries": c.IgnoreRegistries} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", name, pattern, err)
+			}
+		}
+	}
+
+	validGroupBy := map[string]bool{"component": true, "namespace": true}
+	if !validGroupBy[c.GroupBy] {
+		return fmt.Errorf("invalid groupBy: %s (must be component or namespace)", c.GroupBy)
+	}
+
+	validGranularities := map[string]bool{"image": true, "workload": true}
+	if !validGranularities[c.ContainerIssueGranularity] {
+		return fmt.Errorf("invalid containerIssueGranularity: %s (must be image or workload)", c.ContainerIssueGranularity)
+	}
+
+	validGitOpsTools := map[string]bool{"flux": true, "argocd": true, "none": true}
+	if !validGitOpsTools[c.GitOpsTool] {
+		return fmt.Errorf("invalid gitOpsTool: %s (must be flux, argocd, or none)", c.GitOpsTool)
+	}
+
+	validOutputModes := map[string]bool{"github": true, "markdown": true, "sarif": true, "csv": true}
 	if !validOutputModes[c.OutputMode] {
-		return fmt.Errorf("invalid outputMode: %s (must be github or markdown)", c.OutputMode)
+		return fmt.Errorf("invalid outputMode: %s (must be github, markdown, sarif, or csv)", c.OutputMode)
+	}
+
+	if c.PushgatewayUsername != "" && c.PushgatewayBearerToken != "" {
+		return fmt.Errorf("pushgatewayUsername and pushgatewayBearerToken are mutually exclusive")
+	}
+
+	// Catch typos like "localhost:9091" now rather than when pushing after a full scan
+	endpoints := []struct{ name, value string }{
+		{"pushgatewayUrl", c.PushgatewayURL},
+		{"slackWebhookUrl", c.SlackWebhookURL},
+		{"webhookUrl", c.WebhookURL},
+		{"gitlabUrl", c.GitLabURL},
+		{"githubBaseUrl", c.GitHubBaseURL},
+		{"httpProxy", c.HTTPProxy},
+	}
+	for _, endpoint := range endpoints {
+		if err := validateURL(endpoint.name, endpoint.value); err != nil {
+			return err
+		}
+	}
+
+	if !metricPrefixPattern.MatchString(c.MetricPrefix) {
+		return fmt.Errorf("invalid metricPrefix: %q (must start with a letter or underscore followed by letters, digits or underscores)", c.MetricPrefix)
+	}
+
+	validLogFormats := map[string]bool{"json": true, "console": true}
+	if !validLogFormats[c.LogFormat] {
+		return fmt.Errorf("invalid logFormat: %s (must be json or console)", c.LogFormat)
+	}
+
+	validLogLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	for component, level := range c.ComponentLogLevels {
+		if !validLogLevels[level] {
+			return fmt.Errorf("invalid componentLogLevels for %s: %q (must be debug, info, warn or error)", component, level)
+		}
+	}
+
+	validLogDestinations := map[string]bool{"stdout": true, "stderr": true}
+	if !validLogDestinations[c.HumanLogTo] {
+		return fmt.Errorf("invalid humanLogTo: %s (must be stdout or stderr)", c.HumanLogTo)
 	}
 
 	return nil
 }
 
+// metricPrefixPattern matches prefixes that form valid Prometheus metric names with a suffix.
+var metricPrefixPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// validateURL checks that a non-empty endpoint is an absolute http(s) URL.
+// The value is left out of the error because webhook URLs embed secrets.
+func validateURL(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s: must be an http or https URL with a host (e.g. http://pushgateway:9091)", name)
+	}
+	return nil
+}
+
 // SeverityLevel returns a numeric value for the severity level for comparison.
 // higher value = more severe
 func (c *Config) SeverityLevel() int {
@@ -174,17 +968,68 @@ func (c *Config) SeverityLevel() int {
 	}
 }
 
-// ShouldIgnoreVersion returns true if the version matches any of the blacklist patterns.
-// Patterns are matched as substrings (e.g., "-develop" matches "9.2.0-develop.18").
+// CalverSeverityLevel returns the severity of a calver upgrade that changed the given date
+// component ("year", "month" or "day"), using the same scale as semver bumps:
+// 3 = major, 2 = minor, 1 = patch.
+func (c *Config) CalverSeverityLevel(component string) int {
+	switch c.CalverSeverity[component] {
+	case "major":
+		return 3
+	case "minor":
+		return 2
+	default:
+		return 1 // patch
+	}
+}
+
+// RepoFor returns the owner and repository receiving the issues of a namespace:
+// its repoRouting entry, or githubOwner/githubRepo when the namespace isn't routed.
+func (c *Config) RepoFor(namespace string) (owner, repo string) {
+	route, ok := c.RepoRouting[namespace]
+	if !ok {
+		return c.GitHubOwner, c.GitHubRepo
+	}
+	if route.Owner == "" {
+		return c.GitHubOwner, route.Repo
+	}
+	return route.Owner, route.Repo
+}
+
+// ShouldIgnoreVersion returns true if the version matches any of the blacklist patterns
+// (see matchVersionPattern).
 func (c *Config) ShouldIgnoreVersion(version string) bool {
 	for _, pattern := range c.IgnoreVersionPatterns {
-		if strings.Contains(version, pattern) {
+		if matchVersionPattern(pattern, version) {
 			return true
 		}
 	}
 	return false
 }
 
+// matchVersionPattern reports whether a version matches an ignore pattern. A pattern starting
+// with "-" names a semver pre-release: it matches the start of the version's first pre-release
+// identifier when no letter follows, so "-rc" matches "1.0.0-rc1" and "1.0.0-rc.2" but not
+// "1.0.0-rceng" or the build metadata of "1.0.0+rc". Other patterns, and versions that aren't
+// semver, are matched as substrings (e.g., "2023." matches "2023.9.18").
+func matchVersionPattern(pattern, version string) bool {
+	label, ok := strings.CutPrefix(pattern, "-")
+	if !ok || label == "" {
+		return strings.Contains(version, pattern)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return strings.Contains(version, pattern)
+	}
+
+	identifier, _, _ := strings.Cut(v.Prerelease(), ".")
+	rest, ok := strings.CutPrefix(identifier, label)
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLetter(r)
+}
+
 // ShouldIgnoreChartVersion returns true if the version should be ignored for a specific chart.
 // It checks both global ignoreVersionPatterns and chart-specific chartVersionIgnorePatterns.
 func (c *Config) ShouldIgnoreChartVersion(chartName, version string) bool {
@@ -196,10 +1041,68 @@ func (c *Config) ShouldIgnoreChartVersion(chartName, version string) bool {
 	// Then check chart-specific patterns
 	if patterns, ok := c.ChartVersionIgnorePatterns[chartName]; ok {
 		for _, pattern := range patterns {
-			if strings.Contains(version, pattern) {
+			if matchVersionPattern(pattern, version) {
 				return true
 			}
 		}
 	}
 	return false
 }
+
+// CanonicalChartName resolves a chart name through chartAliases.
+// Charts renamed upstream (e.g., "nginx-ingress" -> "ingress-nginx") map to one
+// canonical name so they keep tracking to the same issue across the rename.
+func (c *Config) CanonicalChartName(chartName string) string {
+	if canonical, ok := c.ChartAliases[chartName]; ok && canonical != "" {
+		return canonical
+	}
+	return chartName
+}
+
+// redactedValue replaces secrets in Redacted.
+const redactedValue = "***"
+
+// Redacted returns a copy of the config with tokens, passwords, webhook URLs (which embed
+// secrets) and webhook header values replaced by "***", and credentials in other URLs replaced
+// by "REDACTED", for printing. Unset secrets stay empty.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	for _, secret := range []*string{
+		&redacted.GitHubToken,
+		&redacted.GitLabToken,
+		&redacted.PushgatewayPassword,
+		&redacted.PushgatewayBearerToken,
+		&redacted.SlackWebhookURL,
+		&redacted.WebhookURL,
+	} {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+	for _, endpoint := range []*string{
+		&redacted.HTTPProxy,
+		&redacted.PushgatewayURL,
+		&redacted.GitHubBaseURL,
+		&redacted.GitLabURL,
+		&redacted.OTLPEndpoint,
+	} {
+		*endpoint = redactURLUserinfo(*endpoint)
+	}
+	if c.WebhookHeaders != nil {
+		redacted.WebhookHeaders = make(map[string]string, len(c.WebhookHeaders))
+		for name := range c.WebhookHeaders {
+			redacted.WebhookHeaders[name] = redactedValue
+		}
+	}
+	return &redacted
+}
+
+// redactURLUserinfo replaces the user:pass@ part of rawURL, if any, by "REDACTED".
+func redactURLUserinfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = url.User("REDACTED")
+	return u.String()
+}
@@ -0,0 +1,84 @@
+package github
+
+import (
+	"strings"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// IssueContent is a component issue rendered for another issue backend, with the same title,
+// body and labels as its GitHub issue. The body ends with the hidden fingerprint marker, so
+// backends deduplicate the same way (see HasFingerprint).
+type IssueContent struct {
+	Title       string
+	Body        string
+	Labels      []string
+	Fingerprint string
+
+	// namespace marks a namespace issue, whose title carries no versions.
+	namespace bool
+}
+
+// HelmIssueContent renders the issue for an outdated Helm release.
+func HelmIssueContent(cfg *config.Config, release nova.ReleaseOutput) (IssueContent, error) {
+	body, err := RenderHelmIssueBody(cfg, release)
+	if err != nil {
+		return IssueContent{}, err
+	}
+	fingerprint := helmFingerprint(cfg, release)
+	return IssueContent{
+		Title:       FormatHelmIssueTitle(cfg.TitlePrefix(), release),
+		Body:        body + formatFingerprintMarker(fingerprint),
+		Labels:      helmLabels(cfg, release),
+		Fingerprint: fingerprint,
+	}, nil
+}
+
+// ContainerIssueContent renders the issue for an outdated container image.
+func ContainerIssueContent(cfg *config.Config, container nova.ContainerOutput) (IssueContent, error) {
+	body, err := RenderContainerIssueBody(cfg, container)
+	if err != nil {
+		return IssueContent{}, err
+	}
+	fingerprint := containerFingerprint(cfg, container)
+	return IssueContent{
+		Title:       ContainerIssueTitle(cfg, container),
+		Body:        body + formatFingerprintMarker(fingerprint),
+		Labels:      containerLabels(cfg, container),
+		Fingerprint: fingerprint,
+	}, nil
+}
+
+// NamespaceIssueContent renders the issue for the outdated components of a namespace.
+func NamespaceIssueContent(cfg *config.Config, group NamespaceGroup) IssueContent {
+	fingerprint := namespaceFingerprint(cfg, group)
+	return IssueContent{
+		Title:       FormatNamespaceIssueTitle(cfg.TitlePrefix(), group),
+		Body:        truncateIssueBody(FormatNamespaceIssueBody(group)) + formatFingerprintMarker(fingerprint),
+		Labels:      namespaceLabels(cfg, group),
+		Fingerprint: fingerprint,
+		namespace:   true,
+	}
+}
+
+// Update reports whether an existing issue with title and body is out of date, and the comment
+// to post when updating it. A component issue is updated when its title changed, commenting
+// only when the latest version moved on; a namespace issue when its title or body changed.
+func (c IssueContent) Update(title, body string) (update bool, comment string) {
+	if c.namespace {
+		if title == c.Title && sameIssueBody(body, c.Body) {
+			return false, ""
+		}
+		return true, namespaceUpdateComment
+	}
+	if title == c.Title {
+		return false, ""
+	}
+	return true, versionChangeComment(title, c.Title)
+}
+
+// HasFingerprint reports whether an issue body carries the marker of the fingerprint.
+func HasFingerprint(body, fingerprint string) bool {
+	return strings.Contains(body, strings.TrimSpace(formatFingerprintMarker(fingerprint)))
+}
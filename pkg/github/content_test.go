@@ -0,0 +1,46 @@
+package github
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func TestIssueContent(t *testing.T) {
+	cfg := &config.Config{IssueTitlePrefix: "[Nova prod]", SeverityLabelPrefix: "nova-severity:"}
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		ChartName:   "my-chart",
+		Namespace:   "default",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+
+	helm, err := HelmIssueContent(cfg, release)
+	if err != nil {
+		t.Fatalf("HelmIssueContent() error = %v", err)
+	}
+	if helm.Title != FormatHelmIssueTitle("[Nova prod]", release) {
+		t.Errorf("unexpected helm title %q", helm.Title)
+	}
+	if !strings.HasPrefix(helm.Body, FormatHelmIssueBody(release)) || !HasFingerprint(helm.Body, helm.Fingerprint) {
+		t.Errorf("expected the rendered body followed by the fingerprint marker, got %q", helm.Body)
+	}
+	if want := []string{"nova-scan", "helm-update", "nova-severity:major"}; !reflect.DeepEqual(helm.Labels, want) {
+		t.Errorf("helm labels = %v, want %v", helm.Labels, want)
+	}
+
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20.0", LatestTag: "1.25.0",
+		AffectedWorkloads: []nova.WorkloadOutput{{Name: "web", Namespace: "default"}}}
+	group := GroupByNamespace([]nova.ReleaseOutput{release}, []nova.ContainerOutput{container})[0]
+	namespace := NamespaceIssueContent(cfg, group)
+	if want := []string{"nova-scan", "helm-update", "container-update", "nova-severity:major"}; !reflect.DeepEqual(namespace.Labels, want) {
+		t.Errorf("namespace labels = %v, want %v", namespace.Labels, want)
+	}
+	if HasFingerprint(namespace.Body, helm.Fingerprint) {
+		t.Error("expected the namespace issue not to carry the release fingerprint")
+	}
+}
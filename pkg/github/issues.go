@@ -2,225 +2,1060 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/go-github/v57/github"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/httpclient"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
 const (
+	// labelNovaScan is always applied; dedup only considers issues carrying it.
 	labelNovaScan        = "nova-scan"
-	labelClaudeCode      = "claude-code"
 	labelHelmUpdate      = "helm-update"
 	labelContainerUpdate = "container-update"
+	labelSecurity        = "security"
+	labelSummary         = "nova-summary"
+
+	// GitOps tools whose update instructions are rendered in Helm issues.
+	gitOpsFlux   = "flux"
+	gitOpsArgoCD = "argocd"
+	gitOpsNone   = "none"
+
+	// fingerprintPrefix marks the hidden dedup fingerprint embedded in issue bodies.
+	fingerprintPrefix = "nova-scanner:fingerprint="
+	// traceIDPrefix marks the hidden trace ID of the scan run that created an issue.
+	traceIDPrefix = "nova-scanner:trace-id="
+
+	// maxIssueBodyLength bounds a rendered issue body below GitHub's 65536 character limit,
+	// leaving room for the fingerprint and trace ID markers.
+	maxIssueBodyLength = 65536 - 1024
+	// truncatedBodyNote ends an issue body cut to maxIssueBodyLength.
+	truncatedBodyNote = "\n_Issue body truncated: it exceeded the maximum issue body length._\n"
+
+	// maxRateLimitRetries bounds how often a rate-limited GitHub request is retried.
+	maxRateLimitRetries = 3
+	// defaultRetryBackoff is the wait before the first retry of a transient failure; it doubles per retry.
+	defaultRetryBackoff = time.Second
+	// defaultSecondaryRateLimitWait is used when a secondary rate limit response has no Retry-After.
+	defaultSecondaryRateLimitWait = time.Minute
+	// reopenLookback bounds how long ago a closed issue may have been updated to still be reopened.
+	reopenLookback = 30 * 24 * time.Hour
 )
 
 // IssueManager handles GitHub issue creation and deduplication.
+// It is safe for concurrent use; issues with the same fingerprint are deduplicated one at a time.
 type IssueManager struct {
 	client *github.Client
+	config *config.Config
 	owner  string
 	repo   string
 	dryRun bool
 	logger *logging.Logger
+
+	// mu guards the caches, counters and routed managers below.
+	mu sync.Mutex
+	// inflight holds a lock per fingerprint, so concurrent creates of the same component
+	// don't both miss the cache and create duplicate issues.
+	inflight map[string]*sync.Mutex
+
+	// openIssues caches the open nova-scan issues for dedup; nil until first listed.
+	openIssues []*github.Issue
+	// closedIssues caches the recently closed nova-scan issues (reopenClosed only); nil until first listed.
+	closedIssues []*github.Issue
+	// suppressedIssues caches the open and closed issues carrying the suppress label; nil until first listed.
+	suppressedIssues []*github.Issue
+
+	// skipped counts issues not created because an identical one is already open or suppressed.
+	skipped int
+	// created records the component issues created this run, for the summary issue.
+	created []CreatedIssue
+
+	// retryBackoff is the wait before the first retry of a transient failure.
+	retryBackoff time.Duration
+	// rate is the rate limit quota reported by the latest GitHub response, shared with routed managers.
+	rate *rateLimit
+
+	// routed holds the issue managers of repoRouting repositories, keyed by owner/repo.
+	// Each keeps its own dedup caches and counters.
+	routed map[string]*IssueManager
 }
 
 // NewIssueManager creates a new IssueManager instance.
-func NewIssueManager(token, owner, repo string, dryRun bool, logger *logging.Logger) *IssueManager {
-	ctx := context.Background()
+func NewIssueManager(ctx context.Context, cfg *config.Config, logger *logging.Logger) *IssueManager {
+	// oauth2 wraps the client from the context, so GitHub requests go through the proxy too
+	ctx = context.WithValue(ctx, oauth2.HTTPClient,
+		&http.Client{Transport: httpclient.FromConfig(cfg)})
 	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
+		&oauth2.Token{AccessToken: cfg.GitHubToken},
 	)
 	tc := oauth2.NewClient(ctx, ts)
 	client := github.NewClient(tc)
+	if cfg.GitHubBaseURL != "" {
+		// Invalid URLs are rejected when the config is loaded
+		if enterprise, err := client.WithEnterpriseURLs(cfg.GitHubBaseURL, cfg.GitHubBaseURL); err == nil {
+			client = enterprise
+		}
+	}
 
 	return &IssueManager{
 		client: client,
-		owner:  owner,
-		repo:   repo,
-		dryRun: dryRun,
+		config: cfg,
+		owner:  cfg.GitHubOwner,
+		repo:   cfg.GitHubRepo,
+		dryRun: cfg.DryRun,
 		logger: logger.WithComponent("github"),
+
+		retryBackoff: defaultRetryBackoff,
+		rate:         &rateLimit{},
 	}
 }
 
+// rateLimit holds the rate limit quota reported by the latest GitHub response.
+type rateLimit struct {
+	mu   sync.Mutex
+	rate github.Rate
+}
+
 // CreateHelmIssue creates a GitHub issue for an outdated Helm release.
 // Returns the issue URL if created, empty string if skipped.
-func (im *IssueManager) CreateHelmIssue(ctx context.Context, release nova.ReleaseOutput) (string, error) {
-	title := FormatHelmIssueTitle(release)
+func (im *IssueManager) CreateHelmIssue(ctx context.Context, release nova.ReleaseOutput) (issueURL string, err error) {
+	im = im.forNamespaces(release.Namespace)
+	title := FormatHelmIssueTitle(im.config.TitlePrefix(), release)
+	ctx, span := startIssueSpan(ctx, "helm", title)
+	defer func() { tracing.End(span, err) }()
+	fingerprint := helmFingerprint(im.config, release)
+	defer im.lockFingerprint(fingerprint)()
 
 	// Check if issue already exists
-	exists, err := im.issueExists(ctx, title)
+	existing, err := im.findExistingIssue(ctx, title, fingerprint)
 	if err != nil {
 		return "", fmt.Errorf("failed to check existing issues: %w", err)
 	}
-	if exists {
-		im.logger.IssueSkipped("helm", title, "duplicate")
-		return "", nil
-	}
-
-	body := FormatHelmIssueBody(release)
-
-	if im.dryRun {
-		im.logger.IssueDryRun("helm", title)
+	if existing != nil {
+		if im.isSuppressed(existing) {
+			im.suppressIssue("helm", title, existing)
+			return "", nil
+		}
+		if isClosed(existing) {
+			body, err := RenderHelmIssueBody(im.config, release)
+			if err != nil {
+				return "", err
+			}
+			return "", im.reopenIssue(ctx, "helm", existing, title, body+formatFingerprintMarker(fingerprint))
+		}
+		if existing.GetTitle() != title {
+			return "", im.UpdateHelmIssue(ctx, existing.GetNumber(), existing.GetTitle(), release)
+		}
+		im.skipIssue("helm", title)
 		return "", nil
 	}
 
-	issue, _, err := im.client.Issues.Create(ctx, im.owner, im.repo, &github.IssueRequest{
-		Title:  github.String(title),
-		Body:   github.String(body),
-		Labels: &[]string{labelNovaScan, labelClaudeCode, labelHelmUpdate},
-	})
+	body, err := RenderHelmIssueBody(im.config, release)
 	if err != nil {
-		return "", fmt.Errorf("failed to create issue: %w", err)
+		return "", err
 	}
+	body += formatFingerprintMarker(fingerprint)
 
-	im.logger.IssueCreated("helm", title, issue.GetHTMLURL())
-	return issue.GetHTMLURL(), nil
+	return im.createIssue(ctx, "helm", title, body, helmLabels(im.config, release), im.assignees(release.Namespace))
 }
 
 // CreateContainerIssue creates a GitHub issue for an outdated container image.
 // Returns the issue URL if created, empty string if skipped.
-func (im *IssueManager) CreateContainerIssue(ctx context.Context, container nova.ContainerOutput) (string, error) {
-	title := FormatContainerIssueTitle(container)
+func (im *IssueManager) CreateContainerIssue(ctx context.Context, container nova.ContainerOutput) (issueURL string, err error) {
+	im = im.forNamespaces(workloadNamespaces(container.AffectedWorkloads)...)
+	title := ContainerIssueTitle(im.config, container)
+	ctx, span := startIssueSpan(ctx, "container", title)
+	defer func() { tracing.End(span, err) }()
+	fingerprint := containerFingerprint(im.config, container)
+	defer im.lockFingerprint(fingerprint)()
 
 	// Check if issue already exists
-	exists, err := im.issueExists(ctx, title)
+	existing, err := im.findExistingIssue(ctx, title, fingerprint)
 	if err != nil {
 		return "", fmt.Errorf("failed to check existing issues: %w", err)
 	}
-	if exists {
-		im.logger.IssueSkipped("container", title, "duplicate")
+	if existing != nil {
+		if im.isSuppressed(existing) {
+			im.suppressIssue("container", title, existing)
+			return "", nil
+		}
+		if isClosed(existing) {
+			body, err := RenderContainerIssueBody(im.config, container)
+			if err != nil {
+				return "", err
+			}
+			return "", im.reopenIssue(ctx, "container", existing, title, body+formatFingerprintMarker(fingerprint))
+		}
+		if existing.GetTitle() != title {
+			return "", im.UpdateContainerIssue(ctx, existing.GetNumber(), existing.GetTitle(), container)
+		}
+		im.skipIssue("container", title)
 		return "", nil
 	}
 
-	body := FormatContainerIssueBody(container)
+	body, err := RenderContainerIssueBody(im.config, container)
+	if err != nil {
+		return "", err
+	}
+	body += formatFingerprintMarker(fingerprint)
+
+	return im.createIssue(ctx, "container", title, body, containerLabels(im.config, container),
+		im.assignees(workloadNamespaces(container.AffectedWorkloads)...))
+}
+
+// startIssueSpan starts the span covering dedup and creation or update of one issue.
+func startIssueSpan(ctx context.Context, issueType, title string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "github.CreateIssue", trace.WithAttributes(
+		attribute.String("issue.type", issueType),
+		attribute.String("issue.title", title),
+	))
+}
+
+// SkippedIssues returns the number of issues skipped as duplicates of open issues or as suppressed.
+func (im *IssueManager) SkippedIssues() int {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	skipped := im.skipped
+	for _, routed := range im.routed {
+		routed.mu.Lock()
+		skipped += routed.skipped
+		routed.mu.Unlock()
+	}
+	return skipped
+}
+
+// RateLimit returns the GitHub API rate limit quota reported by the latest response.
+// ok is false until a response carried rate limit headers.
+func (im *IssueManager) RateLimit() (remaining, limit int, ok bool) {
+	im.rate.mu.Lock()
+	defer im.rate.mu.Unlock()
+	return im.rate.rate.Remaining, im.rate.rate.Limit, im.rate.rate.Limit > 0
+}
+
+// lockFingerprint waits until no other issue with the fingerprint is being deduplicated or
+// created, and returns the func releasing it.
+func (im *IssueManager) lockFingerprint(fingerprint string) (unlock func()) {
+	im.mu.Lock()
+	lock, ok := im.inflight[fingerprint]
+	if !ok {
+		if im.inflight == nil {
+			im.inflight = make(map[string]*sync.Mutex)
+		}
+		lock = &sync.Mutex{}
+		im.inflight[fingerprint] = lock
+	}
+	im.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
 
+// forNamespaces returns the issue manager for the repository owning the given namespaces (see
+// config.RepoFor): im itself for the default repository, or one with its own dedup caches for a
+// routed repository. Items spanning namespaces routed to different repositories stay in the default one.
+func (im *IssueManager) forNamespaces(namespaces ...string) *IssueManager {
+	if len(im.config.RepoRouting) == 0 || len(namespaces) == 0 {
+		return im
+	}
+
+	owner, repo := im.config.RepoFor(namespaces[0])
+	for _, namespace := range namespaces[1:] {
+		if o, r := im.config.RepoFor(namespace); o != owner || r != repo {
+			return im
+		}
+	}
+	if owner == im.owner && repo == im.repo {
+		return im
+	}
+
+	key := owner + "/" + repo
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if routed, ok := im.routed[key]; ok {
+		return routed
+	}
+	routed := &IssueManager{
+		client: im.client,
+		config: im.config,
+		owner:  owner,
+		repo:   repo,
+		dryRun: im.dryRun,
+		logger: im.logger,
+
+		retryBackoff: im.retryBackoff,
+		rate:         im.rate,
+	}
+	if im.routed == nil {
+		im.routed = make(map[string]*IssueManager)
+	}
+	im.routed[key] = routed
+	return routed
+}
+
+// skipIssue records an issue that was not created because an identical one is open.
+func (im *IssueManager) skipIssue(issueType, title string) {
+	im.mu.Lock()
+	im.skipped++
+	im.mu.Unlock()
+	im.logger.IssueSkipped(issueType, title, "duplicate")
+}
+
+// suppressIssue records a component whose issue carries the suppress label and is left alone.
+func (im *IssueManager) suppressIssue(issueType, title string, issue *github.Issue) {
+	im.mu.Lock()
+	im.skipped++
+	im.mu.Unlock()
+	im.logger.IssueSuppressed(issueType, title, issue.GetNumber(), im.config.SuppressLabel)
+}
+
+// isSuppressed reports whether an issue carries the configured suppress label.
+func (im *IssueManager) isSuppressed(issue *github.Issue) bool {
+	return im.config.SuppressLabel != "" && hasLabel(issue, im.config.SuppressLabel)
+}
+
+// reopenIssue reopens a closed issue for a component that is still outdated, refreshing its title and body.
+func (im *IssueManager) reopenIssue(ctx context.Context, issueType string, issue *github.Issue, title, body string) error {
 	if im.dryRun {
-		im.logger.IssueDryRun("container", title)
+		im.logger.IssueReopenDryRun(issueType, title, issue.GetNumber())
+		return nil
+	}
+
+	reopened, err := im.editIssue(ctx, issue.GetNumber(), &github.IssueRequest{
+		Title: github.String(title),
+		Body:  github.String(im.withTraceID(body)),
+		State: github.String("open"),
+	}, im.reopenComment())
+	if err != nil {
+		return err
+	}
+
+	im.logger.IssueReopened(issueType, title, reopened.GetHTMLURL())
+	return nil
+}
+
+// reopenComment explains a reopened issue and how to stop tracking the component.
+func (im *IssueManager) reopenComment() string {
+	comment := "Still detected as outdated by nova-scanner, so this issue has been reopened."
+	if im.config.SuppressLabel != "" {
+		comment += " Add the `" + im.config.SuppressLabel + "` label to stop tracking this component."
+	}
+	return comment
+}
+
+// isClosed reports whether an issue found for dedup is closed (reopenClosed only).
+func isClosed(issue *github.Issue) bool {
+	return issue.GetState() == "closed"
+}
+
+// hasLabel reports whether an issue carries the given label.
+func hasLabel(issue *github.Issue, name string) bool {
+	for _, label := range issue.Labels {
+		if label.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// createIssue creates a GitHub issue, respecting dry-run mode.
+// If GitHub rejects the assignees (e.g., unknown user), the issue is created unassigned.
+func (im *IssueManager) createIssue(ctx context.Context, issueType, title, body string, labels, assignees []string) (string, error) {
+	if im.dryRun {
+		im.logger.IssueDryRun(issueType, title)
 		return "", nil
 	}
 
-	issue, _, err := im.client.Issues.Create(ctx, im.owner, im.repo, &github.IssueRequest{
+	req := &github.IssueRequest{
 		Title:  github.String(title),
-		Body:   github.String(body),
-		Labels: &[]string{labelNovaScan, labelClaudeCode, labelContainerUpdate},
-	})
+		Body:   github.String(im.withTraceID(body)),
+		Labels: &labels,
+	}
+	if len(assignees) > 0 {
+		req.Assignees = &assignees
+	}
+
+	create := func() (issue *github.Issue, err error) {
+		err = im.withRateLimitRetry(ctx, func() (resp *github.Response, err error) {
+			issue, resp, err = im.client.Issues.Create(ctx, im.owner, im.repo, req)
+			return resp, err
+		})
+		return issue, err
+	}
+
+	issue, err := create()
+	if err != nil && req.Assignees != nil && isValidationError(err) {
+		im.logger.Warn().Err(err).
+			Strs("assignees", assignees).
+			Str("title", title).
+			Msg("Failed to assign issue, creating it unassigned")
+		req.Assignees = nil
+		issue, err = create()
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to create issue: %w", err)
 	}
+	im.rememberIssue(issue)
+	if issueType != summaryIssueType {
+		im.mu.Lock()
+		im.created = append(im.created, CreatedIssue{Type: issueType, Title: title, URL: issue.GetHTMLURL()})
+		im.mu.Unlock()
+	}
 
-	im.logger.IssueCreated("container", title, issue.GetHTMLURL())
+	im.logger.IssueCreated(issueType, title, issue.GetHTMLURL())
 	return issue.GetHTMLURL(), nil
 }
 
-// issueExists checks if an open issue with the given title already exists.
-func (im *IssueManager) issueExists(ctx context.Context, title string) (bool, error) {
-	// Search for existing open issues with the nova-scan label
-	query := fmt.Sprintf("repo:%s/%s is:issue is:open label:%s in:title \"%s\"",
-		im.owner, im.repo, labelNovaScan, escapeSearchQuery(title))
+// assignees returns the assignees for an issue affecting the given namespaces.
+// Namespace-specific assignees take precedence over the default issueAssignees.
+func (im *IssueManager) assignees(namespaces ...string) []string {
+	var assignees []string
+	seen := make(map[string]bool)
+	for _, ns := range namespaces {
+		for _, assignee := range im.config.NamespaceAssignees[ns] {
+			if !seen[assignee] {
+				assignees = append(assignees, assignee)
+				seen[assignee] = true
+			}
+		}
+	}
+	if len(assignees) == 0 {
+		return im.config.IssueAssignees
+	}
+	return assignees
+}
+
+// workloadNamespaces returns the distinct namespaces of the given workloads.
+func workloadNamespaces(workloads []nova.WorkloadOutput) []string {
+	var namespaces []string
+	seen := make(map[string]bool)
+	for _, w := range workloads {
+		if !seen[w.Namespace] {
+			namespaces = append(namespaces, w.Namespace)
+			seen[w.Namespace] = true
+		}
+	}
+	return namespaces
+}
+
+// isValidationError reports whether err is a GitHub 422 Unprocessable Entity response,
+// which is returned for invalid assignees.
+func isValidationError(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil &&
+		errResp.Response.StatusCode == http.StatusUnprocessableEntity
+}
+
+// UpdateHelmIssue refreshes the title and body of an existing Helm issue whose title is out
+// of date. It comments only when the latest version has moved on, so an upgrade to a
+// version that still isn't the latest updates the issue quietly.
+func (im *IssueManager) UpdateHelmIssue(ctx context.Context, number int, previousTitle string, release nova.ReleaseOutput) error {
+	title := FormatHelmIssueTitle(im.config.TitlePrefix(), release)
+	body, err := RenderHelmIssueBody(im.config, release)
+	if err != nil {
+		return err
+	}
+	body += formatFingerprintMarker(helmFingerprint(im.config, release))
+	return im.updateIssue(ctx, "helm", number, title, body, versionChangeComment(previousTitle, title))
+}
 
-	result, _, err := im.client.Search.Issues(ctx, query, &github.SearchOptions{
-		ListOptions: github.ListOptions{PerPage: 1},
+// UpdateContainerIssue refreshes the title and body of an existing container issue whose title
+// is out of date. Like UpdateHelmIssue, it comments only when the latest tag has moved on.
+func (im *IssueManager) UpdateContainerIssue(ctx context.Context, number int, previousTitle string, container nova.ContainerOutput) error {
+	title := ContainerIssueTitle(im.config, container)
+	body, err := RenderContainerIssueBody(im.config, container)
+	if err != nil {
+		return err
+	}
+	body += formatFingerprintMarker(containerFingerprint(im.config, container))
+	return im.updateIssue(ctx, "container", number, title, body, versionChangeComment(previousTitle, title))
+}
+
+func (im *IssueManager) updateIssue(ctx context.Context, issueType string, number int, title, body, comment string) error {
+	if im.dryRun {
+		im.logger.IssueUpdateDryRun(issueType, title, number)
+		return nil
+	}
+
+	issue, err := im.editIssue(ctx, number, &github.IssueRequest{
+		Title: github.String(title),
+		Body:  github.String(im.withTraceID(body)),
+	}, comment)
+	if err != nil {
+		return err
+	}
+
+	im.logger.IssueUpdated(issueType, title, issue.GetHTMLURL())
+	return nil
+}
+
+// editIssue applies req to an issue and comments on it unless comment is empty, caching the
+// edited issue for dedup.
+func (im *IssueManager) editIssue(ctx context.Context, number int, req *github.IssueRequest, comment string) (*github.Issue, error) {
+	var issue *github.Issue
+	err := im.withRetry(ctx, func() (resp *github.Response, err error) {
+		issue, resp, err = im.client.Issues.Edit(ctx, im.owner, im.repo, number, req)
+		return resp, err
 	})
 	if err != nil {
-		return false, err
+		return nil, fmt.Errorf("failed to update issue #%d: %w", number, err)
+	}
+	im.rememberIssue(issue)
+
+	if comment == "" {
+		return issue, nil
+	}
+	if err := im.addComment(ctx, number, comment); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// addComment posts a comment on an issue.
+func (im *IssueManager) addComment(ctx context.Context, number int, comment string) error {
+	err := im.withRateLimitRetry(ctx, func() (*github.Response, error) {
+		_, resp, err := im.client.Issues.CreateComment(ctx, im.owner, im.repo, number, &github.IssueComment{
+			Body: github.String(comment),
+		})
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// issueLabels returns the configured issue labels plus the given per-issue labels.
+// The nova-scan label is always included so findExistingIssue keeps working.
+func issueLabels(cfg *config.Config, extra ...string) []string {
+	labels := []string{labelNovaScan}
+	seen := map[string]bool{labelNovaScan: true}
+	for _, label := range append(append([]string{}, cfg.IssueLabels...), extra...) {
+		if label != "" && !seen[label] {
+			labels = append(labels, label)
+			seen[label] = true
+		}
+	}
+	return labels
+}
+
+// severityLabel returns the label for an upgrade severity as returned by nova.VersionSeverity:
+// a major, minor or patch version bump, or unknown when the versions are not semver. Returns an
+// empty string when severity labels are disabled or there is no bump.
+func severityLabel(cfg *config.Config, severity int) string {
+	if cfg.SeverityLabelPrefix == "" {
+		return ""
+	}
+	switch severity {
+	case 3:
+		return cfg.SeverityLabelPrefix + "major"
+	case 2:
+		return cfg.SeverityLabelPrefix + "minor"
+	case 1:
+		return cfg.SeverityLabelPrefix + "patch"
+	case nova.SeverityUnknown:
+		return cfg.SeverityLabelPrefix + "unknown"
+	default:
+		return ""
+	}
+}
+
+// deprecatedLabel returns the label for deprecated charts, or an empty string.
+func deprecatedLabel(cfg *config.Config, deprecated bool) string {
+	if !deprecated {
+		return ""
+	}
+	return cfg.DeprecatedLabel
+}
+
+// helmLabels returns the labels of a Helm release issue.
+func helmLabels(cfg *config.Config, release nova.ReleaseOutput) []string {
+	extra := []string{labelHelmUpdate}
+	if release.SecurityAlert {
+		extra = append(extra, labelSecurity)
+	}
+	extra = append(extra,
+		severityLabel(cfg, release.UpgradeSeverity()),
+		deprecatedLabel(cfg, release.Deprecated),
+	)
+	return issueLabels(cfg, extra...)
+}
+
+// containerLabels returns the labels of a container image issue.
+func containerLabels(cfg *config.Config, container nova.ContainerOutput) []string {
+	return issueLabels(cfg, labelContainerUpdate,
+		severityLabel(cfg, nova.VersionSeverity(container.CurrentTag, container.LatestTag)))
+}
+
+// findExistingIssue returns the open issue tracking the given fingerprint, or nil if none exists.
+// An issue carrying the suppress label, open or closed, takes precedence. With reopenClosed,
+// a recently closed issue is returned when no open one matches.
+// Issues created before fingerprint markers were introduced are still matched by title.
+func (im *IssueManager) findExistingIssue(ctx context.Context, title, fingerprint string) (*github.Issue, error) {
+	if im.config.SuppressLabel != "" {
+		suppressed, err := im.suppressedNovaIssues(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if issue := matchIssue(suppressed, title, fingerprint); issue != nil {
+			return issue, nil
+		}
+	}
+
+	issues, err := im.openNovaIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if issue := matchIssue(issues, title, fingerprint); issue != nil || !im.config.ReopenClosed {
+		return issue, nil
+	}
+
+	closed, err := im.closedNovaIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return matchIssue(closed, title, fingerprint), nil
+}
+
+// matchIssue returns the issue carrying the fingerprint marker, falling back to a title match.
+// Titles start with the configured prefix, so the fallback never matches another deployment's
+// issue; the fingerprint is scoped by fingerprintScope. An issue whose title was edited is still
+// matched by its marker.
+func matchIssue(issues []*github.Issue, title, fingerprint string) *github.Issue {
+	for _, issue := range issues {
+		if HasFingerprint(issue.GetBody(), fingerprint) {
+			return issue
+		}
+	}
+	for _, issue := range issues {
+		if issue.GetTitle() == title {
+			return issue
+		}
+	}
+	return nil
+}
+
+// openNovaIssues returns the open nova-scan issues, listing them once per run so dedup
+// doesn't cost a search request (and search rate limit) per outdated component.
+func (im *IssueManager) openNovaIssues(ctx context.Context) ([]*github.Issue, error) {
+	// Concurrent callers wait for the first listing instead of listing again
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if im.openIssues != nil {
+		return im.openIssues, nil
+	}
+
+	issues, err := im.listNovaIssues(ctx, "open", time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	im.logger.Debug().Int("open_issues", len(issues)).Msg("Listed open nova-scan issues for deduplication")
+	im.openIssues = append([]*github.Issue{}, issues...)
+	return im.openIssues, nil
+}
+
+// closedNovaIssues returns the nova-scan issues closed within reopenLookback, listing them once per run.
+func (im *IssueManager) closedNovaIssues(ctx context.Context) ([]*github.Issue, error) {
+	// Concurrent callers wait for the first listing instead of listing again
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if im.closedIssues != nil {
+		return im.closedIssues, nil
+	}
+
+	issues, err := im.listNovaIssues(ctx, "closed", time.Now().Add(-reopenLookback))
+	if err != nil {
+		return nil, err
+	}
+	im.logger.Debug().Int("closed_issues", len(issues)).Msg("Listed recently closed nova-scan issues for reopening")
+	im.closedIssues = append([]*github.Issue{}, issues...)
+	return im.closedIssues, nil
+}
+
+// suppressedNovaIssues returns the open and closed nova-scan issues carrying the suppress label,
+// listing them once per run.
+func (im *IssueManager) suppressedNovaIssues(ctx context.Context) ([]*github.Issue, error) {
+	// Concurrent callers wait for the first listing instead of listing again
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if im.suppressedIssues != nil {
+		return im.suppressedIssues, nil
+	}
+
+	issues, err := im.listNovaIssues(ctx, "all", time.Time{}, im.config.SuppressLabel)
+	if err != nil {
+		return nil, err
+	}
+	im.logger.Debug().Int("suppressed_issues", len(issues)).Msg("Listed suppressed nova-scan issues")
+	im.suppressedIssues = append([]*github.Issue{}, issues...)
+	return im.suppressedIssues, nil
+}
+
+// listNovaIssues lists all issues in the given state carrying the nova-scan label and any extra
+// labels, following pagination. A non-zero since limits the listing to issues updated after it.
+func (im *IssueManager) listNovaIssues(ctx context.Context, state string, since time.Time, labels ...string) ([]*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:       state,
+		Labels:      append([]string{labelNovaScan}, labels...),
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var all []*github.Issue
+	for {
+		var issues []*github.Issue
+		var resp *github.Response
+		err := im.withRetry(ctx, func() (*github.Response, error) {
+			var err error
+			issues, resp, err = im.client.Issues.ListByRepo(ctx, im.owner, im.repo, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s issues: %w", state, err)
+		}
+
+		for _, issue := range issues {
+			// The issues API also returns pull requests
+			if !issue.IsPullRequest() {
+				all = append(all, issue)
+			}
+		}
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
+
+// rememberIssue records a created or edited issue in the open issue cache,
+// so later items in the same run dedup against it. The cache is copied rather than
+// modified in place, as concurrent callers may still be matching against it.
+func (im *IssueManager) rememberIssue(issue *github.Issue) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if im.openIssues == nil || issue == nil {
+		return
+	}
+	issues := slices.Clone(im.openIssues)
+	for i, cached := range issues {
+		if cached.GetNumber() == issue.GetNumber() {
+			issues[i] = issue
+			im.openIssues = issues
+			return
+		}
+	}
+	im.openIssues = append(issues, issue)
+}
 
-	return result.GetTotal() > 0, nil
+// withRetry calls fn, waiting out GitHub primary and secondary rate limits (up to
+// maxRateLimitRetries times) and retrying transient failures, 5xx responses and network errors,
+// with exponential backoff up to githubMaxAttempts attempts. Other errors, such as 4xx
+// responses, are returned immediately, as is the context error once ctx is done.
+// The rate limit quota reported by each response is recorded for RateLimit.
+func (im *IssueManager) withRetry(ctx context.Context, fn func() (*github.Response, error)) error {
+	return im.retry(ctx, true, fn)
 }
 
-// escapeSearchQuery escapes special characters for GitHub search.
-func escapeSearchQuery(s string) string {
-	// Remove characters that might break the search query
-	s = strings.ReplaceAll(s, "\"", "")
-	s = strings.ReplaceAll(s, "\\", "")
-	return s
+// withRateLimitRetry is withRetry for requests that aren't idempotent, such as creating an
+// issue or comment: a 5xx response or network error may come after GitHub created it, so
+// only rate-limited requests, which GitHub rejected, are retried. A create that failed
+// otherwise is retried by the next run, which finds the issue if it was created after all.
+func (im *IssueManager) withRateLimitRetry(ctx context.Context, fn func() (*github.Response, error)) error {
+	return im.retry(ctx, false, fn)
 }
 
-// FormatHelmIssueTitle generates the issue title for a Helm release.
-func FormatHelmIssueTitle(release nova.ReleaseOutput) string {
-	return fmt.Sprintf("[Nova] Update Helm chart: %s (%s → %s)",
+// retry implements withRetry, retrying transient failures only if transient is set.
+func (im *IssueManager) retry(ctx context.Context, transient bool, fn func() (*github.Response, error)) error {
+	rateLimited, attempts := 0, 1
+	for {
+		resp, err := fn()
+		if resp != nil && resp.Rate.Limit > 0 {
+			im.rate.mu.Lock()
+			im.rate.rate = resp.Rate
+			im.rate.mu.Unlock()
+		}
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		wait, limited := rateLimitWait(err)
+		switch {
+		case limited && rateLimited < maxRateLimitRetries:
+			rateLimited++
+			im.logger.Warn().Err(err).
+				Dur("wait", wait).
+				Int("attempt", rateLimited).
+				Msg("GitHub rate limit hit, waiting before retry")
+		case !limited && transient && isTransientError(err) && attempts < im.config.GitHubMaxAttempts:
+			wait = im.retryBackoff << (attempts - 1)
+			attempts++
+			im.logger.Warn().Err(err).
+				Dur("wait", wait).
+				Int("attempt", attempts).
+				Msg("GitHub request failed, retrying")
+		default:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// isTransientError reports whether a failed GitHub request is worth retrying:
+// a 5xx response or a network error.
+func isTransientError(err error) bool {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) {
+		return errResp.Response != nil && errResp.Response.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// rateLimitWait returns how long to wait before retrying a rate-limited request,
+// and false if err is not a rate limit error.
+func rateLimitWait(err error) (time.Duration, bool) {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return max(time.Until(rateErr.Rate.Reset.Time), 0), true
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return max(*abuseErr.RetryAfter, 0), true
+		}
+		return defaultSecondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+// helmFingerprint returns the version-independent dedup key for a Helm release.
+// The chart name is canonicalized through chartAliases so renamed charts keep their issue.
+func helmFingerprint(cfg *config.Config, release nova.ReleaseOutput) string {
+	return fmt.Sprintf("%s:%s/%s:%s",
+		fingerprintKind(cfg, "helm", release.Context),
+		release.Namespace,
+		release.ReleaseName,
+		cfg.CanonicalChartName(release.ChartName),
+	)
+}
+
+// containerFingerprint returns the version-independent dedup key for a container image, or for
+// the image in one workload when container issues are filed per workload.
+func containerFingerprint(cfg *config.Config, container nova.ContainerOutput) string {
+	fingerprint := fingerprintKind(cfg, "container", container.Context) + ":" + container.Name
+	if w, ok := issueWorkload(cfg, container); ok {
+		fingerprint += ":" + workloadKey(w)
+	}
+	return fingerprint
+}
+
+// fingerprintKind qualifies a fingerprint kind with the kube context in multi-context mode,
+// so the same component in different clusters is tracked by separate issues, and with the
+// deployment's title prefix (see fingerprintScope).
+func fingerprintKind(cfg *config.Config, kind, kubeContext string) string {
+	kind = fingerprintScope(cfg) + kind
+	if kubeContext == "" {
+		return kind
+	}
+	return kind + "@" + kubeContext
+}
+
+// fingerprintScope returns the qualifier of a custom issue title prefix, so scanner deployments
+// sharing a repository with different prefixes track the same component by separate issues.
+// The default prefix has none, keeping the fingerprints of existing issues.
+func fingerprintScope(cfg *config.Config) string {
+	if cfg.TitlePrefix() == config.DefaultIssueTitlePrefix {
+		return ""
+	}
+	return cfg.TitlePrefix() + " "
+}
+
+// formatFingerprintMarker renders a fingerprint as a hidden HTML comment for the issue body.
+func formatFingerprintMarker(fingerprint string) string {
+	return fmt.Sprintf("\n<!-- %s%s -->\n", fingerprintPrefix, fingerprint)
+}
+
+// formatTraceIDMarker returns the hidden marker recording the trace ID of the scan run that
+// last wrote an issue body.
+func formatTraceIDMarker(traceID string) string {
+	return fmt.Sprintf("\n<!-- %s%s -->\n", traceIDPrefix, traceID)
+}
+
+// scannerMarkerPattern matches the hidden markers the scanner adds to issue bodies.
+var scannerMarkerPattern = regexp.MustCompile(`<!-- nova-scanner:[^>]*-->`)
+
+// withTraceID appends the marker of this run's trace ID to an issue body, tying the issue to
+// the log lines of the run that created, updated or reopened it.
+func (im *IssueManager) withTraceID(body string) string {
+	return body + formatTraceIDMarker(im.logger.TraceID())
+}
+
+// sameIssueBody reports whether two issue bodies have the same content. It ignores the hidden
+// markers, such as the trace ID of the run that wrote a body, line endings (GitHub stores
+// CRLF for bodies edited in the web UI), trailing whitespace and blank lines.
+func sameIssueBody(a, b string) bool {
+	return normalizeIssueBody(a) == normalizeIssueBody(b)
+}
+
+// normalizeIssueBody returns the lines of an issue body that sameIssueBody compares.
+func normalizeIssueBody(body string) string {
+	body = scannerMarkerPattern.ReplaceAllString(body, "")
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// titleLatestVersion extracts the latest version from an issue title of the form "... (current → latest)".
+func titleLatestVersion(title string) string {
+	idx := strings.LastIndex(title, "→ ")
+	if idx < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(title[idx+len("→ "):], ")")
+}
+
+// versionChangeComment returns the comment for an issue retitled from previousTitle to title,
+// or "" if the latest version in the title is unchanged.
+func versionChangeComment(previousTitle, title string) string {
+	latest := titleLatestVersion(title)
+	if latest == titleLatestVersion(previousTitle) {
+		return ""
+	}
+	return formatVersionChangeComment(titleLatestVersion(previousTitle), latest)
+}
+
+func formatVersionChangeComment(previousVersion, latestVersion string) string {
+	if previousVersion == "" {
+		return fmt.Sprintf("nova-scanner detected a newer version: %s. Title and description have been updated.",
+			backtick(latestVersion))
+	}
+	return fmt.Sprintf("nova-scanner detected a newer version: %s → %s. Title and description have been updated.",
+		backtick(previousVersion), backtick(latestVersion))
+}
+
+// FormatHelmIssueTitle generates the issue title for a Helm release, starting with prefix.
+func FormatHelmIssueTitle(prefix string, release nova.ReleaseOutput) string {
+	return fmt.Sprintf("%s %sUpdate Helm chart: %s (%s → %s)",
+		prefix,
+		formatContextTag(release.Context),
 		release.ReleaseName,
 		release.Installed.Version,
 		release.Latest.Version,
 	)
 }
 
-// FormatContainerIssueTitle generates the issue title for a container image.
-func FormatContainerIssueTitle(container nova.ContainerOutput) string {
-	return fmt.Sprintf("[Nova] Update container image: %s (%s → %s)",
+// FormatContainerIssueTitle generates the issue title for a container image, starting with prefix.
+func FormatContainerIssueTitle(prefix string, container nova.ContainerOutput) string {
+	return fmt.Sprintf("%s %sUpdate container image: %s (%s → %s)",
+		prefix,
+		formatContextTag(container.Context),
 		container.Name,
 		container.CurrentTag,
 		container.LatestTag,
 	)
 }
 
-// FormatHelmIssueBody generates the issue body for a Helm release.
+// FormatHelmIssueBody generates the issue body for a Helm release with Flux update instructions.
 func FormatHelmIssueBody(release nova.ReleaseOutput) string {
+	return formatHelmIssueBody(release, gitOpsFlux)
+}
+
+// formatHelmIssueBody generates the issue body for a Helm release with update
+// instructions for the given GitOps tool.
+func formatHelmIssueBody(release nova.ReleaseOutput, gitOpsTool string) string {
 	deprecated := "No"
 	if release.Deprecated {
 		deprecated = "Yes"
 	}
 
 	return fmt.Sprintf(`## Outdated Helm Chart Detected
-
+%s
 | Field | Value |
 |-------|-------|
 | Release Name | %s |
 | Chart Name | %s |
-| Namespace | %s |
-| Current Version | %s |
+%s%s| Namespace | %s |
+%s%s| Current Version | %s |
 | Latest Version | %s |
-| Deprecated | %s |
-
+%s| Deprecated | %s |
+%s%s%s
 ## Update Checklist
 
 - [ ] Review changelog for breaking changes between %s and %s
-- [ ] Update HelmRelease manifest with new version
-- [ ] Commit and push to trigger Flux reconciliation
-- [ ] Verify Flux successfully reconciles the HelmRelease
-- [ ] Check application health post-upgrade
-
-## Flux Update (GitOps)
-
-Update your HelmRelease manifest:
-
-%s
-
-## Useful Commands
+%s- [ ] Check application health post-upgrade
 
-%s
-
----
+%s%s---
 *This issue was automatically created by nova-scanner*
 `,
+		formatChartDescription(release.Description, release.Icon),
 		backtick(release.ReleaseName),
 		backtick(release.ChartName),
+		formatChartHomeRow(release.Home),
+		formatRepositoryRow(release.RepositoryURL),
 		backtick(release.Namespace),
+		formatContextRow(release.Context),
+		formatHelmVersionRow(release.HelmVersion),
 		backtick(release.Installed.Version),
 		backtick(release.Latest.Version),
+		formatReleaseNotesRow(helmReleaseNotesURL(release)),
 		deprecated,
+		formatFirstSeenRow(release.FirstSeen),
+		formatSecurityNote(release.SecurityAlert),
+		formatOverriddenNote(release.Overridden),
 		release.Installed.Version,
 		release.Latest.Version,
-		formatYAMLSnippet(release.Latest.Version, release.Installed.Version),
-		formatHelmCommands(release.ReleaseName, release.Namespace),
+		formatGitOpsChecklist(gitOpsTool),
+		formatGitOpsSection(release, gitOpsTool),
+		formatHelmCommands(release.ReleaseName, release.Namespace, gitOpsTool),
 	)
 }
 
-// FormatContainerIssueBody generates the issue body for a container image.
-func FormatContainerIssueBody(container nova.ContainerOutput) string {
-	workloadTable := formatWorkloadTable(container.AffectedWorkloads)
+// FormatContainerIssueBody generates the issue body for a container image, listing at most
+// maxWorkloadRows affected workloads (0 = all).
+func FormatContainerIssueBody(container nova.ContainerOutput, maxWorkloadRows int) string {
+	workloadTable := formatWorkloadTable(container.AffectedWorkloads, maxWorkloadRows)
 
-	return fmt.Sprintf(`## Outdated Container Image Detected
+	heading := "Outdated Container Image Detected"
+	updateStep := "Update image tag in deployment manifest"
+	if container.DigestPinned {
+		heading = "Outdated Digest-Pinned Container Image Detected"
+		updateStep = fmt.Sprintf("Resolve the digest of the %s tag and update the pinned digest in the deployment manifest",
+			backtick(container.LatestTag))
+	}
+
+	return fmt.Sprintf(`## %s
 
 | Field | Value |
 |-------|-------|
 | Image | %s |
-| Current Tag | %s |
-| Latest Tag | %s |
-
+%s| Current Tag | %s |
+| %s | %s |
+%s%s%s
 ### Affected Workloads
 
 %s
@@ -228,7 +1063,7 @@ func FormatContainerIssueBody(container nova.ContainerOutput) string {
 ## Update Checklist
 
 - [ ] Review release notes for breaking changes
-- [ ] Update image tag in deployment manifest
+- [ ] %s
 - [ ] Commit and push to trigger Flux reconciliation
 - [ ] Verify pods restart with new image
 - [ ] Check application health
@@ -236,54 +1071,266 @@ func FormatContainerIssueBody(container nova.ContainerOutput) string {
 ---
 *This issue was automatically created by nova-scanner*
 `,
+		heading,
 		backtick(container.Name),
+		formatContextRow(container.Context),
 		backtick(container.CurrentTag),
+		targetTagLabel(container),
 		backtick(container.LatestTag),
+		formatReleaseNotesRow(containerReleaseNotesURL(container)),
+		formatFirstSeenRow(container.FirstSeen),
+		formatDigestNote(container.DigestPinned),
 		workloadTable,
+		updateStep,
 	)
 }
 
+// targetTagLabel names the upgrade target row: a pinned desired tag or nova's latest tag.
+func targetTagLabel(container nova.ContainerOutput) string {
+	if container.DesiredTag != "" {
+		return "Desired Tag"
+	}
+	return "Latest Tag"
+}
+
+// formatContextTag returns the title tag identifying the kube context, if any.
+func formatContextTag(kubeContext string) string {
+	if kubeContext == "" {
+		return ""
+	}
+	return "[" + kubeContext + "] "
+}
+
+// formatChartDescription returns the chart description paragraph, led by the chart icon if known.
+func formatChartDescription(description, icon string) string {
+	if description == "" {
+		return ""
+	}
+	if icon != "" {
+		return fmt.Sprintf("\n<img src=%q alt=\"\" height=\"20\"> %s\n", icon, description)
+	}
+	return "\n" + description + "\n"
+}
+
+// formatChartHomeRow returns the issue table row linking the chart home page, if known.
+func formatChartHomeRow(home string) string {
+	if home == "" {
+		return ""
+	}
+	return fmt.Sprintf("| Chart Home | [%s](%s) |\n", home, home)
+}
+
+// formatRepositoryRow returns the issue table row linking the chart repository, if known.
+func formatRepositoryRow(repositoryURL string) string {
+	if repositoryURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("| Chart Repository | [%s](%s) |\n", repositoryURL, repositoryURL)
+}
+
+// formatContextRow returns the issue table row identifying the kube context, if any.
+func formatContextRow(kubeContext string) string {
+	if kubeContext == "" {
+		return ""
+	}
+	return fmt.Sprintf("| Context | %s |\n", backtick(kubeContext))
+}
+
+// formatHelmVersionRow renders the Helm major version nova found the release installed with, if known.
+func formatHelmVersionRow(helmVersion string) string {
+	if helmVersion == "" {
+		return ""
+	}
+	return fmt.Sprintf("| Helm Version | %s |\n", backtick(helmVersion))
+}
+
+// formatFirstSeenRow renders the date a component was first detected as outdated, if known.
+func formatFirstSeenRow(firstSeen time.Time) string {
+	if firstSeen.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("| First Detected | %s |\n", firstSeen.UTC().Format(time.DateOnly))
+}
+
+func formatSecurityNote(securityAlert bool) string {
+	if !securityAlert {
+		return ""
+	}
+	return "\n> **Security:** ArtifactHub reports known vulnerabilities in the installed version that are reduced in the latest version.\n"
+}
+
+// formatOverriddenNote points out releases whose chart version nova reports as overridden,
+// where running an older version may be intentional.
+func formatOverriddenNote(overridden bool) string {
+	if !overridden {
+		return ""
+	}
+	return "\n> **Overridden:** The chart version of this release is overridden (e.g. pinned locally), so the installed version may be intentional. Check the override before upgrading.\n"
+}
+
+// formatDigestNote explains issues for images referenced by digest, which have no tag to bump.
+func formatDigestNote(digestPinned bool) string {
+	if !digestPinned {
+		return ""
+	}
+	return "\n> **Digest-pinned:** This image is referenced by digest, so nova compared it against the latest tag. Pin the digest of that tag to update it.\n"
+}
+
 func backtick(s string) string {
 	return "`" + s + "`"
 }
 
+// formatGitOpsChecklist returns the tool-specific update steps of the Helm issue checklist.
+func formatGitOpsChecklist(gitOpsTool string) string {
+	switch gitOpsTool {
+	case gitOpsArgoCD:
+		return `- [ ] Update the Application's targetRevision with the new version
+- [ ] Commit and push, then sync the Application in Argo CD
+- [ ] Verify the Application is Synced and Healthy
+`
+	case gitOpsNone:
+		return `- [ ] Update the chart version in your deployment configuration
+- [ ] Roll out the upgrade
+`
+	default:
+		return `- [ ] Update HelmRelease manifest with new version
+- [ ] Commit and push to trigger Flux reconciliation
+- [ ] Verify Flux successfully reconciles the HelmRelease
+`
+	}
+}
+
+// formatGitOpsSection returns the manifest update section for the GitOps tool, or an empty string for none.
+func formatGitOpsSection(release nova.ReleaseOutput, gitOpsTool string) string {
+	switch gitOpsTool {
+	case gitOpsArgoCD:
+		return fmt.Sprintf("## Argo CD Update (GitOps)\n\nUpdate the chart version in your Application manifest:\n\n%s\n\n",
+			formatArgoCDSnippet(release.Latest.Version, release.Installed.Version))
+	case gitOpsNone:
+		return ""
+	default:
+		return fmt.Sprintf("## Flux Update (GitOps)\n\nUpdate your HelmRelease manifest:\n\n%s\n\n",
+			formatYAMLSnippet(release.Latest.Version, release.Installed.Version))
+	}
+}
+
 func formatYAMLSnippet(latestVersion, currentVersion string) string {
 	return fmt.Sprintf("```yaml\nspec:\n  chart:\n    spec:\n      version: \"%s\"  # was: %s\n```",
 		latestVersion, currentVersion)
 }
 
-func formatHelmCommands(releaseName, namespace string) string {
-	return fmt.Sprintf(`%s
-# Check current HelmRelease status
+func formatArgoCDSnippet(latestVersion, currentVersion string) string {
+	return fmt.Sprintf("```yaml\nspec:\n  source:\n    targetRevision: \"%s\"  # was: %s\n```",
+		latestVersion, currentVersion)
+}
+
+// formatHelmCommands returns the "Useful Commands" section for the GitOps tool, or an empty string for none.
+func formatHelmCommands(releaseName, namespace, gitOpsTool string) string {
+	var commands string
+	switch gitOpsTool {
+	case gitOpsArgoCD:
+		commands = fmt.Sprintf(`# Check current Application status
+argocd app get %s
+
+# Preview and sync after commit
+argocd app diff %s
+argocd app sync %s
+
+# View Application deployment history
+argocd app history %s`,
+			releaseName,
+			releaseName, releaseName,
+			releaseName,
+		)
+	case gitOpsNone:
+		return ""
+	default:
+		commands = fmt.Sprintf(`# Check current HelmRelease status
 flux get helmreleases -n %s | grep %s
 
 # Force reconciliation after commit
 flux reconcile helmrelease %s -n %s
 
 # View Helm release history
-helm history %s -n %s
-%s`,
-		"```bash",
-		namespace, releaseName,
-		releaseName, namespace,
-		releaseName, namespace,
-		"```",
-	)
+helm history %s -n %s`,
+			namespace, releaseName,
+			releaseName, namespace,
+			releaseName, namespace,
+		)
+	}
+	return "## Useful Commands\n\n```bash\n" + commands + "\n```\n\n"
 }
 
-func formatWorkloadTable(workloads []nova.WorkloadOutput) string {
+// formatWorkloadTable renders the affected workloads as a markdown table of at most maxRows
+// rows (0 = all), followed by a note on how many were left out.
+func formatWorkloadTable(workloads []nova.WorkloadOutput, maxRows int) string {
 	if len(workloads) == 0 {
 		return "_No workload information available_"
 	}
 
+	shown := workloads
+	if maxRows > 0 && len(workloads) > maxRows {
+		shown = workloads[:maxRows]
+	}
+
 	var sb strings.Builder
 	sb.WriteString("| Workload | Namespace | Kind | Container |\n")
 	sb.WriteString("|----------|-----------|------|----------|\n")
 
-	for _, w := range workloads {
+	for _, w := range shown {
 		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
 			w.Name, w.Namespace, w.Kind, w.Container))
 	}
+	if omitted := len(workloads) - len(shown); omitted > 0 {
+		sb.WriteString(fmt.Sprintf("\n_...and %d more_\n", omitted))
+	}
+
+	return sb.String()
+}
+
+// truncateIssueBody cuts a body that exceeds maxIssueBodyLength at the last full line that
+// fits and appends a note, so the issue API doesn't reject it. The limit leaves room for the
+// fingerprint marker appended afterwards.
+func truncateIssueBody(body string) string {
+	if len(body) <= maxIssueBodyLength {
+		return body
+	}
+
+	cut := maxIssueBodyLength - len(truncatedBodyNote)
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	if i := strings.LastIndexByte(body[:cut], '\n'); i >= 0 {
+		cut = i + 1
+	}
+	return body[:cut] + truncatedBodyNote
+}
+
+// FormatSkippedContainerTable renders the containers skipped by Helm deduplication with the
+// outdated-Helm namespaces that caused each skip. Returns an empty string if nothing was skipped.
+func FormatSkippedContainerTable(skipped []nova.ContainerOutput, skipNamespaces map[string]bool) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Image | Current Tag | Latest Tag | Outdated Helm Namespaces |\n")
+	sb.WriteString("|-------|-------------|------------|--------------------------|\n")
+
+	for _, container := range skipped {
+		var namespaces []string
+		for _, namespace := range workloadNamespaces(container.AffectedWorkloads) {
+			if skipNamespaces[namespace] {
+				namespaces = append(namespaces, backtick(namespace))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			backtick(container.Name),
+			backtick(container.CurrentTag),
+			backtick(container.LatestTag),
+			strings.Join(namespaces, ", "),
+		))
+	}
 
 	return sb.String()
 }
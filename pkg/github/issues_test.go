@@ -1,10 +1,30 @@
 package github
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/google/go-github/v57/github"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestBacktick(t *testing.T) {
@@ -27,27 +47,6 @@ func TestBacktick(t *testing.T) {
 	}
 }
 
-func TestEscapeSearchQuery(t *testing.T) {
-	tests := []struct {
-		input string
-		want  string
-	}{
-		{"normal text", "normal text"},
-		{`with "quotes"`, "with quotes"},
-		{`with \backslash`, "with backslash"},
-		{`"both" and \slash`, "both and slash"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := escapeSearchQuery(tt.input)
-			if got != tt.want {
-				t.Errorf("escapeSearchQuery(%q) = %q, want %q", tt.input, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestFormatYAMLSnippet(t *testing.T) {
 	result := formatYAMLSnippet("2.0.0", "1.0.0")
 
@@ -63,29 +62,89 @@ func TestFormatYAMLSnippet(t *testing.T) {
 }
 
 func TestFormatHelmCommands(t *testing.T) {
-	result := formatHelmCommands("my-release", "my-namespace")
+	tests := []struct {
+		tool string
+		want []string
+	}{
+		{"flux", []string{
+			"## Useful Commands",
+			"```bash",
+			"flux get helmreleases -n my-namespace",
+			"flux reconcile helmrelease my-release -n my-namespace",
+			"helm history my-release -n my-namespace",
+		}},
+		{"argocd", []string{
+			"## Useful Commands",
+			"```bash",
+			"argocd app get my-release",
+			"argocd app sync my-release",
+			"argocd app history my-release",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			result := formatHelmCommands("my-release", "my-namespace", tt.tool)
+			for _, exp := range tt.want {
+				if !strings.Contains(result, exp) {
+					t.Errorf("expected %q in commands, got %q", exp, result)
+				}
+			}
+		})
+	}
 
-	if !strings.Contains(result, "```bash") {
-		t.Error("expected bash code block")
+	if result := formatHelmCommands("my-release", "my-namespace", "none"); result != "" {
+		t.Errorf("expected no commands for none, got %q", result)
 	}
-	if !strings.Contains(result, "flux get helmreleases -n my-namespace") {
-		t.Error("expected flux get command")
+}
+
+func TestFormatHelmIssueBody_GitOpsTool(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
 	}
-	if !strings.Contains(result, "flux reconcile helmrelease my-release -n my-namespace") {
-		t.Error("expected flux reconcile command")
+
+	tests := []struct {
+		tool    string
+		want    []string
+		notWant []string
+	}{
+		{"flux", []string{"## Flux Update (GitOps)", "flux reconcile helmrelease"}, []string{"argocd"}},
+		{"argocd", []string{"## Argo CD Update (GitOps)", `targetRevision: "2.0.0"  # was: 1.0.0`, "argocd app sync my-release"},
+			[]string{"Flux", "flux"}},
+		{"none", []string{"## Update Checklist", "- [ ] Roll out the upgrade"},
+			[]string{"Flux", "flux", "argocd", "## Useful Commands", "(GitOps)"}},
 	}
-	if !strings.Contains(result, "helm history my-release -n my-namespace") {
-		t.Error("expected helm history command")
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			body, err := RenderHelmIssueBody(&config.Config{GitOpsTool: tt.tool}, release)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, exp := range tt.want {
+				if !strings.Contains(body, exp) {
+					t.Errorf("body should contain %q", exp)
+				}
+			}
+			for _, unexp := range tt.notWant {
+				if strings.Contains(body, unexp) {
+					t.Errorf("body should not contain %q", unexp)
+				}
+			}
+		})
 	}
 }
 
 func TestFormatWorkloadTable_Empty(t *testing.T) {
-	result := formatWorkloadTable(nil)
+	result := formatWorkloadTable(nil, 0)
 	if result != "_No workload information available_" {
 		t.Errorf("expected placeholder text, got %q", result)
 	}
 
-	result = formatWorkloadTable([]nova.WorkloadOutput{})
+	result = formatWorkloadTable([]nova.WorkloadOutput{}, 0)
 	if result != "_No workload information available_" {
 		t.Errorf("expected placeholder text, got %q", result)
 	}
@@ -97,7 +156,7 @@ func TestFormatWorkloadTable_WithWorkloads(t *testing.T) {
 		{Name: "api", Namespace: "backend", Kind: "StatefulSet", Container: "app"},
 	}
 
-	result := formatWorkloadTable(workloads)
+	result := formatWorkloadTable(workloads, 0)
 
 	// Check header
 	if !strings.Contains(result, "| Workload | Namespace | Kind | Container |") {
@@ -118,6 +177,72 @@ func TestFormatWorkloadTable_WithWorkloads(t *testing.T) {
 	}
 }
 
+func TestFormatWorkloadTable_MaxRows(t *testing.T) {
+	var workloads []nova.WorkloadOutput
+	for i := range 5 {
+		workloads = append(workloads, nova.WorkloadOutput{Name: fmt.Sprintf("web-%d", i), Namespace: "default", Kind: "Deployment", Container: "nginx"})
+	}
+
+	result := formatWorkloadTable(workloads, 3)
+	if !strings.Contains(result, "| web-2 |") || strings.Contains(result, "| web-3 |") {
+		t.Errorf("expected the first 3 workloads only, got %q", result)
+	}
+	if !strings.Contains(result, "_...and 2 more_") {
+		t.Errorf("expected an overflow note, got %q", result)
+	}
+
+	for _, maxRows := range []int{0, 5} {
+		if result := formatWorkloadTable(workloads, maxRows); strings.Contains(result, "more_") || !strings.Contains(result, "| web-4 |") {
+			t.Errorf("maxRows %d: expected all workloads without an overflow note, got %q", maxRows, result)
+		}
+	}
+}
+
+func TestTruncateIssueBody(t *testing.T) {
+	if body := "short body\n"; truncateIssueBody(body) != body {
+		t.Errorf("expected a short body to be unchanged")
+	}
+
+	long := strings.Repeat("| row | ü |\n", maxIssueBodyLength/10)
+	body := truncateIssueBody(long)
+	if len(body) > maxIssueBodyLength {
+		t.Errorf("expected at most %d bytes, got %d", maxIssueBodyLength, len(body))
+	}
+	if !strings.HasSuffix(body, truncatedBodyNote) {
+		t.Errorf("expected the truncation note at the end")
+	}
+	if !strings.HasSuffix(strings.TrimSuffix(body, truncatedBodyNote), "| row | ü |\n") || !utf8.ValidString(body) {
+		t.Errorf("expected the body to be cut at a full line")
+	}
+}
+
+func TestRenderContainerIssueBody_TruncatesLongBody(t *testing.T) {
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"}
+	for i := range 2000 {
+		container.AffectedWorkloads = append(container.AffectedWorkloads, nova.WorkloadOutput{
+			Name: fmt.Sprintf("workload-with-a-rather-long-name-%d", i), Namespace: "default", Kind: "Deployment", Container: "nginx"})
+	}
+
+	body, err := RenderContainerIssueBody(&config.Config{MaxWorkloadRows: 50}, container)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(body, "_...and 1950 more_") || strings.Contains(body, truncatedBodyNote) {
+		t.Errorf("expected 50 workload rows with an overflow note, got %d bytes", len(body))
+	}
+
+	content, err := ContainerIssueContent(&config.Config{}, container)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(content.Body) > 65536 || !strings.Contains(content.Body, truncatedBodyNote) {
+		t.Errorf("expected the unlimited body to be truncated below 65536 bytes, got %d", len(content.Body))
+	}
+	if !HasFingerprint(content.Body, content.Fingerprint) {
+		t.Errorf("expected the fingerprint marker to survive truncation")
+	}
+}
+
 func TestFormatHelmIssueBody(t *testing.T) {
 	release := nova.ReleaseOutput{
 		ReleaseName: "my-release",
@@ -183,6 +308,98 @@ func TestFormatHelmIssueBody_Deprecated(t *testing.T) {
 	}
 }
 
+func TestFormatHelmIssueBody_Overridden(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "pinned-release",
+		ChartName:   "my-chart",
+		Namespace:   "default",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+		Overridden:  true,
+	}
+
+	if body := FormatHelmIssueBody(release); !strings.Contains(body, "> **Overridden:**") {
+		t.Errorf("expected an override note, got %q", body)
+	}
+
+	release.Overridden = false
+	if body := FormatHelmIssueBody(release); strings.Contains(body, "Overridden") {
+		t.Errorf("expected no override note, got %q", body)
+	}
+}
+
+func TestFormatHelmIssueBody_HelmVersion(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		ChartName:   "my-chart",
+		Namespace:   "default",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+		HelmVersion: "3",
+	}
+
+	if body := FormatHelmIssueBody(release); !strings.Contains(body, "| Helm Version | `3` |") {
+		t.Errorf("expected a helm version row, got %q", body)
+	}
+
+	release.HelmVersion = ""
+	if body := FormatHelmIssueBody(release); strings.Contains(body, "Helm Version") {
+		t.Errorf("expected no helm version row, got %q", body)
+	}
+}
+
+func TestFormatHelmIssueBody_RepositoryURL(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName:   "cert-manager",
+		ChartName:     "cert-manager",
+		Namespace:     "infra",
+		RepositoryURL: "https://charts.jetstack.io",
+	}
+
+	body := FormatHelmIssueBody(release)
+	if !strings.Contains(body, "| Chart Repository | [https://charts.jetstack.io](https://charts.jetstack.io) |") {
+		t.Errorf("expected chart repository link, got %q", body)
+	}
+
+	release.RepositoryURL = ""
+	if body := FormatHelmIssueBody(release); strings.Contains(body, "Chart Repository") {
+		t.Error("expected no chart repository row without a repository URL")
+	}
+}
+
+func TestFormatHelmIssueBody_ChartMetadata(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "cert-manager",
+		ChartName:   "cert-manager",
+		Namespace:   "infra",
+		Description: "A Helm chart for cert-manager",
+		Home:        "https://cert-manager.io",
+		Icon:        "https://cert-manager.io/icon.png",
+	}
+
+	body := FormatHelmIssueBody(release)
+	if !strings.Contains(body, "## Outdated Helm Chart Detected\n\n<img src=\"https://cert-manager.io/icon.png\" alt=\"\" height=\"20\"> A Helm chart for cert-manager\n\n| Field |") {
+		t.Errorf("expected chart description with icon below the heading, got %q", body)
+	}
+	if !strings.Contains(body, "| Chart Home | [https://cert-manager.io](https://cert-manager.io) |") {
+		t.Errorf("expected chart home link, got %q", body)
+	}
+
+	release.Icon = ""
+	if body := FormatHelmIssueBody(release); !strings.Contains(body, "\n\nA Helm chart for cert-manager\n\n") || strings.Contains(body, "<img") {
+		t.Errorf("expected description without icon, got %q", body)
+	}
+
+	release.Description, release.Home, release.Icon = "", "", "https://cert-manager.io/icon.png"
+	body = FormatHelmIssueBody(release)
+	if !strings.Contains(body, "## Outdated Helm Chart Detected\n\n| Field |") || strings.Contains(body, "<img") {
+		t.Errorf("expected no description paragraph without a description, got %q", body)
+	}
+	if strings.Contains(body, "Chart Home") {
+		t.Error("expected no chart home row without a home page")
+	}
+}
+
 func TestFormatContainerIssueBody(t *testing.T) {
 	container := nova.ContainerOutput{
 		Name:       "nginx",
@@ -193,7 +410,7 @@ func TestFormatContainerIssueBody(t *testing.T) {
 		},
 	}
 
-	body := FormatContainerIssueBody(container)
+	body := FormatContainerIssueBody(container, 0)
 
 	// Check table content
 	if !strings.Contains(body, "| Image | `nginx` |") {
@@ -220,6 +437,76 @@ func TestFormatContainerIssueBody(t *testing.T) {
 	}
 }
 
+func TestFormatContainerIssue_DesiredTag(t *testing.T) {
+	container := nova.ContainerOutput{Name: "redis", CurrentTag: "7.0.0", LatestTag: "7.2.0", DesiredTag: "7.2.0"}
+
+	if title := FormatContainerIssueTitle("[Nova]", container); !strings.Contains(title, "(7.0.0 → 7.2.0)") {
+		t.Errorf("expected desired tag in title, got %q", title)
+	}
+	body := FormatContainerIssueBody(container, 0)
+	if !strings.Contains(body, "| Desired Tag | `7.2.0` |") {
+		t.Error("expected desired tag row in table")
+	}
+	if strings.Contains(body, "| Latest Tag |") {
+		t.Error("expected no latest tag row for a pinned image")
+	}
+}
+
+func TestFormatSkippedContainerTable(t *testing.T) {
+	if table := FormatSkippedContainerTable(nil, map[string]bool{"apps": true}); table != "" {
+		t.Errorf("expected empty table for no skipped containers, got %q", table)
+	}
+
+	skipped := []nova.ContainerOutput{
+		{Name: "redis", CurrentTag: "7.0.0", LatestTag: "7.2.0", AffectedWorkloads: []nova.WorkloadOutput{
+			{Name: "cache", Namespace: "apps"},
+			{Name: "queue", Namespace: "jobs"},
+			{Name: "cache-replica", Namespace: "apps"},
+		}},
+	}
+	table := FormatSkippedContainerTable(skipped, map[string]bool{"apps": true, "jobs": true, "other": true})
+
+	expected := []string{
+		"| Image | Current Tag | Latest Tag | Outdated Helm Namespaces |",
+		"| `redis` | `7.0.0` | `7.2.0` | `apps`, `jobs` |",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(table, exp) {
+			t.Errorf("table should contain %q, got:\n%s", exp, table)
+		}
+	}
+}
+
+func TestFormatContainerIssueBody_DigestPinned(t *testing.T) {
+	container := nova.ContainerOutput{
+		Name:         "nginx",
+		CurrentTag:   "sha256:0f0f",
+		LatestTag:    "1.27.0",
+		DigestPinned: true,
+	}
+
+	body := FormatContainerIssueBody(container, 0)
+
+	if !strings.Contains(body, "## Outdated Digest-Pinned Container Image Detected") {
+		t.Error("expected digest-pinned heading")
+	}
+	if !strings.Contains(body, "**Digest-pinned:**") {
+		t.Error("expected digest-pinned note")
+	}
+	if !strings.Contains(body, "- [ ] Resolve the digest of the `1.27.0` tag") {
+		t.Error("expected digest update step")
+	}
+	if strings.Contains(body, "Update image tag") {
+		t.Error("expected no tag update step for digest-pinned images")
+	}
+
+	// Tag-based images keep the regular wording
+	container.DigestPinned = false
+	if body := FormatContainerIssueBody(container, 0); strings.Contains(body, "Digest-pinned") || !strings.Contains(body, "Update image tag") {
+		t.Errorf("expected regular container body, got:\n%s", body)
+	}
+}
+
 func TestFormatContainerIssueBody_NoWorkloads(t *testing.T) {
 	container := nova.ContainerOutput{
 		Name:              "redis",
@@ -228,7 +515,7 @@ func TestFormatContainerIssueBody_NoWorkloads(t *testing.T) {
 		AffectedWorkloads: nil,
 	}
 
-	body := FormatContainerIssueBody(container)
+	body := FormatContainerIssueBody(container, 0)
 
 	if !strings.Contains(body, "_No workload information available_") {
 		t.Error("expected no workload placeholder")
@@ -239,8 +526,151 @@ func TestLabels(t *testing.T) {
 	if labelNovaScan != "nova-scan" {
 		t.Errorf("expected labelNovaScan to be 'nova-scan', got %q", labelNovaScan)
 	}
-	if labelClaudeCode != "claude-code" {
-		t.Errorf("expected labelClaudeCode to be 'claude-code', got %q", labelClaudeCode)
+}
+
+func TestIssueLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured []string
+		typeLabel  string
+		want       []string
+	}{
+		{"default labels", []string{"nova-scan"}, labelHelmUpdate, []string{"nova-scan", "helm-update"}},
+		{"custom labels", []string{"nova-scan", "claude-code"}, labelContainerUpdate, []string{"nova-scan", "claude-code", "container-update"}},
+		{"nova-scan always present", []string{"team-platform"}, labelHelmUpdate, []string{"nova-scan", "team-platform", "helm-update"}},
+		{"no configured labels", nil, labelHelmUpdate, []string{"nova-scan", "helm-update"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := issueLabels(&config.Config{IssueLabels: tt.configured}, tt.typeLabel)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("issueLabels(%q) = %v, want %v", tt.typeLabel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateHelmIssue_SecurityLabel(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName:   "my-release",
+		Installed:     nova.VersionInfo{Version: "1.0.0"},
+		Latest:        nova.VersionInfo{Version: "1.0.1"},
+		SecurityAlert: true,
+	}
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{IssueLabels: []string{"nova-scan"}}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(fake.createdLabels, ","); got != "nova-scan,helm-update,security" {
+		t.Errorf("unexpected labels on created issue: %s", got)
+	}
+	if !strings.Contains(FormatHelmIssueBody(release), "**Security:**") {
+		t.Error("expected security note in issue body")
+	}
+}
+
+func TestCreateHelmIssue_CustomLabels(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{IssueLabels: []string{"team-platform"}}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(fake.createdLabels, ","); got != "nova-scan,team-platform,helm-update" {
+		t.Errorf("unexpected labels on created issue: %s", got)
+	}
+	for _, query := range fake.listQueries {
+		if !strings.Contains(query, "labels=nova-scan") || !strings.Contains(query, "state=open") {
+			t.Errorf("expected dedup listing of open nova-scan issues, got %q", query)
+		}
+	}
+}
+
+func TestCreateIssue_SeverityLabels(t *testing.T) {
+	cfg := func() *config.Config {
+		return &config.Config{SeverityLabelPrefix: "nova-severity:", DeprecatedLabel: "nova-deprecated"}
+	}
+
+	tests := []struct {
+		name   string
+		cfg    *config.Config
+		create func(im *IssueManager) error
+		want   string
+	}{
+		{"major helm upgrade", cfg(), func(im *IssueManager) error {
+			_, err := im.CreateHelmIssue(context.Background(), nova.ReleaseOutput{ReleaseName: "a",
+				Installed: nova.VersionInfo{Version: "1.4.2"}, Latest: nova.VersionInfo{Version: "2.0.0"}})
+			return err
+		}, "nova-scan,helm-update,nova-severity:major"},
+		{"minor helm upgrade of a deprecated chart", cfg(), func(im *IssueManager) error {
+			_, err := im.CreateHelmIssue(context.Background(), nova.ReleaseOutput{ReleaseName: "a", Deprecated: true,
+				Installed: nova.VersionInfo{Version: "1.4.2"}, Latest: nova.VersionInfo{Version: "1.5.0"}})
+			return err
+		}, "nova-scan,helm-update,nova-severity:minor,nova-deprecated"},
+		{"patch container upgrade", cfg(), func(im *IssueManager) error {
+			_, err := im.CreateContainerIssue(context.Background(), nova.ContainerOutput{Name: "nginx",
+				CurrentTag: "1.25.1", LatestTag: "1.25.3"})
+			return err
+		}, "nova-scan,container-update,nova-severity:patch"},
+		{"non-semver tags get the unknown severity", cfg(), func(im *IssueManager) error {
+			_, err := im.CreateContainerIssue(context.Background(), nova.ContainerOutput{Name: "nginx",
+				CurrentTag: "stable", LatestTag: "mainline"})
+			return err
+		}, "nova-scan,container-update,nova-severity:unknown"},
+		{"namespace issue gets the highest severity", cfg(), func(im *IssueManager) error {
+			group := NamespaceGroup{Namespace: "apps",
+				Releases: []nova.ReleaseOutput{{ReleaseName: "a",
+					Installed: nova.VersionInfo{Version: "1.0.0"}, Latest: nova.VersionInfo{Version: "1.0.1"}}},
+				Containers: []nova.ContainerOutput{{Name: "redis", CurrentTag: "6.2.0", LatestTag: "7.0.0"}},
+			}
+			_, err := im.CreateNamespaceIssue(context.Background(), group)
+			return err
+		}, "nova-scan,helm-update,container-update,nova-severity:major"},
+		{"namespace issue of non-semver components gets the unknown severity", cfg(), func(im *IssueManager) error {
+			group := NamespaceGroup{Namespace: "apps",
+				Containers: []nova.ContainerOutput{{Name: "nginx", CurrentTag: "stable", LatestTag: "mainline"}},
+			}
+			_, err := im.CreateNamespaceIssue(context.Background(), group)
+			return err
+		}, "nova-scan,container-update,nova-severity:unknown"},
+		{"namespace issue prefers a known severity over unknown", cfg(), func(im *IssueManager) error {
+			group := NamespaceGroup{Namespace: "apps",
+				Containers: []nova.ContainerOutput{
+					{Name: "nginx", CurrentTag: "stable", LatestTag: "mainline"},
+					{Name: "redis", CurrentTag: "7.0.0", LatestTag: "7.1.0"},
+				},
+			}
+			_, err := im.CreateNamespaceIssue(context.Background(), group)
+			return err
+		}, "nova-scan,container-update,nova-severity:minor"},
+		{"custom prefix", &config.Config{SeverityLabelPrefix: "severity/"}, func(im *IssueManager) error {
+			_, err := im.CreateHelmIssue(context.Background(), nova.ReleaseOutput{ReleaseName: "a", Deprecated: true,
+				Installed: nova.VersionInfo{Version: "1.4.2"}, Latest: nova.VersionInfo{Version: "1.5.0"}})
+			return err
+		}, "nova-scan,helm-update,severity/minor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitHub{}
+			im := newTestIssueManager(t, tt.cfg, fake)
+
+			if err := tt.create(im); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Join(fake.createdLabels, ","); got != tt.want {
+				t.Errorf("expected labels %s, got %s", tt.want, got)
+			}
+		})
 	}
 }
 
@@ -251,7 +681,7 @@ func TestFormatHelmIssueTitle(t *testing.T) {
 		Latest:      nova.VersionInfo{Version: "2.0.0"},
 	}
 
-	title := FormatHelmIssueTitle(release)
+	title := FormatHelmIssueTitle("[Nova]", release)
 
 	expected := "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)"
 	if title != expected {
@@ -266,10 +696,1394 @@ func TestFormatContainerIssueTitle(t *testing.T) {
 		LatestTag:  "1.25",
 	}
 
-	title := FormatContainerIssueTitle(container)
+	title := FormatContainerIssueTitle("[Nova]", container)
 
 	expected := "[Nova] Update container image: nginx (1.20 → 1.25)"
 	if title != expected {
 		t.Errorf("expected title %q, got %q", expected, title)
 	}
 }
+
+func TestFormatIssueBody_FirstSeen(t *testing.T) {
+	firstSeen := time.Date(2024, 5, 20, 23, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	release := nova.ReleaseOutput{ReleaseName: "app", FirstSeen: firstSeen}
+	container := nova.ContainerOutput{Name: "nginx", FirstSeen: firstSeen}
+
+	// Dates are rendered in UTC
+	if body := FormatHelmIssueBody(release); !strings.Contains(body, "| First Detected | 2024-05-20 |") {
+		t.Errorf("expected first detected row in helm body, got %q", body)
+	}
+	if body := FormatContainerIssueBody(container, 0); !strings.Contains(body, "| First Detected | 2024-05-20 |") {
+		t.Errorf("expected first detected row in container body, got %q", body)
+	}
+	if strings.Contains(FormatHelmIssueBody(nova.ReleaseOutput{}), "First Detected") {
+		t.Error("expected no first detected row without a state file")
+	}
+}
+
+func TestFormatIssueTitles_CustomPrefix(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"}
+	group := GroupByNamespace([]nova.ReleaseOutput{release}, nil)[0]
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"helm", FormatHelmIssueTitle("[Nova prod]", release), "[Nova prod] Update Helm chart: my-release (1.0.0 → 2.0.0)"},
+		{"container", FormatContainerIssueTitle("[Nova prod]", container), "[Nova prod] Update container image: nginx (1.20 → 1.25)"},
+		{"namespace", FormatNamespaceIssueTitle("[Nova prod]", group), "[Nova prod] Update 1 outdated component in namespace: default"},
+		{"summary", FormatSummaryIssueTitle("[Nova prod]", time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)), "[Nova prod] Scan summary 2024-06-01"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s title = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCreateHelmIssue_TitlePrefixScopesDedup(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	cfg := &config.Config{IssueTitlePrefix: "[Nova prod]"}
+	stagingBody := "body" + formatFingerprintMarker(helmFingerprint(&config.Config{IssueTitlePrefix: "[Nova staging]"}, release))
+	prodBody := "body" + formatFingerprintMarker(helmFingerprint(cfg, release))
+
+	// Another deployment's issue for the same release is not a duplicate
+	fake := &fakeGitHub{existingTitle: "[Nova staging] Update Helm chart: my-release (1.0.0 → 2.0.0)", existingBody: stagingBody}
+	im := newTestIssueManager(t, cfg, fake)
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.created != 1 || fake.edited != 0 {
+		t.Errorf("expected an issue to be created next to the other prefix's, got created=%d edited=%d", fake.created, fake.edited)
+	}
+	if !strings.HasPrefix(fake.lastCreatedTitle, "[Nova prod] ") {
+		t.Errorf("expected the created title to carry the prefix, got %q", fake.lastCreatedTitle)
+	}
+
+	// The deployment's own issue is, also after someone edited its title
+	for _, title := range []string{"[Nova prod] Update Helm chart: my-release (1.0.0 → 2.0.0)", "Upgrade my-release before Q3"} {
+		fake = &fakeGitHub{existingTitle: title, existingBody: prodBody}
+		im = newTestIssueManager(t, cfg, fake)
+		if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fake.created != 0 {
+			t.Errorf("title %q: expected the existing issue to be found, got %d created", title, fake.created)
+		}
+	}
+}
+
+func TestFingerprintScope(t *testing.T) {
+	release := nova.ReleaseOutput{ReleaseName: "app", Namespace: "default", ChartName: "chart"}
+	if got := helmFingerprint(&config.Config{}, release); got != "helm:default/app:chart" {
+		t.Errorf("expected the default prefix to keep the fingerprint, got %q", got)
+	}
+	if got := helmFingerprint(&config.Config{IssueTitlePrefix: "[Nova prod]"}, release); got != "[Nova prod] helm:default/app:chart" {
+		t.Errorf("expected a custom prefix to scope the fingerprint, got %q", got)
+	}
+}
+
+func TestHelmFingerprint_ChartAliases(t *testing.T) {
+	cfg := &config.Config{
+		ChartAliases: map[string]string{"nginx-ingress": "ingress-nginx"},
+	}
+
+	before := nova.ReleaseOutput{
+		ReleaseName: "ingress",
+		Namespace:   "ingress-system",
+		ChartName:   "nginx-ingress",
+	}
+	after := before
+	after.ChartName = "ingress-nginx"
+
+	beforeMarker := formatFingerprintMarker(helmFingerprint(cfg, before))
+	afterMarker := formatFingerprintMarker(helmFingerprint(cfg, after))
+	if beforeMarker != afterMarker {
+		t.Errorf("expected aliased charts to share a marker, got %q and %q", beforeMarker, afterMarker)
+	}
+	if !strings.Contains(afterMarker, "helm:ingress-system/ingress:ingress-nginx") {
+		t.Errorf("expected marker to use canonical chart name, got %q", afterMarker)
+	}
+
+	other := before
+	other.ChartName = "traefik"
+	if helmFingerprint(cfg, other) == helmFingerprint(cfg, before) {
+		t.Error("expected unrelated chart to have a different fingerprint")
+	}
+}
+
+func TestContainerFingerprint(t *testing.T) {
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"}
+
+	marker := formatFingerprintMarker(containerFingerprint(&config.Config{}, container))
+
+	if !strings.Contains(marker, "<!-- nova-scanner:fingerprint=container:nginx -->") {
+		t.Errorf("unexpected marker %q", marker)
+	}
+}
+
+// fakeGitHub is a minimal stand-in for the GitHub issues and search APIs.
+type fakeGitHub struct {
+	// mu serializes requests, so concurrent tests can read the counters below.
+	mu sync.Mutex
+
+	existingTitle string // empty = no existing issue
+	existingBody  string
+	listQueries   []string
+	// pages splits the open issue listing into this many pages; the existing issue is on the last one.
+	pages int
+	// rateLimited is the number of list requests answered with a rate limit error before succeeding.
+	rateLimited   int
+	created       int
+	createdLabels []string
+	// lastCreatedTitle and lastCreatedBody are the title and body of the most recently created issue.
+	lastCreatedTitle string
+	lastCreatedBody  string
+	// createdAssignees records the assignees of each create request, including rejected ones.
+	createdAssignees [][]string
+	rejectAssignees  bool
+	edited           int
+	commented        int
+	lastComment      string
+	// existingLabels are the labels of the existing open issue besides nova-scan.
+	existingLabels []string
+	// closedTitle is the title of a closed issue (#9) returned when listing closed issues; empty = none.
+	closedTitle  string
+	closedBody   string
+	closedLabels []string
+	// reopenRequest is the edit request sent for the closed issue.
+	reopenRequest *github.IssueRequest
+	// editRequest is the most recent edit request sent for the existing open issue.
+	editRequest *github.IssueRequest
+}
+
+func (f *fakeGitHub) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			f.listQueries = append(f.listQueries, r.URL.RawQuery)
+			if state := r.URL.Query().Get("state"); state == "closed" || state == "all" {
+				// Listing all issues filters by the suppress label, listing closed ones doesn't
+				wanted := strings.TrimPrefix(r.URL.Query().Get("labels"), labelNovaScan+",")
+				issues := []*github.Issue{}
+				if f.closedTitle != "" && (state == "closed" || slices.Contains(f.closedLabels, wanted)) {
+					issues = append(issues, &github.Issue{Number: github.Int(9), State: github.String("closed"),
+						Title: github.String(f.closedTitle), Body: github.String(f.closedBody), Labels: closedIssueLabels(f.closedLabels)})
+				}
+				if f.existingTitle != "" && state == "all" && slices.Contains(f.existingLabels, wanted) {
+					issues = append(issues, &github.Issue{Number: github.Int(7), State: github.String("open"),
+						Title: github.String(f.existingTitle), Body: github.String(f.existingBody), Labels: closedIssueLabels(f.existingLabels)})
+				}
+				json.NewEncoder(w).Encode(issues)
+				return
+			}
+			if f.rateLimited > 0 {
+				f.rateLimited--
+				w.Header().Set("X-RateLimit-Limit", "5000")
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+				return
+			}
+			if page, _ := strconv.Atoi(r.URL.Query().Get("page")); page < f.pages && f.pages > 1 {
+				// Earlier pages hold unrelated issues and a pull request, which the issues API also returns
+				next := *r.URL
+				q := next.Query()
+				q.Set("page", strconv.Itoa(max(page, 1)+1))
+				next.RawQuery = q.Encode()
+				w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.String()))
+				fmt.Fprintf(w, `[{"number":%d,"title":"other","body":""},{"number":%d,"title":%q,"pull_request":{}}]`,
+					100+page, 200+page, f.existingTitle)
+				return
+			}
+			if f.existingTitle == "" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			json.NewEncoder(w).Encode([]*github.Issue{{Number: github.Int(7), Title: github.String(f.existingTitle),
+				Body: github.String(f.existingBody), Labels: closedIssueLabels(f.existingLabels)}})
+			return
+		}
+
+		var req github.IssueRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.createdAssignees = append(f.createdAssignees, req.GetAssignees())
+		if f.rejectAssignees && len(req.GetAssignees()) > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Validation Failed","errors":[{"resource":"Issue","field":"assignees","code":"invalid"}]}`)
+			return
+		}
+		f.created++
+		f.createdLabels = req.GetLabels()
+		f.lastCreatedTitle = req.GetTitle()
+		f.lastCreatedBody = req.GetBody()
+		// The first created issue is #8; later ones get distinct numbers
+		fmt.Fprintf(w, `{"number":%d,"title":%q,"body":%q,"html_url":"https://github.com/owner/repo/issues/8"}`,
+			7+f.created, req.GetTitle(), req.GetBody())
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/7", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("expected PATCH for issue edit, got %s", r.Method)
+		}
+		f.edited++
+		f.editRequest = &github.IssueRequest{}
+		json.NewDecoder(r.Body).Decode(f.editRequest)
+		fmt.Fprint(w, `{"number":7,"html_url":"https://github.com/owner/repo/issues/7"}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/9", func(w http.ResponseWriter, r *http.Request) {
+		f.reopenRequest = &github.IssueRequest{}
+		json.NewDecoder(r.Body).Decode(f.reopenRequest)
+		fmt.Fprint(w, `{"number":9,"state":"open","html_url":"https://github.com/owner/repo/issues/9"}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/9/comments", func(w http.ResponseWriter, r *http.Request) {
+		var comment github.IssueComment
+		json.NewDecoder(r.Body).Decode(&comment)
+		f.commented++
+		f.lastComment = comment.GetBody()
+		fmt.Fprint(w, `{"id":2}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		var comment github.IssueComment
+		json.NewDecoder(r.Body).Decode(&comment)
+		f.commented++
+		f.lastComment = comment.GetBody()
+		fmt.Fprint(w, `{"id":1}`)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// closedIssueLabels converts label names to the issues API representation.
+func closedIssueLabels(names []string) []*github.Label {
+	labels := []*github.Label{}
+	for _, name := range names {
+		labels = append(labels, &github.Label{Name: github.String(name)})
+	}
+	return labels
+}
+
+func TestNewIssueManager_HTTPProxy(t *testing.T) {
+	var proxied []string
+	var auth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Scheme+"://"+r.URL.Host+r.URL.Path)
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, "[]")
+	}))
+	defer proxy.Close()
+
+	cfg := &config.Config{GitHubToken: "token", GitHubOwner: "owner", GitHubRepo: "repo", DryRun: true, HTTPProxy: proxy.URL}
+	im := NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+	im.client.BaseURL, _ = url.Parse("http://github.example.test/")
+
+	release := nova.ReleaseOutput{ReleaseName: "my-release", Namespace: "default", ChartName: "my-chart",
+		Installed: nova.VersionInfo{Version: "1.0.0"}, Latest: nova.VersionInfo{Version: "2.0.0"}}
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("CreateHelmIssue() error = %v", err)
+	}
+	if len(proxied) == 0 || proxied[0] != "http://github.example.test/repos/owner/repo/issues" {
+		t.Errorf("expected GitHub requests to go through the proxy, got %v", proxied)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("expected the token on proxied requests, got %q", auth)
+	}
+}
+
+// newTestIssueManager returns an issue manager talking to the fake, served as a GitHub
+// Enterprise Server API under /api/v3.
+func newTestIssueManager(t *testing.T, cfg *config.Config, fake *fakeGitHub) *IssueManager {
+	server := httptest.NewServer(http.StripPrefix("/api/v3", fake.handler(t)))
+	t.Cleanup(server.Close)
+
+	if cfg.GitHubOwner == "" {
+		cfg.GitHubOwner = "owner"
+	}
+	if cfg.GitHubRepo == "" {
+		cfg.GitHubRepo = "repo"
+	}
+	cfg.GitHubBaseURL = server.URL
+	return NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+}
+
+func TestCreateHelmIssue_TraceIDMarker(t *testing.T) {
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+	release := nova.ReleaseOutput{ReleaseName: "ingress", ChartName: "ingress-nginx", Namespace: "edge",
+		Installed: nova.VersionInfo{Version: "4.0.0"}, Latest: nova.VersionInfo{Version: "4.1.0"}}
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	traceID := im.logger.TraceID()
+	if traceID == "" {
+		t.Fatal("expected the logger to have a trace ID")
+	}
+	if want := "<!-- nova-scanner:trace-id=" + traceID + " -->"; !strings.HasSuffix(strings.TrimSpace(fake.lastCreatedBody), want) {
+		t.Errorf("expected the body to end with %q, got %q", want, fake.lastCreatedBody)
+	}
+}
+
+func TestIssueManager_UserAgent(t *testing.T) {
+	fake := &fakeGitHub{}
+	var userAgents []string
+	handler := http.StripPrefix("/api/v3", fake.handler(t))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{GitHubOwner: "owner", GitHubRepo: "repo", GitHubBaseURL: server.URL, UserAgent: "nova-scanner/1.2.3"}
+	im := NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+	release := nova.ReleaseOutput{ReleaseName: "ingress", ChartName: "ingress-nginx", Namespace: "edge",
+		Installed: nova.VersionInfo{Version: "4.0.0"}, Latest: nova.VersionInfo{Version: "4.1.0"}}
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(userAgents) == 0 {
+		t.Fatal("expected requests to the GitHub API")
+	}
+	for _, userAgent := range userAgents {
+		if userAgent != "nova-scanner/1.2.3" {
+			t.Errorf("expected the configured user agent on every request, got %q", userAgent)
+		}
+	}
+}
+
+func TestIssueManager_EndToEnd(t *testing.T) {
+	release := nova.ReleaseOutput{ReleaseName: "ingress", ChartName: "ingress-nginx", Namespace: "edge",
+		Installed: nova.VersionInfo{Version: "4.0.0"}, Latest: nova.VersionInfo{Version: "4.1.0"}}
+	cfg := func() *config.Config { return &config.Config{IssueLabels: []string{"team-edge"}} }
+
+	t.Run("creates a missing issue", func(t *testing.T) {
+		fake := &fakeGitHub{}
+		im := newTestIssueManager(t, cfg(), fake)
+
+		issueURL, err := im.CreateHelmIssue(context.Background(), release)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if issueURL != "https://github.com/owner/repo/issues/8" || fake.created != 1 {
+			t.Fatalf("expected one created issue, got %d (URL %q)", fake.created, issueURL)
+		}
+		if want := "[Nova] Update Helm chart: ingress (4.0.0 → 4.1.0)"; fake.lastCreatedTitle != want {
+			t.Errorf("title = %q, want %q", fake.lastCreatedTitle, want)
+		}
+		for _, want := range []string{"| Release Name | `ingress` |", "| Namespace | `edge` |",
+			"<!-- nova-scanner:fingerprint=helm:edge/ingress:ingress-nginx -->"} {
+			if !strings.Contains(fake.lastCreatedBody, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		for _, want := range []string{labelNovaScan, "team-edge", labelHelmUpdate} {
+			if !slices.Contains(fake.createdLabels, want) {
+				t.Errorf("expected label %q, got %v", want, fake.createdLabels)
+			}
+		}
+	})
+
+	t.Run("skips an existing issue", func(t *testing.T) {
+		body, err := RenderHelmIssueBody(cfg(), release)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fake := &fakeGitHub{
+			existingTitle: FormatHelmIssueTitle(cfg().TitlePrefix(), release),
+			existingBody:  body + formatFingerprintMarker(helmFingerprint(cfg(), release)),
+		}
+		im := newTestIssueManager(t, cfg(), fake)
+
+		issueURL, err := im.CreateHelmIssue(context.Background(), release)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if issueURL != "" || fake.created != 0 || fake.edited != 0 {
+			t.Errorf("expected the existing issue to be left alone, got %d created, %d edited", fake.created, fake.edited)
+		}
+		if im.SkippedIssues() != 1 {
+			t.Errorf("expected 1 skipped issue, got %d", im.SkippedIssues())
+		}
+	})
+}
+
+func TestCreateIssues_RepoRouting(t *testing.T) {
+	teamRelease := nova.ReleaseOutput{ReleaseName: "api", Namespace: "team-a", ChartName: "api",
+		Installed: nova.VersionInfo{Version: "1.0.0"}, Latest: nova.VersionInfo{Version: "2.0.0"}}
+	trackedRelease := nova.ReleaseOutput{ReleaseName: "worker", Namespace: "team-a", ChartName: "worker",
+		Installed: nova.VersionInfo{Version: "1.0.0"}, Latest: nova.VersionInfo{Version: "1.1.0"}}
+	otherRelease := nova.ReleaseOutput{ReleaseName: "web", Namespace: "default", ChartName: "web",
+		Installed: nova.VersionInfo{Version: "1.0.0"}, Latest: nova.VersionInfo{Version: "1.0.1"}}
+
+	// The central repo already tracks the team's api release; team-a's repo tracks its worker release
+	existing := map[string][]string{
+		"owner/repo":     {FormatHelmIssueTitle("[Nova]", teamRelease)},
+		"team-a-org/api": {FormatHelmIssueTitle("[Nova]", trackedRelease)},
+	}
+	created := map[string][]string{}
+	listed := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/"), "/")
+		repo := parts[0] + "/" + parts[1]
+		if r.Method == http.MethodGet {
+			listed[repo]++
+			issues := []*github.Issue{}
+			for i, title := range existing[repo] {
+				issues = append(issues, &github.Issue{Number: github.Int(i + 1), Title: github.String(title)})
+			}
+			json.NewEncoder(w).Encode(issues)
+			return
+		}
+		var req github.IssueRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		created[repo] = append(created[repo], req.GetTitle())
+		fmt.Fprintf(w, `{"number":100,"html_url":"https://github.com/%s/issues/100"}`, repo)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		GitHubOwner: "owner",
+		GitHubRepo:  "repo",
+		RepoRouting: map[string]config.RepoRoute{"team-a": {Owner: "team-a-org", Repo: "api"}},
+	}
+	im := NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+	im.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	ctx := context.Background()
+	for _, release := range []nova.ReleaseOutput{teamRelease, trackedRelease, otherRelease} {
+		if _, err := im.CreateHelmIssue(ctx, release); err != nil {
+			t.Fatalf("CreateHelmIssue(%s) error = %v", release.ReleaseName, err)
+		}
+	}
+
+	// Dedup is per repository: the central repo's api issue doesn't count for team-a's repo
+	want := map[string][]string{
+		"team-a-org/api": {FormatHelmIssueTitle("[Nova]", teamRelease)},
+		"owner/repo":     {FormatHelmIssueTitle("[Nova]", otherRelease)},
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("expected created issues %v, got %v", want, created)
+	}
+	if listed["owner/repo"] != 1 || listed["team-a-org/api"] != 1 {
+		t.Errorf("expected each repository's issues to be listed once, got %v", listed)
+	}
+	if im.SkippedIssues() != 1 {
+		t.Errorf("expected the tracked worker release to be skipped, got %d skipped", im.SkippedIssues())
+	}
+	if got := im.CreatedIssues(); len(got) != 2 {
+		t.Errorf("expected created issues from both repositories, got %v", got)
+	}
+}
+
+func TestForNamespaces(t *testing.T) {
+	cfg := &config.Config{
+		GitHubOwner: "owner",
+		GitHubRepo:  "repo",
+		RepoRouting: map[string]config.RepoRoute{
+			"team-a":   {Owner: "team-a-org", Repo: "api"},
+			"team-a-2": {Owner: "team-a-org", Repo: "api"},
+			"team-b":   {Repo: "team-b"},
+		},
+	}
+	im := NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       string
+	}{
+		{"fallback", []string{"default"}, "owner/repo"},
+		{"no namespaces", nil, "owner/repo"},
+		{"routed", []string{"team-a"}, "team-a-org/api"},
+		{"routed with default owner", []string{"team-b"}, "owner/team-b"},
+		{"namespaces routed to the same repo", []string{"team-a", "team-a-2"}, "team-a-org/api"},
+		{"namespaces routed to different repos", []string{"team-a", "team-b"}, "owner/repo"},
+		{"routed and unrouted namespaces", []string{"team-a", "default"}, "owner/repo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routed := im.forNamespaces(tt.namespaces...)
+			if got := routed.owner + "/" + routed.repo; got != tt.want {
+				t.Errorf("forNamespaces(%v) = %s, want %s", tt.namespaces, got, tt.want)
+			}
+		})
+	}
+	if im.forNamespaces("team-a") != im.forNamespaces("team-a-2") {
+		t.Error("expected namespaces routed to the same repo to share an issue manager")
+	}
+}
+
+func TestCreateHelmIssue_UpdatesWhenLatestVersionAdvances(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		ChartName:   "my-chart",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.1.0"},
+	}
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)",
+		existingBody:  "old body" + formatFingerprintMarker("helm:default/my-release:my-chart"),
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	issueURL, err := im.CreateHelmIssue(context.Background(), release)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issueURL != "" {
+		t.Errorf("expected no URL for an updated issue, got %q", issueURL)
+	}
+	if fake.created != 0 {
+		t.Errorf("expected no issue to be created, got %d", fake.created)
+	}
+	if fake.edited != 1 {
+		t.Errorf("expected existing issue to be edited once, got %d", fake.edited)
+	}
+	if fake.commented != 1 {
+		t.Errorf("expected one comment on the existing issue, got %d", fake.commented)
+	}
+	if !strings.Contains(fake.lastComment, "`2.0.0` → `2.1.0`") {
+		t.Errorf("expected comment to mention the version change, got %q", fake.lastComment)
+	}
+}
+
+func TestCreateHelmIssue_SkipsWhenVersionUnchanged(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	fake := &fakeGitHub{existingTitle: FormatHelmIssueTitle("[Nova]", release)}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.created != 0 || fake.edited != 0 || fake.commented != 0 {
+		t.Errorf("expected skip, got created=%d edited=%d commented=%d", fake.created, fake.edited, fake.commented)
+	}
+}
+
+func TestCreateHelmIssue_InstalledVersionChangeUpdatesQuietly(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		ChartName:   "my-chart",
+		Installed:   nova.VersionInfo{Version: "1.5.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)",
+		existingBody:  "old body" + formatFingerprintMarker("helm:default/my-release:my-chart"),
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.created != 0 || fake.edited != 1 {
+		t.Errorf("expected the existing issue to be edited, got created=%d edited=%d", fake.created, fake.edited)
+	}
+	if got := fake.editRequest.GetTitle(); got != FormatHelmIssueTitle("[Nova]", release) {
+		t.Errorf("expected the title to show the installed version, got %q", got)
+	}
+	if fake.commented != 0 {
+		t.Errorf("expected no version change comment, got %q", fake.lastComment)
+	}
+}
+
+func TestVersionChangeComment(t *testing.T) {
+	tests := []struct {
+		previous, title string
+		want            string
+	}{
+		{"[Nova] Update Helm chart: api (1.0.0 → 2.0.0)", "[Nova] Update Helm chart: api (1.0.0 → 2.1.0)",
+			formatVersionChangeComment("2.0.0", "2.1.0")},
+		{"[Nova] Update Helm chart: api (1.0.0 → 2.0.0)", "[Nova] Update Helm chart: api (1.5.0 → 2.0.0)", ""},
+		{"Update nginx", "[Nova] Update container image: nginx (1.20 → 1.26)", formatVersionChangeComment("", "1.26")},
+	}
+	for _, tt := range tests {
+		if got := versionChangeComment(tt.previous, tt.title); got != tt.want {
+			t.Errorf("versionChangeComment(%q, %q) = %q, want %q", tt.previous, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestCreateContainerIssue_UpdatesWhenLatestTagAdvances(t *testing.T) {
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.26"}
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update container image: nginx (1.20 → 1.25)",
+		existingBody:  "old body" + formatFingerprintMarker("container:nginx"),
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateContainerIssue(context.Background(), container); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.created != 0 {
+		t.Errorf("expected no issue to be created, got %d", fake.created)
+	}
+	if fake.edited != 1 || fake.commented != 1 {
+		t.Errorf("expected edit and comment, got edited=%d commented=%d", fake.edited, fake.commented)
+	}
+}
+
+func TestCreateHelmIssue_UpdateRespectsDryRun(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.1.0"},
+	}
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)",
+		existingBody:  "old body" + formatFingerprintMarker("helm:/my-release:"),
+	}
+	im := newTestIssueManager(t, &config.Config{DryRun: true}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.created != 0 || fake.edited != 0 || fake.commented != 0 {
+		t.Errorf("expected no writes in dry-run, got created=%d edited=%d commented=%d", fake.created, fake.edited, fake.commented)
+	}
+}
+
+func TestCreateHelmIssue_CreatesWhenNoneExists(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	issueURL, err := im.CreateHelmIssue(context.Background(), release)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issueURL != "https://github.com/owner/repo/issues/8" {
+		t.Errorf("unexpected URL %q", issueURL)
+	}
+	if fake.created != 1 || fake.edited != 0 {
+		t.Errorf("expected one create and no edits, got created=%d edited=%d", fake.created, fake.edited)
+	}
+}
+
+func TestTitleLatestVersion(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"[Nova] Update Helm chart: app (1.0.0 → 2.0.0)", "2.0.0"},
+		{"[Nova] Update container image: nginx (1.20 → 1.25)", "1.25"},
+		{"unrelated title", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := titleLatestVersion(tt.title); got != tt.want {
+				t.Errorf("titleLatestVersion(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueManager_Assignees(t *testing.T) {
+	cfg := &config.Config{
+		IssueAssignees: []string{"platform-oncall"},
+		NamespaceAssignees: map[string][]string{
+			"payments": {"alice"},
+			"search":   {"bob", "alice"},
+		},
+	}
+	im := NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       []string
+	}{
+		{"default assignees", []string{"default"}, []string{"platform-oncall"}},
+		{"namespace routing", []string{"payments"}, []string{"alice"}},
+		{"multiple namespaces merged", []string{"payments", "search"}, []string{"alice", "bob"}},
+		{"no namespaces", nil, []string{"platform-oncall"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := im.assignees(tt.namespaces...)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("assignees(%v) = %v, want %v", tt.namespaces, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateHelmIssue_SetsAssignees(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "payments",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	fake := &fakeGitHub{}
+	cfg := &config.Config{
+		IssueAssignees:     []string{"platform-oncall"},
+		NamespaceAssignees: map[string][]string{"payments": {"alice"}},
+	}
+	im := newTestIssueManager(t, cfg, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.createdAssignees) != 1 || strings.Join(fake.createdAssignees[0], ",") != "alice" {
+		t.Errorf("expected issue assigned to alice, got %v", fake.createdAssignees)
+	}
+}
+
+func TestCreateContainerIssue_SetsAssignees(t *testing.T) {
+	container := nova.ContainerOutput{
+		Name:       "nginx",
+		CurrentTag: "1.20",
+		LatestTag:  "1.25",
+		AffectedWorkloads: []nova.WorkloadOutput{
+			{Name: "web", Namespace: "frontend"},
+		},
+	}
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{IssueAssignees: []string{"platform-oncall"}}, fake)
+
+	if _, err := im.CreateContainerIssue(context.Background(), container); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.createdAssignees) != 1 || strings.Join(fake.createdAssignees[0], ",") != "platform-oncall" {
+		t.Errorf("expected issue assigned to platform-oncall, got %v", fake.createdAssignees)
+	}
+}
+
+func TestCreateHelmIssue_InvalidAssigneeFallsBackToUnassigned(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	fake := &fakeGitHub{rejectAssignees: true}
+	im := newTestIssueManager(t, &config.Config{IssueAssignees: []string{"no-such-user"}}, fake)
+
+	issueURL, err := im.CreateHelmIssue(context.Background(), release)
+	if err != nil {
+		t.Fatalf("expected issue to be created unassigned, got error: %v", err)
+	}
+	if issueURL == "" {
+		t.Error("expected issue URL")
+	}
+	if fake.created != 1 {
+		t.Errorf("expected one issue created, got %d", fake.created)
+	}
+	if len(fake.createdAssignees) != 2 || len(fake.createdAssignees[1]) != 0 {
+		t.Errorf("expected retry without assignees, got %v", fake.createdAssignees)
+	}
+}
+
+func TestFormatIssues_KubeContext(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		ChartName:   "my-chart",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+		Context:     "prod",
+	}
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25", Context: "prod"}
+
+	if got := FormatHelmIssueTitle("[Nova]", release); got != "[Nova] [prod] Update Helm chart: my-release (1.0.0 → 2.0.0)" {
+		t.Errorf("unexpected helm title %q", got)
+	}
+	if got := FormatContainerIssueTitle("[Nova]", container); got != "[Nova] [prod] Update container image: nginx (1.20 → 1.25)" {
+		t.Errorf("unexpected container title %q", got)
+	}
+	if !strings.Contains(FormatHelmIssueBody(release), "| Context | `prod` |") {
+		t.Error("expected context row in helm body")
+	}
+	if !strings.Contains(FormatContainerIssueBody(container, 0), "| Context | `prod` |") {
+		t.Error("expected context row in container body")
+	}
+	if strings.Contains(FormatHelmIssueBody(nova.ReleaseOutput{}), "| Context |") {
+		t.Error("expected no context row without a kube context")
+	}
+}
+
+func TestFingerprint_KubeContext(t *testing.T) {
+	cfg := &config.Config{}
+
+	prod := nova.ReleaseOutput{ReleaseName: "app", Namespace: "default", ChartName: "chart", Context: "prod"}
+	staging := prod
+	staging.Context = "staging"
+
+	if helmFingerprint(cfg, prod) == helmFingerprint(cfg, staging) {
+		t.Error("expected different fingerprints for different contexts")
+	}
+	if got := helmFingerprint(cfg, prod); got != "helm@prod:default/app:chart" {
+		t.Errorf("unexpected helm fingerprint %q", got)
+	}
+
+	container := nova.ContainerOutput{Name: "nginx", Context: "prod"}
+	if got := containerFingerprint(&config.Config{}, container); got != "container@prod:nginx" {
+		t.Errorf("unexpected container fingerprint %q", got)
+	}
+}
+
+func TestFindExistingIssue_ListsOpenIssuesOnce(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"}
+
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := im.CreateHelmIssue(ctx, release); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := im.CreateContainerIssue(ctx, container); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(fake.listQueries) != 1 {
+		t.Errorf("expected open issues to be listed once, got %d list requests", len(fake.listQueries))
+	}
+	if fake.created != 2 {
+		t.Errorf("expected issues created in this run to be deduplicated, got %d created", fake.created)
+	}
+	if im.SkippedIssues() != 2 {
+		t.Errorf("expected 2 skipped duplicates, got %d", im.SkippedIssues())
+	}
+}
+
+func TestFindExistingIssue_ExactFingerprint(t *testing.T) {
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update container image: nginx-exporter (1.0 → 1.1)",
+		existingBody:  "body" + formatFingerprintMarker("container:nginx-exporter"),
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	tests := []struct {
+		title       string
+		fingerprint string
+		wantFound   bool
+	}{
+		{"[Nova] Update container image: nginx (1.20 → 1.25)", "container:nginx", false},
+		{"[Nova] Update container image: nginx-exporter (1.0 → 1.2)", "container:nginx-exporter", true},
+		{"[Nova] Update container image: nginx-exporter (1.0 → 1.1)", "container:other", true},
+	}
+
+	for _, tt := range tests {
+		issue, err := im.findExistingIssue(context.Background(), tt.title, tt.fingerprint)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if (issue != nil) != tt.wantFound {
+			t.Errorf("findExistingIssue(%q) found=%v, want %v", tt.fingerprint, issue != nil, tt.wantFound)
+		}
+	}
+}
+
+func TestListOpenNovaIssues_RateLimitRetry(t *testing.T) {
+	fake := &fakeGitHub{existingTitle: "existing", rateLimited: 1}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	issues, err := im.listNovaIssues(context.Background(), "open", time.Time{})
+	if err != nil {
+		t.Fatalf("expected retry after rate limit to succeed, got %v", err)
+	}
+	if len(issues) != 1 {
+		t.Errorf("expected 1 issue, got %d", len(issues))
+	}
+	if len(fake.listQueries) != 2 {
+		t.Errorf("expected one retry after the rate limit, got %d requests", len(fake.listQueries))
+	}
+}
+
+func TestListOpenNovaIssues_RateLimitExhausted(t *testing.T) {
+	fake := &fakeGitHub{rateLimited: maxRateLimitRetries + 1}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	_, err := im.listNovaIssues(context.Background(), "open", time.Time{})
+	var rateErr *github.RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("expected rate limit error after exhausting retries, got %v", err)
+	}
+	if len(fake.listQueries) != maxRateLimitRetries+1 {
+		t.Errorf("expected %d requests, got %d", maxRateLimitRetries+1, len(fake.listQueries))
+	}
+}
+
+// statusTransport is a mock transport answering issue requests with the given status codes in turn.
+type statusTransport struct {
+	statuses []int
+	requests int
+}
+
+func (st *statusTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	st.requests++
+	status := st.statuses[min(st.requests, len(st.statuses))-1]
+	body := `{"message":"Bad Gateway"}`
+	if status == http.StatusCreated {
+		body = `{"number":8,"html_url":"https://github.com/owner/repo/issues/8"}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+func newRetryTestIssueManager(transport http.RoundTripper, maxAttempts int) *IssueManager {
+	cfg := &config.Config{GitHubOwner: "owner", GitHubRepo: "repo", GitHubMaxAttempts: maxAttempts}
+	im := NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+	im.client = github.NewClient(&http.Client{Transport: transport})
+	im.retryBackoff = time.Millisecond
+	return im
+}
+
+func TestEditIssue_RetriesTransientErrors(t *testing.T) {
+	transport := &statusTransport{statuses: []int{http.StatusBadGateway, http.StatusCreated}}
+	im := newRetryTestIssueManager(transport, 3)
+
+	issue, err := im.editIssue(context.Background(), 8, &github.IssueRequest{}, "")
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if issue.GetHTMLURL() != "https://github.com/owner/repo/issues/8" {
+		t.Errorf("unexpected issue URL %q", issue.GetHTMLURL())
+	}
+	if transport.requests != 2 {
+		t.Errorf("expected one retry after the 502, got %d requests", transport.requests)
+	}
+}
+
+func TestEditIssue_RetriesBoundedByMaxAttempts(t *testing.T) {
+	transport := &statusTransport{statuses: []int{http.StatusServiceUnavailable}}
+	im := newRetryTestIssueManager(transport, 3)
+
+	if _, err := im.editIssue(context.Background(), 8, &github.IssueRequest{}, ""); err == nil {
+		t.Fatal("expected error after exhausting attempts")
+	}
+	if transport.requests != 3 {
+		t.Errorf("expected 3 attempts, got %d", transport.requests)
+	}
+}
+
+func TestCreateIssue_DoesNotRetryTransientErrors(t *testing.T) {
+	// GitHub may have created the issue before failing, so a retry could file it twice
+	transport := &statusTransport{statuses: []int{http.StatusBadGateway, http.StatusCreated}}
+	im := newRetryTestIssueManager(transport, 3)
+
+	if _, err := im.createIssue(context.Background(), "helm", "title", "body", nil, nil); err == nil {
+		t.Fatal("expected the 502 to be returned")
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected no retry of a create after a 5xx, got %d requests", transport.requests)
+	}
+}
+
+func TestCreateIssue_DoesNotRetryClientErrors(t *testing.T) {
+	transport := &statusTransport{statuses: []int{http.StatusNotFound, http.StatusCreated}}
+	im := newRetryTestIssueManager(transport, 3)
+
+	if _, err := im.createIssue(context.Background(), "helm", "title", "body", nil, nil); err == nil {
+		t.Fatal("expected the 404 to be returned")
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected no retry after a 4xx, got %d requests", transport.requests)
+	}
+}
+
+func TestEditIssue_RetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transport := &statusTransport{statuses: []int{http.StatusBadGateway, http.StatusCreated}}
+	im := newRetryTestIssueManager(transport, 3)
+	im.retryBackoff = time.Hour
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := im.editIssue(ctx, 8, &github.IssueRequest{}, "")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry backoff did not stop on cancel")
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected no request after cancel, got %d requests", transport.requests)
+	}
+}
+
+func TestRateLimit_RecordedFromResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4990")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{GitHubOwner: "owner", GitHubRepo: "repo", RepoRouting: map[string]config.RepoRoute{"team-a": {Repo: "team-a"}}}
+	im := NewIssueManager(context.Background(), cfg, logging.NewLogger("error", "json"))
+	im.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	if _, _, ok := im.RateLimit(); ok {
+		t.Error("expected no rate limit before any request")
+	}
+
+	// Routed repositories share the quota of the token
+	if _, err := im.forNamespaces("team-a").openNovaIssues(context.Background()); err != nil {
+		t.Fatalf("openNovaIssues() error = %v", err)
+	}
+	remaining, limit, ok := im.RateLimit()
+	if !ok || remaining != 4990 || limit != 5000 {
+		t.Errorf("RateLimit() = %d, %d, %v, want 4990, 5000, true", remaining, limit, ok)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	response := func(status int) *github.ErrorResponse {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status}}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"502", response(http.StatusBadGateway), true},
+		{"500", response(http.StatusInternalServerError), true},
+		{"404", response(http.StatusNotFound), false},
+		{"422", response(http.StatusUnprocessableEntity), false},
+		{"network error", &url.Error{Op: "Post", URL: "https://api.github.com", Err: errors.New("connection reset")}, true},
+		{"canceled", &url.Error{Op: "Post", URL: "https://api.github.com", Err: context.Canceled}, false},
+		{"other error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	retryAfter := 5 * time.Second
+	tests := []struct {
+		name        string
+		err         error
+		wantWait    time.Duration
+		wantLimited bool
+	}{
+		{"primary limit already reset", &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: time.Now().Add(-time.Minute)}}}, 0, true},
+		{"secondary limit with retry-after", &github.AbuseRateLimitError{RetryAfter: &retryAfter}, retryAfter, true},
+		{"secondary limit without retry-after", &github.AbuseRateLimitError{}, defaultSecondaryRateLimitWait, true},
+		{"other error", errors.New("boom"), 0, false},
+		{"no error", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, limited := rateLimitWait(tt.err)
+			if wait != tt.wantWait || limited != tt.wantLimited {
+				t.Errorf("rateLimitWait() = (%s, %v), want (%s, %v)", wait, limited, tt.wantWait, tt.wantLimited)
+			}
+		})
+	}
+}
+
+func TestListOpenNovaIssues_Paginated(t *testing.T) {
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update container image: nginx (1.20 → 1.25)",
+		existingBody:  "body" + formatFingerprintMarker("container:nginx"),
+		pages:         3,
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	issues, err := im.listNovaIssues(context.Background(), "open", time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.listQueries) != 3 {
+		t.Errorf("expected 3 page requests, got %d", len(fake.listQueries))
+	}
+	if !strings.Contains(fake.listQueries[0], "per_page=100") {
+		t.Errorf("expected 100 issues per page, got %q", fake.listQueries[0])
+	}
+	// Two unrelated issues plus the existing one; pull requests are dropped
+	if len(issues) != 3 {
+		t.Errorf("expected 3 issues across pages, got %d", len(issues))
+	}
+	for _, issue := range issues {
+		if issue.IsPullRequest() {
+			t.Errorf("expected pull request #%d to be filtered out", issue.GetNumber())
+		}
+	}
+}
+
+func TestCreateContainerIssue_MatchesIssueOnLaterPage(t *testing.T) {
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.26"}
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update container image: nginx (1.20 → 1.25)",
+		existingBody:  "body" + formatFingerprintMarker("container:nginx"),
+		pages:         2,
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateContainerIssue(context.Background(), container); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.created != 0 || fake.edited != 1 {
+		t.Errorf("expected the issue on page 2 to be updated, got created=%d edited=%d", fake.created, fake.edited)
+	}
+}
+
+func TestCreateIssue_TracingSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	im := newTestIssueManager(t, &config.Config{}, &fakeGitHub{})
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"}
+	if _, err := im.CreateContainerIssue(context.Background(), container); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "github.CreateIssue" {
+		t.Fatalf("expected one github.CreateIssue span, got %v", spans)
+	}
+	for _, attr := range spans[0].Attributes {
+		if attr.Key == "issue.type" && attr.Value.AsString() != "container" {
+			t.Errorf("expected issue.type container, got %q", attr.Value.AsString())
+		}
+	}
+}
+
+func TestCreateHelmIssue_ReopenClosed(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		ChartName:   "my-chart",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.1.0"},
+	}
+	closedTitle := "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)"
+	closedBody := "old body" + formatFingerprintMarker("helm:default/my-release:my-chart")
+
+	tests := []struct {
+		name         string
+		reopenClosed bool
+		labels       []string
+		wantReopened bool
+		wantCreated  int
+		wantSkipped  int
+	}{
+		{"closed issue is reopened", true, []string{"nova-scan"}, true, 0, 0},
+		{"nova-ignore keeps the issue closed", true, []string{"nova-scan", "nova-ignore"}, false, 0, 1},
+		{"closed issues are ignored without reopenClosed", false, []string{"nova-scan"}, false, 1, 0},
+		{"suppressed without reopenClosed", false, []string{"nova-scan", "nova-ignore"}, false, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitHub{closedTitle: closedTitle, closedBody: closedBody, closedLabels: tt.labels}
+			im := newTestIssueManager(t, &config.Config{ReopenClosed: tt.reopenClosed, SuppressLabel: "nova-ignore"}, fake)
+
+			if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if reopened := fake.reopenRequest != nil; reopened != tt.wantReopened {
+				t.Fatalf("expected reopened=%v, got %v", tt.wantReopened, reopened)
+			}
+			if tt.wantReopened {
+				if fake.reopenRequest.GetState() != "open" {
+					t.Errorf("expected state open, got %q", fake.reopenRequest.GetState())
+				}
+				if want := FormatHelmIssueTitle("[Nova]", release); fake.reopenRequest.GetTitle() != want {
+					t.Errorf("expected refreshed title %q, got %q", want, fake.reopenRequest.GetTitle())
+				}
+				if !strings.Contains(fake.lastComment, "Still detected as outdated") {
+					t.Errorf("expected reopen comment, got %q", fake.lastComment)
+				}
+			}
+			if fake.created != tt.wantCreated {
+				t.Errorf("expected %d created issues, got %d", tt.wantCreated, fake.created)
+			}
+			if im.SkippedIssues() != tt.wantSkipped {
+				t.Errorf("expected %d skipped issues, got %d", tt.wantSkipped, im.SkippedIssues())
+			}
+		})
+	}
+}
+
+func TestCreateHelmIssue_ReopenClosedPrefersOpenIssue(t *testing.T) {
+	release := nova.ReleaseOutput{ReleaseName: "my-release", Namespace: "default", ChartName: "my-chart"}
+	fake := &fakeGitHub{
+		existingTitle: FormatHelmIssueTitle("[Nova]", release),
+		closedTitle:   FormatHelmIssueTitle("[Nova]", release),
+	}
+	im := newTestIssueManager(t, &config.Config{ReopenClosed: true}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.reopenRequest != nil {
+		t.Error("expected the closed issue to stay closed while an open one exists")
+	}
+	for _, query := range fake.listQueries {
+		if strings.Contains(query, "state=closed") {
+			t.Errorf("expected no closed issue listing, got %q", query)
+		}
+	}
+}
+
+func TestCreateIssue_SuppressLabel(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		ChartName:   "my-chart",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.1.0"},
+	}
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"}
+	helmBody := "body" + formatFingerprintMarker("helm:default/my-release:my-chart")
+	containerBody := "body" + formatFingerprintMarker("container:nginx")
+
+	tests := []struct {
+		name   string
+		fake   *fakeGitHub
+		create func(im *IssueManager) error
+	}{
+		{
+			name: "open helm issue is not updated",
+			fake: &fakeGitHub{existingTitle: "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)",
+				existingBody: helmBody, existingLabels: []string{"wontfix"}},
+			create: func(im *IssueManager) error {
+				_, err := im.CreateHelmIssue(context.Background(), release)
+				return err
+			},
+		},
+		{
+			name: "closed helm issue is not recreated",
+			fake: &fakeGitHub{closedTitle: "[Nova] Update Helm chart: my-release (0.9.0 → 1.0.0)",
+				closedBody: helmBody, closedLabels: []string{"wontfix"}},
+			create: func(im *IssueManager) error {
+				_, err := im.CreateHelmIssue(context.Background(), release)
+				return err
+			},
+		},
+		{
+			name: "closed container issue is not recreated",
+			fake: &fakeGitHub{closedTitle: "old", closedBody: containerBody, closedLabels: []string{"wontfix"}},
+			create: func(im *IssueManager) error {
+				_, err := im.CreateContainerIssue(context.Background(), container)
+				return err
+			},
+		},
+		{
+			name: "closed namespace issue is not recreated",
+			fake: &fakeGitHub{closedTitle: "old", closedBody: "body" + formatFingerprintMarker("namespace:default"),
+				closedLabels: []string{"wontfix"}},
+			create: func(im *IssueManager) error {
+				_, err := im.CreateNamespaceIssue(context.Background(), GroupByNamespace([]nova.ReleaseOutput{release}, nil)[0])
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im := newTestIssueManager(t, &config.Config{SuppressLabel: "wontfix", ReopenClosed: true}, tt.fake)
+
+			if err := tt.create(im); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.fake.created != 0 || tt.fake.edited != 0 || tt.fake.reopenRequest != nil {
+				t.Errorf("expected suppressed issue to be left alone, got %d created, %d edited, reopened=%v",
+					tt.fake.created, tt.fake.edited, tt.fake.reopenRequest != nil)
+			}
+			if im.SkippedIssues() != 1 {
+				t.Errorf("expected 1 skipped issue, got %d", im.SkippedIssues())
+			}
+			if !strings.Contains(tt.fake.listQueries[0], "labels=nova-scan%2Cwontfix") || !strings.Contains(tt.fake.listQueries[0], "state=all") {
+				t.Errorf("expected suppressed issues of any state to be listed first, got %q", tt.fake.listQueries[0])
+			}
+		})
+	}
+}
+
+func TestCreateHelmIssue_ConcurrentDedup(t *testing.T) {
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	// Ten releases, each submitted three times; only one issue per release may be created
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		release := nova.ReleaseOutput{
+			ReleaseName: fmt.Sprintf("release-%d", i%10),
+			Namespace:   "default",
+			ChartName:   "chart",
+			Installed:   nova.VersionInfo{Version: "1.0.0"},
+			Latest:      nova.VersionInfo{Version: "2.0.0"},
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := im.CreateHelmIssue(context.Background(), release); err != nil {
+				t.Errorf("CreateHelmIssue() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fake.created != 10 {
+		t.Errorf("expected 10 issues created, got %d", fake.created)
+	}
+	if got := im.SkippedIssues(); got != 20 {
+		t.Errorf("expected 20 duplicates skipped, got %d", got)
+	}
+	if got := len(im.CreatedIssues()); got != 10 {
+		t.Errorf("expected 10 created issues recorded, got %d", got)
+	}
+}
@@ -0,0 +1,248 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/tracing"
+)
+
+// namespaceUpdateComment is posted on a namespace issue whose components changed.
+const namespaceUpdateComment = "nova-scanner detected changes in the outdated components of this namespace. Title and description have been updated."
+
+// NamespaceGroup collects the outdated Helm releases and container images of one namespace,
+// reported together in a single issue when groupBy is "namespace".
+type NamespaceGroup struct {
+	Namespace  string
+	Context    string
+	Releases   []nova.ReleaseOutput
+	Containers []nova.ContainerOutput
+}
+
+// GroupByNamespace groups outdated releases and containers by kube context and namespace.
+// A container used in several namespaces appears in each group, limited to that namespace's workloads.
+// Groups are sorted by context and namespace, and their releases and containers by name, so the
+// issue body doesn't change with the order nova reports them in.
+func GroupByNamespace(releases []nova.ReleaseOutput, containers []nova.ContainerOutput) []NamespaceGroup {
+	groups := make(map[string]*NamespaceGroup)
+	group := func(kubeContext, namespace string) *NamespaceGroup {
+		key := kubeContext + "/" + namespace
+		if groups[key] == nil {
+			groups[key] = &NamespaceGroup{Namespace: namespace, Context: kubeContext}
+		}
+		return groups[key]
+	}
+
+	for _, release := range releases {
+		g := group(release.Context, release.Namespace)
+		g.Releases = append(g.Releases, release)
+	}
+
+	for _, container := range containers {
+		if len(container.AffectedWorkloads) == 0 {
+			g := group(container.Context, "")
+			g.Containers = append(g.Containers, container)
+			continue
+		}
+		for _, namespace := range workloadNamespaces(container.AffectedWorkloads) {
+			scoped := container
+			scoped.AffectedWorkloads = nil
+			for _, w := range container.AffectedWorkloads {
+				if w.Namespace == namespace {
+					scoped.AffectedWorkloads = append(scoped.AffectedWorkloads, w)
+				}
+			}
+			g := group(container.Context, namespace)
+			g.Containers = append(g.Containers, scoped)
+		}
+	}
+
+	result := make([]NamespaceGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.SliceStable(g.Releases, func(i, j int) bool { return g.Releases[i].ReleaseName < g.Releases[j].ReleaseName })
+		sort.SliceStable(g.Containers, func(i, j int) bool { return g.Containers[i].Name < g.Containers[j].Name })
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Context != result[j].Context {
+			return result[i].Context < result[j].Context
+		}
+		return result[i].Namespace < result[j].Namespace
+	})
+	return result
+}
+
+// CreateNamespaceIssue creates a GitHub issue covering all outdated components in a namespace.
+// An existing issue for the namespace is updated when its title or body changed.
+// Returns the issue URL if created, empty string if skipped or updated.
+func (im *IssueManager) CreateNamespaceIssue(ctx context.Context, group NamespaceGroup) (issueURL string, err error) {
+	im = im.forNamespaces(group.Namespace)
+	title := FormatNamespaceIssueTitle(im.config.TitlePrefix(), group)
+	ctx, span := startIssueSpan(ctx, "namespace", title)
+	defer func() { tracing.End(span, err) }()
+	fingerprint := namespaceFingerprint(im.config, group)
+	defer im.lockFingerprint(fingerprint)()
+	body := truncateIssueBody(FormatNamespaceIssueBody(group)) + formatFingerprintMarker(fingerprint)
+
+	// Check if issue already exists
+	existing, err := im.findExistingIssue(ctx, title, fingerprint)
+	if err != nil {
+		return "", fmt.Errorf("failed to check existing issues: %w", err)
+	}
+	if existing != nil {
+		if im.isSuppressed(existing) {
+			im.suppressIssue("namespace", title, existing)
+			return "", nil
+		}
+		if isClosed(existing) {
+			return "", im.reopenIssue(ctx, "namespace", existing, title, body)
+		}
+		if existing.GetTitle() != title || !sameIssueBody(existing.GetBody(), body) {
+			return "", im.updateIssue(ctx, "namespace", existing.GetNumber(), title, body, namespaceUpdateComment)
+		}
+		im.skipIssue("namespace", title)
+		return "", nil
+	}
+
+	return im.createIssue(ctx, "namespace", title, body, namespaceLabels(im.config, group), im.assignees(group.Namespace))
+}
+
+// namespaceLabels returns the labels of a namespace issue: the type labels of the kinds it
+// contains and the highest severity of its components.
+func namespaceLabels(cfg *config.Config, group NamespaceGroup) []string {
+	var extra []string
+	if len(group.Releases) > 0 {
+		extra = append(extra, labelHelmUpdate)
+	}
+	if len(group.Containers) > 0 {
+		extra = append(extra, labelContainerUpdate)
+	}
+	for _, release := range group.Releases {
+		if release.SecurityAlert {
+			extra = append(extra, labelSecurity)
+			break
+		}
+	}
+
+	// The namespace issue carries the highest severity of its components, or the unknown
+	// severity if none of them could be classified
+	var severities []int
+	deprecated := false
+	for _, release := range group.Releases {
+		severities = append(severities, release.UpgradeSeverity())
+		deprecated = deprecated || release.Deprecated
+	}
+	for _, container := range group.Containers {
+		severities = append(severities, nova.VersionSeverity(container.CurrentTag, container.LatestTag))
+	}
+	severity := 0
+	for _, s := range severities {
+		severity = max(severity, s)
+	}
+	if severity == 0 && slices.Contains(severities, nova.SeverityUnknown) {
+		severity = nova.SeverityUnknown
+	}
+	extra = append(extra, severityLabel(cfg, severity), deprecatedLabel(cfg, deprecated))
+	return issueLabels(cfg, extra...)
+}
+
+// namespaceFingerprint returns the dedup key for a namespace issue.
+func namespaceFingerprint(cfg *config.Config, group NamespaceGroup) string {
+	return fingerprintKind(cfg, "namespace", group.Context) + ":" + group.Namespace
+}
+
+// FormatNamespaceIssueTitle generates the issue title for a namespace group, starting with prefix.
+func FormatNamespaceIssueTitle(prefix string, group NamespaceGroup) string {
+	count := len(group.Releases) + len(group.Containers)
+	noun := "components"
+	if count == 1 {
+		noun = "component"
+	}
+	return fmt.Sprintf("%s %sUpdate %d outdated %s in namespace: %s",
+		prefix,
+		formatContextTag(group.Context),
+		count,
+		noun,
+		formatNamespaceName(group.Namespace),
+	)
+}
+
+// FormatNamespaceIssueBody generates the issue body for a namespace group.
+func FormatNamespaceIssueBody(group NamespaceGroup) string {
+	var sb strings.Builder
+	sb.WriteString("## Outdated Components Detected\n\n")
+	sb.WriteString("| Field | Value |\n|-------|-------|\n")
+	sb.WriteString(fmt.Sprintf("| Namespace | %s |\n", backtick(formatNamespaceName(group.Namespace))))
+	sb.WriteString(formatContextRow(group.Context))
+	sb.WriteString(fmt.Sprintf("| Helm Releases | %d |\n", len(group.Releases)))
+	sb.WriteString(fmt.Sprintf("| Container Images | %d |\n", len(group.Containers)))
+
+	securityAlert := false
+	if len(group.Releases) > 0 {
+		sb.WriteString("\n### Helm Releases\n\n")
+		sb.WriteString("| Release | Chart | Current Version | Latest Version | Deprecated |\n")
+		sb.WriteString("|---------|-------|-----------------|----------------|------------|\n")
+		for _, release := range group.Releases {
+			deprecated := "No"
+			if release.Deprecated {
+				deprecated = "Yes"
+			}
+			securityAlert = securityAlert || release.SecurityAlert
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+				backtick(release.ReleaseName),
+				backtick(release.ChartName),
+				backtick(release.Installed.Version),
+				backtick(release.Latest.Version),
+				deprecated,
+			))
+		}
+	}
+	sb.WriteString(formatSecurityNote(securityAlert))
+
+	if len(group.Containers) > 0 {
+		sb.WriteString("\n### Container Images\n\n")
+		sb.WriteString("| Image | Current Tag | Latest Tag | Workloads |\n")
+		sb.WriteString("|-------|-------------|------------|-----------|\n")
+		for _, container := range group.Containers {
+			var workloads []string
+			for _, w := range container.AffectedWorkloads {
+				workloads = append(workloads, w.Kind+"/"+w.Name)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				backtick(container.Name),
+				backtick(container.CurrentTag),
+				backtick(container.LatestTag),
+				strings.Join(workloads, ", "),
+			))
+		}
+	}
+
+	sb.WriteString("\n## Update Checklist\n\n")
+	for _, release := range group.Releases {
+		sb.WriteString(fmt.Sprintf("- [ ] Update Helm release %s to chart version %s (review changelog from %s)\n",
+			backtick(release.ReleaseName), backtick(release.Latest.Version), release.Installed.Version))
+	}
+	for _, container := range group.Containers {
+		sb.WriteString(fmt.Sprintf("- [ ] Update image %s to tag %s (review release notes from %s)\n",
+			backtick(container.Name), backtick(container.LatestTag), container.CurrentTag))
+	}
+	sb.WriteString("- [ ] Commit and push to trigger Flux reconciliation\n")
+	sb.WriteString("- [ ] Check application health post-upgrade\n")
+
+	sb.WriteString("\n---\n*This issue was automatically created by nova-scanner*\n")
+	return sb.String()
+}
+
+// formatNamespaceName returns the display name for a namespace; containers without
+// workload information are grouped under an empty namespace.
+func formatNamespaceName(namespace string) string {
+	if namespace == "" {
+		return "unknown"
+	}
+	return namespace
+}
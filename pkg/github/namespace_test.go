@@ -0,0 +1,206 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func testNamespaceItems() ([]nova.ReleaseOutput, []nova.ContainerOutput) {
+	releases := []nova.ReleaseOutput{
+		{ReleaseName: "api", ChartName: "api-chart", Namespace: "payments",
+			Installed: nova.VersionInfo{Version: "1.0.0"}, Latest: nova.VersionInfo{Version: "1.2.0"}},
+		{ReleaseName: "worker", ChartName: "worker-chart", Namespace: "payments",
+			Installed: nova.VersionInfo{Version: "2.0.0"}, Latest: nova.VersionInfo{Version: "3.0.0"}, Deprecated: true},
+		{ReleaseName: "grafana", ChartName: "grafana", Namespace: "monitoring",
+			Installed: nova.VersionInfo{Version: "7.0.0"}, Latest: nova.VersionInfo{Version: "7.0.1"}},
+	}
+	containers := []nova.ContainerOutput{
+		{Name: "redis", CurrentTag: "7.0.0", LatestTag: "7.2.0", AffectedWorkloads: []nova.WorkloadOutput{
+			{Name: "cache", Namespace: "payments", Kind: "StatefulSet", Container: "redis"},
+			{Name: "queue", Namespace: "jobs", Kind: "Deployment", Container: "redis"},
+		}},
+	}
+	return releases, containers
+}
+
+func TestGroupByNamespace(t *testing.T) {
+	releases, containers := testNamespaceItems()
+	groups := GroupByNamespace(releases, containers)
+
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 namespace groups, got %d", len(groups))
+	}
+
+	tests := []struct {
+		namespace  string
+		releases   int
+		containers int
+	}{
+		{"jobs", 0, 1},
+		{"monitoring", 1, 0},
+		{"payments", 2, 1},
+	}
+	for i, tt := range tests {
+		g := groups[i]
+		if g.Namespace != tt.namespace {
+			t.Errorf("group %d: expected namespace %q, got %q", i, tt.namespace, g.Namespace)
+		}
+		if len(g.Releases) != tt.releases || len(g.Containers) != tt.containers {
+			t.Errorf("%s: expected %d releases and %d containers, got %d and %d",
+				tt.namespace, tt.releases, tt.containers, len(g.Releases), len(g.Containers))
+		}
+	}
+
+	// A container spanning namespaces only lists each namespace's own workloads
+	payments := groups[2]
+	if workloads := payments.Containers[0].AffectedWorkloads; len(workloads) != 1 || workloads[0].Name != "cache" {
+		t.Errorf("expected only the payments workload, got %v", workloads)
+	}
+}
+
+func TestGroupByNamespace_KubeContext(t *testing.T) {
+	releases := []nova.ReleaseOutput{
+		{ReleaseName: "api", Namespace: "default", Context: "prod"},
+		{ReleaseName: "api", Namespace: "default", Context: "staging"},
+	}
+
+	groups := GroupByNamespace(releases, nil)
+	if len(groups) != 2 {
+		t.Fatalf("expected separate groups per context, got %d", len(groups))
+	}
+	if groups[0].Context != "prod" || groups[1].Context != "staging" {
+		t.Errorf("expected groups sorted by context, got %q and %q", groups[0].Context, groups[1].Context)
+	}
+}
+
+func TestFormatNamespaceIssueTitle(t *testing.T) {
+	releases, containers := testNamespaceItems()
+	groups := GroupByNamespace(releases, containers)
+
+	tests := []struct {
+		group NamespaceGroup
+		want  string
+	}{
+		{groups[2], "[Nova] Update 3 outdated components in namespace: payments"},
+		{groups[1], "[Nova] Update 1 outdated component in namespace: monitoring"},
+		{NamespaceGroup{Namespace: "default", Context: "prod", Releases: releases[:1]},
+			"[Nova] [prod] Update 1 outdated component in namespace: default"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatNamespaceIssueTitle("[Nova]", tt.group); got != tt.want {
+			t.Errorf("FormatNamespaceIssueTitle() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestFormatNamespaceIssueBody(t *testing.T) {
+	releases, containers := testNamespaceItems()
+	body := FormatNamespaceIssueBody(GroupByNamespace(releases, containers)[2])
+
+	expected := []string{
+		"## Outdated Components Detected",
+		"| Namespace | `payments` |",
+		"| Helm Releases | 2 |",
+		"| Container Images | 1 |",
+		"### Helm Releases",
+		"| `worker` | `worker-chart` | `2.0.0` | `3.0.0` | Yes |",
+		"### Container Images",
+		"| `redis` | `7.0.0` | `7.2.0` | StatefulSet/cache |",
+		"- [ ] Update Helm release `api` to chart version `1.2.0`",
+		"- [ ] Update image `redis` to tag `7.2.0`",
+		"*This issue was automatically created by nova-scanner*",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(body, exp) {
+			t.Errorf("body should contain %q", exp)
+		}
+	}
+	if strings.Contains(body, "StatefulSet/cache, Deployment/queue") {
+		t.Error("body should not list workloads from other namespaces")
+	}
+}
+
+func TestCreateNamespaceIssue(t *testing.T) {
+	releases, containers := testNamespaceItems()
+	group := GroupByNamespace(releases, containers)[2]
+
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	issueURL, err := im.CreateNamespaceIssue(context.Background(), group)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issueURL != "https://github.com/owner/repo/issues/8" {
+		t.Errorf("expected created issue URL, got %q", issueURL)
+	}
+	if !strings.Contains(fake.lastCreatedBody, fingerprintPrefix+"namespace:payments") {
+		t.Errorf("expected namespace fingerprint in the issue body, got %q", fake.lastCreatedBody)
+	}
+	want := []string{"nova-scan", "helm-update", "container-update"}
+	if strings.Join(fake.createdLabels, ",") != strings.Join(want, ",") {
+		t.Errorf("expected labels %v, got %v", want, fake.createdLabels)
+	}
+}
+
+func TestCreateNamespaceIssue_Existing(t *testing.T) {
+	releases, containers := testNamespaceItems()
+	group := GroupByNamespace(releases, containers)[2]
+	title := FormatNamespaceIssueTitle("[Nova]", group)
+	body := FormatNamespaceIssueBody(group) + formatFingerprintMarker(namespaceFingerprint(&config.Config{}, group))
+
+	tests := []struct {
+		name       string
+		title      string
+		body       string
+		wantEdited int
+	}{
+		{"unchanged issue is skipped", title, body, 0},
+		{"unchanged issue written by an earlier run is skipped", title, body + formatTraceIDMarker("0123abcd"), 0},
+		{"issue edited with CRLF line endings is skipped", title, strings.ReplaceAll(body, "\n", "\r\n") + "\r\n", 0},
+		{"trailing whitespace is ignored", title, strings.ReplaceAll(body, "\n", "  \n"), 0},
+		{"changed components update the issue", "[Nova] Update 2 outdated components in namespace: payments",
+			"old body" + formatFingerprintMarker(namespaceFingerprint(&config.Config{}, group)), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitHub{existingTitle: tt.title, existingBody: tt.body}
+			im := newTestIssueManager(t, &config.Config{}, fake)
+
+			if _, err := im.CreateNamespaceIssue(context.Background(), group); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fake.created != 0 {
+				t.Errorf("expected no new issue, got %d", fake.created)
+			}
+			if fake.edited != tt.wantEdited {
+				t.Errorf("expected %d edits, got %d", tt.wantEdited, fake.edited)
+			}
+			if fake.edited > 0 && !strings.Contains(fake.editRequest.GetBody(), formatTraceIDMarker(im.logger.TraceID())) {
+				t.Errorf("expected the updated body to carry this run's trace ID, got %q", fake.editRequest.GetBody())
+			}
+		})
+	}
+}
+
+func TestGroupByNamespace_ComponentOrder(t *testing.T) {
+	releases, containers := testNamespaceItems()
+	slices.Reverse(releases)
+	slices.Reverse(containers)
+	reversed := GroupByNamespace(releases, containers)
+	releases, containers = testNamespaceItems()
+	groups := GroupByNamespace(releases, containers)
+
+	for i := range groups {
+		if FormatNamespaceIssueBody(groups[i]) != FormatNamespaceIssueBody(reversed[i]) {
+			t.Errorf("expected the body of %s to not depend on nova's output order", groups[i].Namespace)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// releaseNotesURL returns a best-effort link comparing two releases of the project at home.
+// Only GitHub project homes are supported; releases are assumed to be tagged with a "v" prefix.
+// Returns an empty string when no link can be derived.
+func releaseNotesURL(home, current, latest string) string {
+	if current == "" || latest == "" || current == latest {
+		return ""
+	}
+	u, err := url.Parse(home)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host != "github.com" {
+		return ""
+	}
+	// Keep the owner/repo of deeper links such as /owner/repo/tree/main/charts
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	repo := strings.TrimSuffix(parts[1], ".git")
+	return fmt.Sprintf("https://github.com/%s/%s/compare/%s...%s", parts[0], repo, releaseTag(current), releaseTag(latest))
+}
+
+// releaseTag returns the git tag assumed for a version.
+func releaseTag(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// helmReleaseNotesURL returns the compare link for a Helm release upgrade. The chart home
+// usually points at the application, so app versions are compared when both are known.
+func helmReleaseNotesURL(release nova.ReleaseOutput) string {
+	current, latest := release.Installed.Version, release.Latest.Version
+	if release.Installed.AppVersion != "" && release.Latest.AppVersion != "" {
+		current, latest = release.Installed.AppVersion, release.Latest.AppVersion
+	}
+	return releaseNotesURL(release.Home, current, latest)
+}
+
+// containerReleaseNotesURL returns the compare link for a container image upgrade.
+// Images published to GitHub Container Registry (ghcr.io/owner/repo) are linked to their repository.
+func containerReleaseNotesURL(container nova.ContainerOutput) string {
+	if container.DigestPinned {
+		return ""
+	}
+	path, ok := strings.CutPrefix(container.Name, "ghcr.io/")
+	if !ok {
+		return ""
+	}
+	return releaseNotesURL("https://github.com/"+path, container.CurrentTag, container.LatestTag)
+}
+
+// formatReleaseNotesRow returns the issue table row linking the release notes, if known.
+func formatReleaseNotesRow(releaseNotesURL string) string {
+	if releaseNotesURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("| Release Notes | [Compare changes](%s) |\n", releaseNotesURL)
+}
@@ -0,0 +1,82 @@
+package github
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func TestReleaseNotesURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		home    string
+		current string
+		latest  string
+		want    string
+	}{
+		{"github home", "https://github.com/cert-manager/cert-manager", "1.13.0", "1.14.2",
+			"https://github.com/cert-manager/cert-manager/compare/v1.13.0...v1.14.2"},
+		{"v-prefixed versions", "https://github.com/traefik/traefik", "v2.10.0", "v3.0.0",
+			"https://github.com/traefik/traefik/compare/v2.10.0...v3.0.0"},
+		{"deep link and trailing slash", "https://github.com/grafana/helm-charts/tree/main/charts/loki/", "5.0.0", "5.1.0",
+			"https://github.com/grafana/helm-charts/compare/v5.0.0...v5.1.0"},
+		{"git suffix", "https://github.com/owner/repo.git", "1.0.0", "2.0.0",
+			"https://github.com/owner/repo/compare/v1.0.0...v2.0.0"},
+		{"non-github home", "https://cert-manager.io", "1.13.0", "1.14.2", ""},
+		{"github org only", "https://github.com/cert-manager", "1.13.0", "1.14.2", ""},
+		{"empty home", "", "1.0.0", "2.0.0", ""},
+		{"same versions", "https://github.com/owner/repo", "1.0.0", "1.0.0", ""},
+		{"missing version", "https://github.com/owner/repo", "", "2.0.0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := releaseNotesURL(tt.home, tt.current, tt.latest); got != tt.want {
+				t.Errorf("releaseNotesURL(%q, %q, %q) = %q, want %q", tt.home, tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHelmIssueBody_ReleaseNotes(t *testing.T) {
+	release := nova.ReleaseOutput{
+		ReleaseName: "cert-manager",
+		ChartName:   "cert-manager",
+		Namespace:   "infra",
+		Home:        "https://github.com/cert-manager/cert-manager",
+		Installed:   nova.VersionInfo{Version: "1.13.0"},
+		Latest:      nova.VersionInfo{Version: "1.14.2"},
+	}
+
+	body := FormatHelmIssueBody(release)
+	if !strings.Contains(body, "| Release Notes | [Compare changes](https://github.com/cert-manager/cert-manager/compare/v1.13.0...v1.14.2) |") {
+		t.Errorf("expected release notes link, got %q", body)
+	}
+
+	// App versions are preferred since the home usually belongs to the application
+	release.Installed.AppVersion, release.Latest.AppVersion = "v1.13.0", "v1.14.2"
+	release.Installed.Version, release.Latest.Version = "13.0.0", "14.2.0"
+	if body := FormatHelmIssueBody(release); !strings.Contains(body, "/compare/v1.13.0...v1.14.2)") {
+		t.Errorf("expected app versions in release notes link, got %q", body)
+	}
+
+	release.Home = "https://cert-manager.io"
+	if body := FormatHelmIssueBody(release); strings.Contains(body, "Release Notes") {
+		t.Error("expected no release notes row for a non-GitHub home")
+	}
+}
+
+func TestFormatContainerIssueBody_ReleaseNotes(t *testing.T) {
+	container := nova.ContainerOutput{Name: "ghcr.io/fluxcd/source-controller", CurrentTag: "v1.2.0", LatestTag: "v1.3.0"}
+
+	body := FormatContainerIssueBody(container, 0)
+	if !strings.Contains(body, "| Release Notes | [Compare changes](https://github.com/fluxcd/source-controller/compare/v1.2.0...v1.3.0) |") {
+		t.Errorf("expected release notes link for a ghcr.io image, got %q", body)
+	}
+
+	container.Name = "docker.io/library/nginx"
+	if body := FormatContainerIssueBody(container, 0); strings.Contains(body, "Release Notes") {
+		t.Error("expected no release notes row for an image outside ghcr.io")
+	}
+}
@@ -0,0 +1,141 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+const (
+	// resolvedReason is logged for an issue that would be closed in dry-run mode.
+	resolvedReason = "component no longer outdated"
+	// resolvedComment is posted on an issue closed by closeResolved.
+	resolvedComment = "nova-scanner no longer detects this component as outdated, so this issue is being closed. " +
+		"A new issue is opened if it becomes outdated again."
+)
+
+// fingerprintMarkerPattern matches the fingerprint marker of an issue body, capturing the fingerprint.
+var fingerprintMarkerPattern = regexp.MustCompile(`<!-- ` + fingerprintPrefix + `(.+?) -->`)
+
+// CloseResolvedIssues closes the open issues of components that are not outdated in result, in
+// the default and all routed repositories. Only issues carrying a fingerprint of this
+// deployment are considered, and only those of the scan types that ran: Helm and container
+// issues, and namespace issues with groupBy namespace. Issues carrying the suppress label are
+// left open. The caller must only pass the result of a run whose scans all succeeded, as a
+// failed scan reports no outdated components.
+func (im *IssueManager) CloseResolvedIssues(ctx context.Context, result *nova.RunResult) error {
+	outdated := outdatedFingerprints(im.config, result)
+	var errs []error
+	for _, manager := range im.repoManagers() {
+		if err := manager.closeResolved(ctx, outdated); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeResolved closes the open issues of this repository whose fingerprint isn't outdated.
+func (im *IssueManager) closeResolved(ctx context.Context, outdated map[string]bool) error {
+	issues, err := im.openNovaIssues(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, issue := range issues {
+		fingerprint := issueFingerprint(issue.GetBody())
+		kind, ok := resolvableKind(im.config, fingerprint)
+		if !ok || outdated[fingerprint] || im.isSuppressed(issue) {
+			continue
+		}
+		if err := im.closeIssue(ctx, kind, issue, resolvedReason, resolvedComment); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// repoManagers returns the issue managers of the default and all routed repositories.
+func (im *IssueManager) repoManagers() []*IssueManager {
+	namespaces := make([]string, 0, len(im.config.RepoRouting))
+	for namespace := range im.config.RepoRouting {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	managers := []*IssueManager{im}
+	for _, namespace := range namespaces {
+		if manager := im.forNamespaces(namespace); !slices.Contains(managers, manager) {
+			managers = append(managers, manager)
+		}
+	}
+	return managers
+}
+
+// outdatedFingerprints returns the fingerprints of every issue the outdated components of
+// result are tracked by: per release, per image and per workload, and per namespace, whatever
+// the configured grouping, so switching it doesn't close the issues of outdated components.
+func outdatedFingerprints(cfg *config.Config, result *nova.RunResult) map[string]bool {
+	fingerprints := make(map[string]bool)
+	for _, c := range result.Contexts {
+		var releases []nova.ReleaseOutput
+		var containers []nova.ContainerOutput
+		if c.Helm != nil {
+			releases = c.Helm.Outdated
+		}
+		if c.Containers != nil {
+			containers = c.Containers.Outdated
+		}
+
+		for _, release := range releases {
+			fingerprints[helmFingerprint(cfg, release)] = true
+		}
+		for _, container := range containers {
+			// The fingerprints of containerFingerprint with either containerIssueGranularity
+			image := fingerprintKind(cfg, "container", container.Context) + ":" + container.Name
+			fingerprints[image] = true
+			for _, w := range container.AffectedWorkloads {
+				fingerprints[image+":"+workloadKey(w)] = true
+			}
+		}
+		for _, group := range GroupByNamespace(releases, containers) {
+			fingerprints[namespaceFingerprint(cfg, group)] = true
+		}
+	}
+	return fingerprints
+}
+
+// resolvableKind returns the issue type of a fingerprint of this deployment whose issue
+// closeResolved may close, or false for other deployments' fingerprints, summary issues and
+// issue types of scans that didn't run.
+func resolvableKind(cfg *config.Config, fingerprint string) (string, bool) {
+	rest, ok := strings.CutPrefix(fingerprint, fingerprintScope(cfg))
+	if !ok {
+		return "", false
+	}
+	kinds := map[string]bool{
+		"helm":      cfg.ScanHelm,
+		"container": cfg.ScanContainers,
+		"namespace": cfg.IsGroupedByNamespace(),
+	}
+	for kind, enabled := range kinds {
+		if enabled && (strings.HasPrefix(rest, kind+":") || strings.HasPrefix(rest, kind+"@")) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// issueFingerprint returns the fingerprint of an issue body, or "" if it has no marker.
+func issueFingerprint(body string) string {
+	if match := fingerprintMarkerPattern.FindStringSubmatch(body); match != nil {
+		return match[1]
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+var resolvedRelease = nova.ReleaseOutput{
+	ReleaseName: "api",
+	ChartName:   "api-chart",
+	Namespace:   "payments",
+	Installed:   nova.VersionInfo{Version: "1.0.0"},
+	Latest:      nova.VersionInfo{Version: "2.0.0"},
+}
+
+// helmRunResult returns a run result reporting the given releases as outdated.
+func helmRunResult(releases ...nova.ReleaseOutput) *nova.RunResult {
+	return &nova.RunResult{Contexts: []nova.ContextResult{{
+		Helm:       &nova.HelmScanResult{Outdated: releases},
+		Containers: &nova.ContainerScanResult{},
+	}}}
+}
+
+func TestCloseResolvedIssues(t *testing.T) {
+	helmBody := "body" + formatFingerprintMarker(helmFingerprint(&config.Config{}, resolvedRelease))
+
+	tests := []struct {
+		name       string
+		cfg        *config.Config
+		body       string
+		labels     []string
+		result     *nova.RunResult
+		wantClosed bool
+	}{
+		{"resolved release is closed", &config.Config{ScanHelm: true}, helmBody, nil, helmRunResult(), true},
+		{"outdated release stays open", &config.Config{ScanHelm: true}, helmBody, nil, helmRunResult(resolvedRelease), false},
+		{"release outdated in another version stays open", &config.Config{ScanHelm: true}, helmBody, nil,
+			helmRunResult(nova.ReleaseOutput{ReleaseName: "api", ChartName: "api-chart", Namespace: "payments",
+				Installed: nova.VersionInfo{Version: "1.5.0"}, Latest: nova.VersionInfo{Version: "3.0.0"}}), false},
+		{"suppressed issue stays open", &config.Config{ScanHelm: true, SuppressLabel: "nova-ignore"}, helmBody,
+			[]string{"nova-ignore"}, helmRunResult(), false},
+		{"issue of a scan that didn't run stays open", &config.Config{ScanContainers: true}, helmBody, nil, helmRunResult(), false},
+		{"issue of another deployment stays open", &config.Config{ScanHelm: true, IssueTitlePrefix: "[Nova prod]"}, helmBody, nil,
+			helmRunResult(), false},
+		{"issue without a fingerprint stays open", &config.Config{ScanHelm: true}, "legacy body", nil, helmRunResult(), false},
+		{"summary issue stays open", &config.Config{ScanHelm: true, ScanContainers: true, GroupBy: "namespace"},
+			"summary" + formatFingerprintMarker(summaryFingerprintKind+"2024-06-01"), nil, helmRunResult(), false},
+		{"resolved namespace is closed", &config.Config{ScanHelm: true, GroupBy: "namespace"},
+			"body" + formatFingerprintMarker("namespace:payments"), nil, helmRunResult(), true},
+		{"outdated namespace stays open", &config.Config{ScanHelm: true, GroupBy: "namespace"},
+			"body" + formatFingerprintMarker("namespace:payments"), nil, helmRunResult(resolvedRelease), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitHub{existingTitle: "[Nova] Update Helm chart: api (1.0.0 → 2.0.0)", existingBody: tt.body,
+				existingLabels: tt.labels}
+			im := newTestIssueManager(t, tt.cfg, fake)
+
+			if err := im.CloseResolvedIssues(context.Background(), tt.result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if closed := fake.editRequest != nil && fake.editRequest.GetState() == "closed"; closed != tt.wantClosed {
+				t.Errorf("expected closed=%v, got edit request %+v", tt.wantClosed, fake.editRequest)
+			}
+			if tt.wantClosed && !strings.Contains(fake.lastComment, "no longer detects this component as outdated") {
+				t.Errorf("expected a comment explaining the close, got %q", fake.lastComment)
+			}
+		})
+	}
+}
+
+func TestCloseResolvedIssues_WorkloadGranularity(t *testing.T) {
+	container := nova.ContainerOutput{Name: "redis", CurrentTag: "7.0.0", LatestTag: "7.2.0",
+		AffectedWorkloads: []nova.WorkloadOutput{
+			{Name: "cache", Namespace: "apps", Kind: "StatefulSet", Container: "redis"},
+			{Name: "queue", Namespace: "jobs", Kind: "Deployment", Container: "redis"},
+		}}
+	result := &nova.RunResult{Contexts: []nova.ContextResult{{Containers: &nova.ContainerScanResult{
+		Outdated: []nova.ContainerOutput{container}}}}}
+
+	for _, granularity := range []string{"image", "workload"} {
+		cfg := &config.Config{ContainerIssueGranularity: granularity}
+		outdated := outdatedFingerprints(cfg, result)
+		for _, split := range SplitContainerIssues(&config.Config{ContainerIssueGranularity: "workload"}, container) {
+			if fingerprint := containerFingerprint(&config.Config{ContainerIssueGranularity: "workload"}, split); !outdated[fingerprint] {
+				t.Errorf("granularity %s: expected workload fingerprint %s to be outdated", granularity, fingerprint)
+			}
+		}
+		if fingerprint := containerFingerprint(cfg, container); !outdated[fingerprint] {
+			t.Errorf("granularity %s: expected image fingerprint %s to be outdated", granularity, fingerprint)
+		}
+	}
+}
+
+func TestCloseResolvedIssues_DryRun(t *testing.T) {
+	fake := &fakeGitHub{
+		existingTitle: "[Nova] Update Helm chart: api (1.0.0 → 2.0.0)",
+		existingBody:  "body" + formatFingerprintMarker(helmFingerprint(&config.Config{}, resolvedRelease)),
+	}
+	im := newTestIssueManager(t, &config.Config{ScanHelm: true, DryRun: true}, fake)
+	var buf bytes.Buffer
+	im.logger = logging.NewLoggerWithWriter("info", "json", &buf)
+
+	if err := im.CloseResolvedIssues(context.Background(), helmRunResult()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.edited != 0 || fake.commented != 0 {
+		t.Errorf("expected no close in dry-run mode, got %d edits, %d comments", fake.edited, fake.commented)
+	}
+
+	events := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if name, _ := event["event"].(string); name != "" {
+			events[name] = event
+		}
+	}
+	wouldClose := events["issue_would_close"]
+	if wouldClose == nil || wouldClose["title"] != fake.existingTitle || wouldClose["issue_number"] != float64(7) ||
+		wouldClose["reason"] != resolvedReason || wouldClose["issue_type"] != "helm" {
+		t.Errorf("unexpected issue_would_close event %v", wouldClose)
+	}
+	if events["issue_close_dry_run"] == nil {
+		t.Errorf("expected the issue_close_dry_run event as well, got:\n%s", buf.String())
+	}
+}
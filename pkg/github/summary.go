@@ -0,0 +1,190 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/tracing"
+)
+
+const (
+	// summaryIssueType is the issue type of the per-run summary issue.
+	summaryIssueType = "summary"
+	// summaryFingerprintKind prefixes the fingerprint of summary issues, which is qualified by date.
+	summaryFingerprintKind = "summary:"
+)
+
+// summaryGroups lists the component issue types in the order they appear in the summary body.
+var summaryGroups = []struct {
+	issueType string
+	heading   string
+}{
+	{"helm", "Helm Releases"},
+	{"container", "Container Images"},
+	{"namespace", "Namespaces"},
+}
+
+// CreatedIssue is a component issue created during a scan run.
+type CreatedIssue struct {
+	Type  string
+	Title string
+	URL   string
+}
+
+// CreatedIssues returns the component issues created so far in this run, in all repositories.
+func (im *IssueManager) CreatedIssues() []CreatedIssue {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	created := append([]CreatedIssue{}, im.created...)
+	keys := make([]string, 0, len(im.routed))
+	for key := range im.routed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		routed := im.routed[key]
+		routed.mu.Lock()
+		created = append(created, routed.created...)
+		routed.mu.Unlock()
+	}
+	return created
+}
+
+// CreateSummaryIssue opens a summary issue linking the component issues created in this run
+// and closes the summaries of previous days. Summaries are deduplicated by date: a later run
+// on the same day comments its newly created issues on that day's summary instead.
+// Returns the issue URL if created, empty string otherwise.
+func (im *IssueManager) CreateSummaryIssue(ctx context.Context, now time.Time) (issueURL string, err error) {
+	title := FormatSummaryIssueTitle(im.config.TitlePrefix(), now)
+	ctx, span := startIssueSpan(ctx, summaryIssueType, title)
+	defer func() { tracing.End(span, err) }()
+
+	issues, err := im.openNovaIssues(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	fingerprint := summaryFingerprint(im.config, now)
+	current := matchIssue(issues, title, fingerprint)
+	if current != nil {
+		return "", im.appendToSummary(ctx, current, title)
+	}
+
+	var previous []*github.Issue
+	for _, issue := range issues {
+		if isSummaryIssue(im.config, issue) {
+			previous = append(previous, issue)
+		}
+	}
+
+	issueURL, err = im.createIssue(ctx, summaryIssueType, title,
+		truncateIssueBody(FormatSummaryIssueBody(im.CreatedIssues()))+formatFingerprintMarker(fingerprint),
+		issueLabels(im.config, labelSummary), nil)
+	if err != nil {
+		return "", err
+	}
+
+	for _, issue := range previous {
+		if err := im.closeIssue(ctx, summaryIssueType, issue, "superseded by a newer summary", "Superseded by "+issueURL); err != nil {
+			return issueURL, err
+		}
+	}
+	return issueURL, nil
+}
+
+// appendToSummary comments the issues created in this run on today's existing summary issue.
+func (im *IssueManager) appendToSummary(ctx context.Context, summary *github.Issue, title string) error {
+	created := im.CreatedIssues()
+	if len(created) == 0 {
+		im.logger.IssueSkipped(summaryIssueType, title, "no new issues")
+		return nil
+	}
+	if im.dryRun {
+		im.logger.IssueUpdateDryRun(summaryIssueType, title, summary.GetNumber())
+		return nil
+	}
+
+	comment := "Issues created by a later scan today:\n\n" + formatCreatedIssueList(created)
+	if err := im.addComment(ctx, summary.GetNumber(), comment); err != nil {
+		return err
+	}
+	im.logger.IssueUpdated(summaryIssueType, title, summary.GetHTMLURL())
+	return nil
+}
+
+// closeIssue closes an issue with an explanatory comment. In dry-run mode it only logs the
+// issue it would close and the reason.
+func (im *IssueManager) closeIssue(ctx context.Context, issueType string, issue *github.Issue, reason, comment string) error {
+	if im.dryRun {
+		im.logger.IssueCloseDryRun(issueType, issue.GetTitle(), issue.GetNumber())
+		im.logger.IssueWouldClose(issueType, issue.GetTitle(), issue.GetNumber(), reason)
+		return nil
+	}
+
+	closed, err := im.editIssue(ctx, issue.GetNumber(), &github.IssueRequest{
+		State: github.String("closed"),
+	}, comment)
+	if err != nil {
+		return err
+	}
+
+	im.logger.IssueClosed(issueType, issue.GetTitle(), closed.GetHTMLURL())
+	return nil
+}
+
+// isSummaryIssue reports whether an issue is a summary issue of this deployment, whatever its date.
+func isSummaryIssue(cfg *config.Config, issue *github.Issue) bool {
+	return strings.Contains(issue.GetBody(), fingerprintPrefix+fingerprintScope(cfg)+summaryFingerprintKind)
+}
+
+// summaryFingerprint returns the dedup fingerprint of the summary issue for the given day.
+func summaryFingerprint(cfg *config.Config, now time.Time) string {
+	return fingerprintScope(cfg) + summaryFingerprintKind + now.UTC().Format(time.DateOnly)
+}
+
+// FormatSummaryIssueTitle generates the title of the summary issue for the given day, starting with prefix.
+func FormatSummaryIssueTitle(prefix string, now time.Time) string {
+	return prefix + " Scan summary " + now.UTC().Format(time.DateOnly)
+}
+
+// FormatSummaryIssueBody generates the summary issue body listing the created issues grouped by type.
+func FormatSummaryIssueBody(created []CreatedIssue) string {
+	list := "_No new issues were created in this scan._\n\n"
+	if len(created) > 0 {
+		list = formatCreatedIssueList(created)
+	}
+
+	return fmt.Sprintf(`## Nova Scan Summary
+
+%s---
+*This issue was automatically created by nova-scanner*
+`, list)
+}
+
+// formatCreatedIssueList renders the created issues as markdown lists, one section per issue type.
+func formatCreatedIssueList(created []CreatedIssue) string {
+	var sb strings.Builder
+	for _, group := range summaryGroups {
+		var items []CreatedIssue
+		for _, issue := range created {
+			if issue.Type == group.issueType {
+				items = append(items, issue)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "### %s (%d)\n\n", group.heading, len(items))
+		for _, issue := range items {
+			fmt.Fprintf(&sb, "- [%s](%s)\n", issue.Title, issue.URL)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
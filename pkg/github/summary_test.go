@@ -0,0 +1,170 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func TestFormatSummaryIssueBody_GroupsByType(t *testing.T) {
+	body := FormatSummaryIssueBody([]CreatedIssue{
+		{Type: "container", Title: "[Nova] Update container image: nginx (1.0 → 1.1)", URL: "https://github.com/owner/repo/issues/3"},
+		{Type: "helm", Title: "[Nova] Update Helm chart: app (1.0.0 → 2.0.0)", URL: "https://github.com/owner/repo/issues/1"},
+		{Type: "helm", Title: "[Nova] Update Helm chart: db (1.0.0 → 1.1.0)", URL: "https://github.com/owner/repo/issues/2"},
+	})
+
+	for _, want := range []string{
+		"### Helm Releases (2)",
+		"- [[Nova] Update Helm chart: app (1.0.0 → 2.0.0)](https://github.com/owner/repo/issues/1)",
+		"- [[Nova] Update Helm chart: db (1.0.0 → 1.1.0)](https://github.com/owner/repo/issues/2)",
+		"### Container Images (1)",
+		"- [[Nova] Update container image: nginx (1.0 → 1.1)](https://github.com/owner/repo/issues/3)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected body to contain %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Index(body, "### Helm Releases") > strings.Index(body, "### Container Images") {
+		t.Errorf("expected Helm releases before container images, got:\n%s", body)
+	}
+	if strings.Contains(body, "### Namespaces") {
+		t.Errorf("expected no section for types without created issues, got:\n%s", body)
+	}
+}
+
+func TestFormatSummaryIssueBody_NoIssues(t *testing.T) {
+	body := FormatSummaryIssueBody(nil)
+	if !strings.Contains(body, "No new issues were created") {
+		t.Errorf("expected empty summary note, got:\n%s", body)
+	}
+}
+
+func TestCreateSummaryIssue_ClosesPreviousSummary(t *testing.T) {
+	fake := &fakeGitHub{
+		existingTitle: FormatSummaryIssueTitle("[Nova]", time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)),
+		existingBody:  "## Nova Scan Summary\n" + formatFingerprintMarker("summary:2024-06-01"),
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+	ctx := context.Background()
+
+	release := nova.ReleaseOutput{
+		ReleaseName: "my-release",
+		Namespace:   "default",
+		ChartName:   "my-chart",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "2.0.0"},
+	}
+	if _, err := im.CreateHelmIssue(ctx, release); err != nil {
+		t.Fatalf("CreateHelmIssue() error = %v", err)
+	}
+
+	url, err := im.CreateSummaryIssue(ctx, time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("CreateSummaryIssue() error = %v", err)
+	}
+	if url == "" {
+		t.Fatal("expected summary issue to be created")
+	}
+	if fake.created != 2 {
+		t.Errorf("expected helm and summary issues to be created, got %d", fake.created)
+	}
+	if !strings.Contains(fake.lastCreatedBody, FormatHelmIssueTitle("[Nova]", release)) {
+		t.Errorf("expected summary to link the helm issue, got:\n%s", fake.lastCreatedBody)
+	}
+	if !strings.Contains(fake.lastCreatedBody, "nova-scanner:fingerprint=summary:2024-06-02") {
+		t.Errorf("expected dated summary fingerprint, got:\n%s", fake.lastCreatedBody)
+	}
+	if !slices.Contains(fake.createdLabels, labelSummary) {
+		t.Errorf("expected %s label, got %v", labelSummary, fake.createdLabels)
+	}
+
+	if fake.editRequest == nil || fake.editRequest.GetState() != "closed" {
+		t.Fatalf("expected previous summary to be closed, got %+v", fake.editRequest)
+	}
+	if !strings.Contains(fake.lastComment, "Superseded by "+url) {
+		t.Errorf("expected superseded comment, got %q", fake.lastComment)
+	}
+}
+
+func TestCreateSummaryIssue_DryRunLogsWouldClose(t *testing.T) {
+	fake := &fakeGitHub{
+		existingTitle: FormatSummaryIssueTitle("[Nova]", time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)),
+		existingBody:  "## Nova Scan Summary\n" + formatFingerprintMarker("summary:2024-06-01"),
+	}
+	im := newTestIssueManager(t, &config.Config{DryRun: true}, fake)
+	var buf bytes.Buffer
+	im.logger = logging.NewLoggerWithWriter("info", "json", &buf)
+
+	if _, err := im.CreateSummaryIssue(context.Background(), time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("CreateSummaryIssue() error = %v", err)
+	}
+	if fake.edited != 0 || fake.commented != 0 || fake.editRequest != nil {
+		t.Errorf("expected no close in dry-run mode, got %d edits, %d comments", fake.edited, fake.commented)
+	}
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if event["event"] == "issue_would_close" {
+			events = append(events, event)
+		}
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one issue_would_close event, got %d:\n%s", len(events), buf.String())
+	}
+	event := events[0]
+	if event["title"] != fake.existingTitle || event["issue_number"] != float64(7) || event["reason"] != "superseded by a newer summary" {
+		t.Errorf("unexpected issue_would_close event %v", event)
+	}
+}
+
+func TestCreateSummaryIssue_SameDayCommentsOnExistingSummary(t *testing.T) {
+	now := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	fake := &fakeGitHub{
+		existingTitle: FormatSummaryIssueTitle("[Nova]", now),
+		existingBody:  "## Nova Scan Summary\n" + formatFingerprintMarker(summaryFingerprint(&config.Config{}, now)),
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+	im.created = []CreatedIssue{{Type: "container", Title: "nginx", URL: "https://github.com/owner/repo/issues/8"}}
+
+	url, err := im.CreateSummaryIssue(context.Background(), now)
+	if err != nil {
+		t.Fatalf("CreateSummaryIssue() error = %v", err)
+	}
+	if url != "" || fake.created != 0 {
+		t.Errorf("expected no new summary on the same day, got url %q and %d created", url, fake.created)
+	}
+	if fake.edited != 0 {
+		t.Errorf("expected today's summary to stay open, got %d edits", fake.edited)
+	}
+	if fake.commented != 1 || !strings.Contains(fake.lastComment, "[nginx](https://github.com/owner/repo/issues/8)") {
+		t.Errorf("expected a comment listing the new issue, got %d comments: %q", fake.commented, fake.lastComment)
+	}
+}
+
+func TestCreateSummaryIssue_SameDayWithoutNewIssuesSkips(t *testing.T) {
+	now := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	fake := &fakeGitHub{
+		existingTitle: FormatSummaryIssueTitle("[Nova]", now),
+		existingBody:  formatFingerprintMarker(summaryFingerprint(&config.Config{}, now)),
+	}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateSummaryIssue(context.Background(), now); err != nil {
+		t.Fatalf("CreateSummaryIssue() error = %v", err)
+	}
+	if fake.created != 0 || fake.commented != 0 || fake.edited != 0 {
+		t.Errorf("expected no GitHub writes, got %d created, %d comments, %d edits", fake.created, fake.commented, fake.edited)
+	}
+}
@@ -0,0 +1,76 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// issueTemplateFuncs returns the helper functions available to custom issue body templates.
+// workloadTable lists at most maxWorkloadRows workloads.
+func issueTemplateFuncs(cfg *config.Config) template.FuncMap {
+	return template.FuncMap{
+		"backtick":   backtick,
+		"contextRow": formatContextRow,
+		"workloadTable": func(workloads []nova.WorkloadOutput) string {
+			return formatWorkloadTable(workloads, cfg.MaxWorkloadRows)
+		},
+		"yesNo": func(b bool) string {
+			if b {
+				return "Yes"
+			}
+			return "No"
+		},
+	}
+}
+
+// RenderHelmIssueBody renders the issue body for a Helm release with the configured
+// helmIssueTemplate, or the built-in body for the configured GitOps tool when none is set.
+// Bodies over the maximum issue body length are truncated.
+func RenderHelmIssueBody(cfg *config.Config, release nova.ReleaseOutput) (string, error) {
+	if cfg.HelmIssueTemplate == "" {
+		return truncateIssueBody(formatHelmIssueBody(release, cfg.GitOpsTool)), nil
+	}
+	return renderIssueTemplate(cfg, "helmIssueTemplate", cfg.HelmIssueTemplate, release)
+}
+
+// RenderContainerIssueBody renders the issue body for a container image with the configured
+// containerIssueTemplate, or the built-in body when none is set.
+// Bodies over the maximum issue body length are truncated.
+func RenderContainerIssueBody(cfg *config.Config, container nova.ContainerOutput) (string, error) {
+	if cfg.ContainerIssueTemplate == "" {
+		return truncateIssueBody(FormatContainerIssueBody(container, cfg.MaxWorkloadRows)), nil
+	}
+	return renderIssueTemplate(cfg, "containerIssueTemplate", cfg.ContainerIssueTemplate, container)
+}
+
+// ValidateIssueTemplates parses the configured issue body templates with the functions they can
+// call, so a syntax error or an unknown function fails the config rather than the first issue.
+func ValidateIssueTemplates(cfg *config.Config) error {
+	for _, tmpl := range []struct{ name, text string }{
+		{"helmIssueTemplate", cfg.HelmIssueTemplate},
+		{"containerIssueTemplate", cfg.ContainerIssueTemplate},
+	} {
+		if _, err := template.New(tmpl.name).Funcs(issueTemplateFuncs(cfg)).Parse(tmpl.text); err != nil {
+			return fmt.Errorf("invalid %s: %w", tmpl.name, err)
+		}
+	}
+	return nil
+}
+
+// renderIssueTemplate executes an issue body template against a release or container.
+func renderIssueTemplate(cfg *config.Config, name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(issueTemplateFuncs(cfg)).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return truncateIssueBody(sb.String()), nil
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func TestRenderHelmIssueBody_Template(t *testing.T) {
+	cfg := &config.Config{HelmIssueTemplate: "Upgrade {{ backtick .ReleaseName }} in {{ .Namespace }} " +
+		"from {{ .Installed.Version }} to {{ .Latest.Version }} (deprecated: {{ yesNo .Deprecated }})\n" +
+		"argocd app sync {{ .ReleaseName }}"}
+	release := nova.ReleaseOutput{
+		ReleaseName: "cert-manager",
+		Namespace:   "infra",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "1.5.0"},
+		Deprecated:  true,
+	}
+
+	body, err := RenderHelmIssueBody(cfg, release)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Upgrade `cert-manager` in infra from 1.0.0 to 1.5.0 (deprecated: Yes)\nargocd app sync cert-manager"
+	if body != want {
+		t.Errorf("RenderHelmIssueBody() = %q, want %q", body, want)
+	}
+}
+
+func TestRenderContainerIssueBody_Template(t *testing.T) {
+	cfg := &config.Config{ContainerIssueTemplate: "{{ .Name }}:{{ .LatestTag }}\n{{ workloadTable .AffectedWorkloads }}"}
+	container := nova.ContainerOutput{
+		Name:      "redis",
+		LatestTag: "7.2.0",
+		AffectedWorkloads: []nova.WorkloadOutput{
+			{Name: "cache", Namespace: "apps", Kind: "StatefulSet", Container: "redis"},
+		},
+	}
+
+	body, err := RenderContainerIssueBody(cfg, container)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, exp := range []string{"redis:7.2.0", "| cache | apps | StatefulSet | redis |"} {
+		if !strings.Contains(body, exp) {
+			t.Errorf("body should contain %q, got %q", exp, body)
+		}
+	}
+}
+
+func TestRenderIssueBody_BuiltIn(t *testing.T) {
+	release := nova.ReleaseOutput{ReleaseName: "api", Namespace: "apps"}
+	body, err := RenderHelmIssueBody(&config.Config{}, release)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body != FormatHelmIssueBody(release) {
+		t.Error("expected the built-in body without a template")
+	}
+}
+
+func TestRenderIssueBody_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"unknown function", "{{ shout .ReleaseName }}"},
+		{"unknown field", "{{ .Missing }}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{HelmIssueTemplate: tt.template}
+			if _, err := RenderHelmIssueBody(cfg, nova.ReleaseOutput{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestValidateIssueTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		wantErr string
+	}{
+		{"no templates", &config.Config{}, ""},
+		{"helper functions", &config.Config{HelmIssueTemplate: "{{ backtick .ReleaseName }} {{ contextRow .Context }}",
+			ContainerIssueTemplate: "{{ workloadTable .AffectedWorkloads }} {{ yesNo .DigestPinned }}"}, ""},
+		{"invalid syntax", &config.Config{ContainerIssueTemplate: "{{ .Name"}, "invalid containerIssueTemplate"},
+		{"unknown function", &config.Config{HelmIssueTemplate: "{{ upper .ReleaseName }}"},
+			`invalid helmIssueTemplate: template: helmIssueTemplate:1: function "upper" not defined`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIssueTemplates(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreateHelmIssue_Template(t *testing.T) {
+	fake := &fakeGitHub{}
+	im := newTestIssueManager(t, &config.Config{HelmIssueTemplate: "Sync {{ .ReleaseName }} with Argo CD"}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), nova.ReleaseOutput{ReleaseName: "api"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(fake.lastCreatedBody, "Sync api with Argo CD") {
+		t.Errorf("expected the templated body, got %q", fake.lastCreatedBody)
+	}
+	if !strings.Contains(fake.lastCreatedBody, fingerprintPrefix) {
+		t.Error("expected the fingerprint marker after the templated body")
+	}
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"fmt"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// SplitContainerIssues returns the containers to file issues for: the container itself, or with
+// containerIssueGranularity "workload" one copy per affected workload, limited to that workload.
+// Containers without workload information are not split.
+func SplitContainerIssues(cfg *config.Config, container nova.ContainerOutput) []nova.ContainerOutput {
+	if !cfg.IsContainerIssuePerWorkload() || len(container.AffectedWorkloads) == 0 {
+		return []nova.ContainerOutput{container}
+	}
+
+	// A workload running the image in several containers keeps all of them in its issue
+	var keys []string
+	byWorkload := make(map[string][]nova.WorkloadOutput)
+	for _, w := range container.AffectedWorkloads {
+		key := workloadKey(w)
+		if byWorkload[key] == nil {
+			keys = append(keys, key)
+		}
+		byWorkload[key] = append(byWorkload[key], w)
+	}
+
+	split := make([]nova.ContainerOutput, 0, len(keys))
+	for _, key := range keys {
+		scoped := container
+		scoped.AffectedWorkloads = byWorkload[key]
+		split = append(split, scoped)
+	}
+	return split
+}
+
+// ContainerIssueTitle returns the issue title for a container, naming the workload when the
+// issue is filed per workload.
+func ContainerIssueTitle(cfg *config.Config, container nova.ContainerOutput) string {
+	w, ok := issueWorkload(cfg, container)
+	if !ok {
+		return FormatContainerIssueTitle(cfg.TitlePrefix(), container)
+	}
+	scoped := container
+	scoped.Name = fmt.Sprintf("%s in %s/%s", container.Name, w.Namespace, w.Name)
+	return FormatContainerIssueTitle(cfg.TitlePrefix(), scoped)
+}
+
+// issueWorkload returns the workload a container issue is filed for with containerIssueGranularity
+// "workload", or false if the issue covers the image.
+func issueWorkload(cfg *config.Config, container nova.ContainerOutput) (nova.WorkloadOutput, bool) {
+	if !cfg.IsContainerIssuePerWorkload() || len(container.AffectedWorkloads) == 0 {
+		return nova.WorkloadOutput{}, false
+	}
+	key := workloadKey(container.AffectedWorkloads[0])
+	for _, w := range container.AffectedWorkloads[1:] {
+		if workloadKey(w) != key {
+			return nova.WorkloadOutput{}, false
+		}
+	}
+	return container.AffectedWorkloads[0], true
+}
+
+// workloadKey identifies a workload by namespace, kind and name.
+func workloadKey(w nova.WorkloadOutput) string {
+	return w.Namespace + "/" + w.Kind + "/" + w.Name
+}
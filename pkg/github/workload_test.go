@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+var sharedImage = nova.ContainerOutput{
+	Name:       "nginx",
+	CurrentTag: "1.20",
+	LatestTag:  "1.25",
+	AffectedWorkloads: []nova.WorkloadOutput{
+		{Name: "web", Namespace: "team-a", Kind: "Deployment", Container: "nginx"},
+		{Name: "web", Namespace: "team-a", Kind: "Deployment", Container: "sidecar"},
+		{Name: "proxy", Namespace: "team-b", Kind: "DaemonSet", Container: "nginx"},
+	},
+}
+
+func TestSplitContainerIssues_Image(t *testing.T) {
+	cfg := &config.Config{ContainerIssueGranularity: "image"}
+
+	split := SplitContainerIssues(cfg, sharedImage)
+	if len(split) != 1 || len(split[0].AffectedWorkloads) != 3 {
+		t.Fatalf("expected the image unsplit, got %v", split)
+	}
+	if got, want := ContainerIssueTitle(cfg, split[0]), "[Nova] Update container image: nginx (1.20 → 1.25)"; got != want {
+		t.Errorf("title = %q, want %q", got, want)
+	}
+	if got := containerFingerprint(cfg, split[0]); got != "container:nginx" {
+		t.Errorf("fingerprint = %q, want container:nginx", got)
+	}
+}
+
+func TestSplitContainerIssues_Workload(t *testing.T) {
+	cfg := &config.Config{ContainerIssueGranularity: "workload"}
+
+	split := SplitContainerIssues(cfg, sharedImage)
+	if len(split) != 2 {
+		t.Fatalf("expected one container per workload, got %d", len(split))
+	}
+	// Both containers of team-a/web stay in its issue
+	if len(split[0].AffectedWorkloads) != 2 || len(split[1].AffectedWorkloads) != 1 {
+		t.Errorf("expected 2 and 1 workload entries, got %v and %v", split[0].AffectedWorkloads, split[1].AffectedWorkloads)
+	}
+
+	var titles, fingerprints []string
+	for _, container := range split {
+		titles = append(titles, ContainerIssueTitle(cfg, container))
+		fingerprints = append(fingerprints, containerFingerprint(cfg, container))
+	}
+	wantTitles := []string{
+		"[Nova] Update container image: nginx in team-a/web (1.20 → 1.25)",
+		"[Nova] Update container image: nginx in team-b/proxy (1.20 → 1.25)",
+	}
+	if !slices.Equal(titles, wantTitles) {
+		t.Errorf("titles = %v, want %v", titles, wantTitles)
+	}
+	wantFingerprints := []string{"container:nginx:team-a/Deployment/web", "container:nginx:team-b/DaemonSet/proxy"}
+	if !slices.Equal(fingerprints, wantFingerprints) {
+		t.Errorf("fingerprints = %v, want %v", fingerprints, wantFingerprints)
+	}
+
+	// Images without workload information get a single image issue
+	bare := nova.ContainerOutput{Name: "redis", CurrentTag: "6", LatestTag: "7"}
+	if split := SplitContainerIssues(cfg, bare); len(split) != 1 || containerFingerprint(cfg, split[0]) != "container:redis" {
+		t.Errorf("expected an unsplit image issue, got %v", split)
+	}
+}
+
+func TestCreateContainerIssue_PerWorkload(t *testing.T) {
+	fake := &fakeGitHub{}
+	cfg := &config.Config{ContainerIssueGranularity: "workload"}
+	im := newTestIssueManager(t, cfg, fake)
+
+	for _, container := range SplitContainerIssues(cfg, sharedImage) {
+		if _, err := im.CreateContainerIssue(context.Background(), container); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if fake.created != 2 {
+		t.Fatalf("expected one issue per workload, got %d", fake.created)
+	}
+	if want := "[Nova] Update container image: nginx in team-b/proxy (1.20 → 1.25)"; fake.lastCreatedTitle != want {
+		t.Errorf("title = %q, want %q", fake.lastCreatedTitle, want)
+	}
+	if !HasFingerprint(fake.lastCreatedBody, "container:nginx:team-b/DaemonSet/proxy") {
+		t.Errorf("expected the workload fingerprint in the body, got %q", fake.lastCreatedBody)
+	}
+}
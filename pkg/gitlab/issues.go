@@ -0,0 +1,508 @@
+// Package gitlab files the issues of outdated components into a GitLab project, as an
+// alternative to the GitHub issue backend. Titles, bodies, labels and dedup fingerprints are
+// shared with the github package, so both backends report components the same way.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/github"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/httpclient"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// labelNovaScan is always applied by the shared issue labels; dedup only considers issues carrying it.
+	labelNovaScan = "nova-scan"
+
+	// requestTimeout bounds a single GitLab API request.
+	requestTimeout = 30 * time.Second
+
+	// maxRateLimitRetries bounds how often a rate-limited GitLab request is retried.
+	maxRateLimitRetries = 3
+	// defaultRetryBackoff is the wait before the first retry of a transient failure; it doubles per retry.
+	defaultRetryBackoff = time.Second
+	// defaultRateLimitWait is the wait after a 429 response without a Retry-After header.
+	defaultRateLimitWait = time.Minute
+)
+
+// errSummaryUnsupported and errCloseResolvedUnsupported are returned by CreateSummaryIssue and
+// CloseResolvedIssues; config validation rejects createSummaryIssue and closeResolved with the
+// GitLab backend.
+var (
+	errSummaryUnsupported       = errors.New("summary issues are not supported by the gitlab issue backend")
+	errCloseResolvedUnsupported = errors.New("closing resolved issues is not supported by the gitlab issue backend")
+)
+
+// Issue is the subset of a GitLab issue used for deduplication.
+type Issue struct {
+	IID         int      `json:"iid"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	WebURL      string   `json:"web_url"`
+}
+
+// IssueManager handles GitLab issue creation and deduplication.
+// It is safe for concurrent use; issues with the same fingerprint are deduplicated one at a time.
+type IssueManager struct {
+	client  *http.Client
+	baseURL string // API root, e.g. https://gitlab.com/api/v4
+	token   string
+	project string
+	config  *config.Config
+	dryRun  bool
+	logger  *logging.Logger
+	// retryBackoff is the wait before the first retry of a transient failure.
+	retryBackoff time.Duration
+
+	// mu guards the cache and counter below.
+	mu sync.Mutex
+	// inflight holds a lock per fingerprint, so concurrent creates of the same component
+	// don't both miss the cache and create duplicate issues.
+	inflight map[string]*sync.Mutex
+
+	// openIssues caches the open nova-scan issues for dedup; nil until first listed.
+	openIssues []*Issue
+	// suppressedIssues caches the open and closed nova-scan issues carrying the suppress
+	// label; nil until first listed.
+	suppressedIssues []*Issue
+	// skipped counts issues not created because an identical one is already open or suppressed.
+	skipped int
+}
+
+// NewIssueManager creates a new IssueManager for the configured GitLab project.
+func NewIssueManager(cfg *config.Config, logger *logging.Logger) *IssueManager {
+	return &IssueManager{
+		client:  &http.Client{Timeout: requestTimeout, Transport: httpclient.FromConfig(cfg)},
+		baseURL: strings.TrimSuffix(cfg.GitLabURL, "/") + "/api/v4",
+		token:   cfg.GitLabToken,
+		project: cfg.GitLabProjectID,
+		config:  cfg,
+		dryRun:  cfg.DryRun,
+		logger:  logger.WithComponent("gitlab"),
+
+		retryBackoff: defaultRetryBackoff,
+	}
+}
+
+// CreateHelmIssue creates a GitLab issue for an outdated Helm release.
+// Returns the issue URL if created, empty string if skipped or updated.
+func (im *IssueManager) CreateHelmIssue(ctx context.Context, release nova.ReleaseOutput) (string, error) {
+	content, err := github.HelmIssueContent(im.config, release)
+	if err != nil {
+		return "", err
+	}
+	return im.ensureIssue(ctx, "helm", content)
+}
+
+// CreateContainerIssue creates a GitLab issue for an outdated container image.
+// Returns the issue URL if created, empty string if skipped or updated.
+func (im *IssueManager) CreateContainerIssue(ctx context.Context, container nova.ContainerOutput) (string, error) {
+	content, err := github.ContainerIssueContent(im.config, container)
+	if err != nil {
+		return "", err
+	}
+	return im.ensureIssue(ctx, "container", content)
+}
+
+// CreateNamespaceIssue creates a GitLab issue covering all outdated components in a namespace.
+// Returns the issue URL if created, empty string if skipped or updated.
+func (im *IssueManager) CreateNamespaceIssue(ctx context.Context, group github.NamespaceGroup) (string, error) {
+	return im.ensureIssue(ctx, "namespace", github.NamespaceIssueContent(im.config, group))
+}
+
+// CreateSummaryIssue is not supported by the GitLab backend.
+func (im *IssueManager) CreateSummaryIssue(ctx context.Context, now time.Time) (string, error) {
+	return "", errSummaryUnsupported
+}
+
+// CloseResolvedIssues is not supported by the GitLab backend.
+func (im *IssueManager) CloseResolvedIssues(ctx context.Context, result *nova.RunResult) error {
+	return errCloseResolvedUnsupported
+}
+
+// SkippedIssues returns the number of issues skipped as duplicates of open issues or as suppressed.
+func (im *IssueManager) SkippedIssues() int {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	return im.skipped
+}
+
+// RateLimit reports no quota: the rate limit metrics describe GitHub's API.
+func (im *IssueManager) RateLimit() (remaining, limit int, ok bool) {
+	return 0, 0, false
+}
+
+// ensureIssue creates the issue unless an open issue with its fingerprint exists. An existing
+// issue is updated in place when it is out of date (see github.IssueContent.Update), and left
+// alone when it carries the suppress label, even if it was closed.
+func (im *IssueManager) ensureIssue(ctx context.Context, issueType string, content github.IssueContent) (issueURL string, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "gitlab.CreateIssue", trace.WithAttributes(
+		attribute.String("issue.type", issueType),
+		attribute.String("issue.title", content.Title),
+	))
+	defer func() { tracing.End(span, err) }()
+	defer im.lockFingerprint(content.Fingerprint)()
+
+	existing, err := im.findExistingIssue(ctx, content)
+	if err != nil {
+		return "", fmt.Errorf("failed to check existing issues: %w", err)
+	}
+	if existing == nil {
+		return im.createIssue(ctx, issueType, content)
+	}
+	if im.config.SuppressLabel != "" && slices.Contains(existing.Labels, im.config.SuppressLabel) {
+		im.skip()
+		im.logger.IssueSuppressed(issueType, content.Title, existing.IID, im.config.SuppressLabel)
+		return "", nil
+	}
+	if update, comment := content.Update(existing.Title, existing.Description); update {
+		return "", im.updateIssue(ctx, issueType, existing, content, comment)
+	}
+	im.skip()
+	im.logger.IssueSkipped(issueType, content.Title, "duplicate")
+	return "", nil
+}
+
+// findExistingIssue returns the issue tracking the content's fingerprint, or nil if none exists.
+// An issue carrying the suppress label, open or closed, takes precedence over open issues.
+func (im *IssueManager) findExistingIssue(ctx context.Context, content github.IssueContent) (*Issue, error) {
+	if im.config.SuppressLabel != "" {
+		suppressed, err := im.suppressedNovaIssues(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if issue := matchIssue(suppressed, content.Title, content.Fingerprint); issue != nil {
+			return issue, nil
+		}
+	}
+
+	issues, err := im.openNovaIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return matchIssue(issues, content.Title, content.Fingerprint), nil
+}
+
+// matchIssue returns the issue carrying the fingerprint marker, falling back to a title match.
+func matchIssue(issues []*Issue, title, fingerprint string) *Issue {
+	for _, issue := range issues {
+		if github.HasFingerprint(issue.Description, fingerprint) {
+			return issue
+		}
+	}
+	for _, issue := range issues {
+		if issue.Title == title {
+			return issue
+		}
+	}
+	return nil
+}
+
+// skip records an issue that was not created because it is open or suppressed.
+func (im *IssueManager) skip() {
+	im.mu.Lock()
+	im.skipped++
+	im.mu.Unlock()
+}
+
+// lockFingerprint waits until no other issue with the fingerprint is being deduplicated or
+// created, and returns the func releasing it.
+func (im *IssueManager) lockFingerprint(fingerprint string) (unlock func()) {
+	im.mu.Lock()
+	lock, ok := im.inflight[fingerprint]
+	if !ok {
+		if im.inflight == nil {
+			im.inflight = make(map[string]*sync.Mutex)
+		}
+		lock = &sync.Mutex{}
+		im.inflight[fingerprint] = lock
+	}
+	im.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// createIssue creates a GitLab issue, respecting dry-run mode.
+func (im *IssueManager) createIssue(ctx context.Context, issueType string, content github.IssueContent) (string, error) {
+	if im.dryRun {
+		im.logger.IssueDryRun(issueType, content.Title)
+		return "", nil
+	}
+
+	var issue Issue
+	err := im.do(ctx, http.MethodPost, im.projectPath("issues"), map[string]string{
+		"title":       content.Title,
+		"description": content.Body,
+		"labels":      strings.Join(content.Labels, ","),
+	}, &issue)
+	if err != nil {
+		return "", fmt.Errorf("failed to create issue: %w", err)
+	}
+	im.rememberIssue(&issue)
+
+	im.logger.IssueCreated(issueType, content.Title, issue.WebURL)
+	return issue.WebURL, nil
+}
+
+// updateIssue refreshes the title and description of an existing issue, commenting on the
+// change unless comment is empty.
+func (im *IssueManager) updateIssue(ctx context.Context, issueType string, existing *Issue, content github.IssueContent, comment string) error {
+	if im.dryRun {
+		im.logger.IssueUpdateDryRun(issueType, content.Title, existing.IID)
+		return nil
+	}
+
+	var issue Issue
+	err := im.do(ctx, http.MethodPut, im.projectPath("issues", strconv.Itoa(existing.IID)), map[string]string{
+		"title":       content.Title,
+		"description": content.Body,
+	}, &issue)
+	if err != nil {
+		return fmt.Errorf("failed to update issue #%d: %w", existing.IID, err)
+	}
+	im.rememberIssue(&issue)
+
+	if comment != "" {
+		err = im.do(ctx, http.MethodPost, im.projectPath("issues", strconv.Itoa(existing.IID), "notes"), map[string]string{
+			"body": comment,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to comment on issue #%d: %w", existing.IID, err)
+		}
+	}
+
+	im.logger.IssueUpdated(issueType, content.Title, issue.WebURL)
+	return nil
+}
+
+// openNovaIssues returns the open nova-scan issues, listing them once per run so dedup
+// doesn't cost a request per outdated component.
+func (im *IssueManager) openNovaIssues(ctx context.Context) ([]*Issue, error) {
+	// Concurrent callers wait for the first listing instead of listing again
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if im.openIssues != nil {
+		return im.openIssues, nil
+	}
+
+	issues, err := im.listIssues(ctx, "opened", labelNovaScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open issues: %w", err)
+	}
+	im.logger.Debug().Int("open_issues", len(issues)).Msg("Listed open nova-scan issues for deduplication")
+	im.openIssues = issues
+	return im.openIssues, nil
+}
+
+// suppressedNovaIssues returns the open and closed nova-scan issues carrying the suppress
+// label, listing them once per run. Closing a suppressed issue keeps the component suppressed.
+func (im *IssueManager) suppressedNovaIssues(ctx context.Context) ([]*Issue, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if im.suppressedIssues != nil {
+		return im.suppressedIssues, nil
+	}
+
+	issues, err := im.listIssues(ctx, "all", labelNovaScan+","+im.config.SuppressLabel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppressed issues: %w", err)
+	}
+	im.logger.Debug().Int("suppressed_issues", len(issues)).Msg("Listed suppressed nova-scan issues for deduplication")
+	im.suppressedIssues = issues
+	return im.suppressedIssues, nil
+}
+
+// listIssues returns all issues of the project in state carrying every one of the
+// comma-separated labels, following the pagination headers.
+func (im *IssueManager) listIssues(ctx context.Context, state, labels string) ([]*Issue, error) {
+	query := url.Values{
+		"state":    {state},
+		"labels":   {labels},
+		"per_page": {"100"},
+	}
+	issues := []*Issue{}
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+		var batch []*Issue
+		resp, err := im.request(ctx, http.MethodGet, im.projectPath("issues")+"?"+query.Encode(), nil, &batch)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, batch...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return issues, nil
+}
+
+// rememberIssue records a created or updated issue in the open issue cache, so later items in
+// the same run dedup against it. The cache is copied rather than modified in place, as
+// concurrent callers may still be matching against it.
+func (im *IssueManager) rememberIssue(issue *Issue) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if im.openIssues == nil {
+		return
+	}
+	issues := slices.Clone(im.openIssues)
+	for i, cached := range issues {
+		if cached.IID == issue.IID {
+			issues[i] = issue
+			im.openIssues = issues
+			return
+		}
+	}
+	im.openIssues = append(issues, issue)
+}
+
+// projectPath returns the API path of a resource of the configured project.
+func (im *IssueManager) projectPath(elem ...string) string {
+	return "/projects/" + url.PathEscape(im.project) + "/" + strings.Join(elem, "/")
+}
+
+// do sends a JSON request to the GitLab API and decodes the response into out, if non-nil.
+func (im *IssueManager) do(ctx context.Context, method, path string, body, out any) error {
+	_, err := im.request(ctx, method, path, body, out)
+	return err
+}
+
+// request sends a JSON request to the GitLab API and decodes the response into out, if non-nil.
+// Like the GitHub backend, it waits out rate limits (429, up to maxRateLimitRetries times) and
+// retries transient failures, 5xx responses and network errors, up to githubMaxAttempts
+// attempts with exponential backoff. POST requests, which create issues and notes, may have
+// succeeded despite a transient failure and are not retried. Context cancellation stops the retries.
+func (im *IssueManager) request(ctx context.Context, method, path string, body, out any) (*http.Response, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	rateLimited, attempts := 0, 1
+	for {
+		resp, err := im.send(ctx, method, path, data, out)
+		if err == nil || ctx.Err() != nil {
+			return resp, err
+		}
+
+		var wait time.Duration
+		var apiErr *apiError
+		limited := errors.As(err, &apiErr) && apiErr.statusCode == http.StatusTooManyRequests
+		switch {
+		case limited && rateLimited < maxRateLimitRetries:
+			rateLimited++
+			wait = apiErr.retryAfter
+			im.logger.Warn().Err(err).
+				Dur("wait", wait).
+				Int("attempt", rateLimited).
+				Msg("GitLab rate limit hit, waiting before retry")
+		case !limited && method != http.MethodPost && isTransientError(err) && attempts < im.config.GitHubMaxAttempts:
+			wait = im.retryBackoff << (attempts - 1)
+			attempts++
+			im.logger.Warn().Err(err).
+				Dur("wait", wait).
+				Int("attempt", attempts).
+				Msg("GitLab request failed, retrying")
+		default:
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// apiError is a non-2xx response of the GitLab API.
+type apiError struct {
+	method, path string
+	status       string
+	statusCode   int
+	message      string
+	// retryAfter is the wait requested by a 429 response.
+	retryAfter time.Duration
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s", e.method, e.path, e.status, e.message)
+}
+
+// isTransientError reports whether a failed GitLab request is worth retrying:
+// a 5xx response or a network error.
+func isTransientError(err error) bool {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.statusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// send makes a single attempt of a request. Non-2xx responses are returned as *apiError
+// carrying GitLab's message.
+func (im *IssueManager) send(ctx context.Context, method, path string, data []byte, out any) (*http.Response, error) {
+	var reader io.Reader
+	if data != nil {
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, im.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", im.token)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := im.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		apiErr := &apiError{
+			method:     method,
+			path:       path,
+			status:     resp.Status,
+			statusCode: resp.StatusCode,
+			message:    strings.TrimSpace(string(msg)),
+			retryAfter: defaultRateLimitWait,
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.retryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, apiErr
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp, nil
+}
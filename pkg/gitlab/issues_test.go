@@ -0,0 +1,412 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/github"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// fakeGitLab serves the project issue endpoints of the GitLab API used by IssueManager.
+type fakeGitLab struct {
+	mu sync.Mutex
+
+	// pages are the open issues returned per listing page.
+	pages [][]Issue
+	// suppressed are the issues returned when listing issues in any state.
+	suppressed  []Issue
+	listQueries []string
+	tokens      []string
+	created     []map[string]string
+	updated     []map[string]string
+	notes       int
+	// status, if set, answers every request with this status code.
+	status int
+	// failures are answered, in order, to the first requests before serving them.
+	failures []int
+	// createFailures are answered, in order, to the first issue creations, which are served
+	// nonetheless, as when GitLab fails after creating the issue.
+	createFailures []int
+}
+
+func (f *fakeGitLab) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.tokens = append(f.tokens, r.Header.Get("PRIVATE-TOKEN"))
+		if f.status != 0 {
+			w.WriteHeader(f.status)
+			fmt.Fprint(w, `{"message":"403 Forbidden"}`)
+			return
+		}
+		if len(f.failures) > 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(f.failures[0])
+			f.failures = f.failures[1:]
+			return
+		}
+
+		var req map[string]string
+		if r.Body != nil {
+			json.NewDecoder(r.Body).Decode(&req)
+		}
+		switch path := r.URL.EscapedPath(); {
+		case r.Method == http.MethodGet && path == "/api/v4/projects/group%2Fproject/issues":
+			f.listQueries = append(f.listQueries, r.URL.RawQuery)
+			if r.URL.Query().Get("state") == "all" {
+				json.NewEncoder(w).Encode(f.suppressed)
+				return
+			}
+			page := 1
+			fmt.Sscan(r.URL.Query().Get("page"), &page)
+			if page < len(f.pages) {
+				w.Header().Set("X-Next-Page", fmt.Sprint(page+1))
+			}
+			issues := []Issue{}
+			if page <= len(f.pages) {
+				issues = f.pages[page-1]
+			}
+			json.NewEncoder(w).Encode(issues)
+		case r.Method == http.MethodPost && path == "/api/v4/projects/group%2Fproject/issues":
+			f.created = append(f.created, req)
+			if len(f.createFailures) > 0 {
+				w.WriteHeader(f.createFailures[0])
+				f.createFailures = f.createFailures[1:]
+				return
+			}
+			json.NewEncoder(w).Encode(Issue{IID: 40 + len(f.created), Title: req["title"], Description: req["description"],
+				WebURL: fmt.Sprintf("https://gitlab.com/group/project/-/issues/%d", 40+len(f.created))})
+		case r.Method == http.MethodPut && path == "/api/v4/projects/group%2Fproject/issues/7":
+			f.updated = append(f.updated, req)
+			json.NewEncoder(w).Encode(Issue{IID: 7, Title: req["title"], Description: req["description"],
+				WebURL: "https://gitlab.com/group/project/-/issues/7"})
+		case r.Method == http.MethodPost && path == "/api/v4/projects/group%2Fproject/issues/7/notes":
+			f.notes++
+			fmt.Fprint(w, `{"id":1}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func newTestIssueManager(t *testing.T, cfg *config.Config, fake *fakeGitLab) *IssueManager {
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+
+	cfg.GitLabURL = server.URL + "/"
+	cfg.GitLabToken = "glpat-token"
+	cfg.GitLabProjectID = "group/project"
+	im := NewIssueManager(cfg, logging.NewLogger("error", "json"))
+	im.retryBackoff = time.Millisecond
+	return im
+}
+
+var testRelease = nova.ReleaseOutput{
+	ReleaseName: "my-release",
+	ChartName:   "my-chart",
+	Namespace:   "default",
+	Installed:   nova.VersionInfo{Version: "1.0.0"},
+	Latest:      nova.VersionInfo{Version: "2.0.0"},
+}
+
+func TestCreateHelmIssue_CreatesWhenNoneExists(t *testing.T) {
+	fake := &fakeGitLab{}
+	im := newTestIssueManager(t, &config.Config{IssueLabels: []string{"team-platform"}}, fake)
+
+	issueURL, err := im.CreateHelmIssue(context.Background(), testRelease)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issueURL != "https://gitlab.com/group/project/-/issues/41" {
+		t.Errorf("unexpected URL %q", issueURL)
+	}
+	if len(fake.created) != 1 {
+		t.Fatalf("expected one created issue, got %d", len(fake.created))
+	}
+
+	created := fake.created[0]
+	if want := "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)"; created["title"] != want {
+		t.Errorf("title = %q, want %q", created["title"], want)
+	}
+	if !strings.Contains(created["description"], "my-release") || !strings.Contains(created["description"], "<!-- nova-scanner:fingerprint=helm:default/my-release:my-chart -->") {
+		t.Errorf("expected description with release and fingerprint marker, got %q", created["description"])
+	}
+	if created["labels"] != "nova-scan,team-platform,helm-update" {
+		t.Errorf("labels = %q, want nova-scan,team-platform,helm-update", created["labels"])
+	}
+	if !strings.Contains(fake.listQueries[0], "labels=nova-scan") || !strings.Contains(fake.listQueries[0], "state=opened") {
+		t.Errorf("expected open nova-scan issues to be listed, got %q", fake.listQueries[0])
+	}
+	for _, token := range fake.tokens {
+		if token != "glpat-token" {
+			t.Errorf("expected the token in every request, got %q", token)
+		}
+	}
+}
+
+func TestCreateContainerIssue_SkipsExisting(t *testing.T) {
+	container := nova.ContainerOutput{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"}
+	content, err := github.ContainerIssueContent(&config.Config{}, container)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{pages: [][]Issue{{{IID: 7, Title: content.Title, Description: content.Body}}}}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	for range 2 {
+		if _, err := im.CreateContainerIssue(context.Background(), container); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(fake.created) != 0 || len(fake.updated) != 0 {
+		t.Errorf("expected the existing issue to be left alone, got %d created, %d updated", len(fake.created), len(fake.updated))
+	}
+	if im.SkippedIssues() != 2 {
+		t.Errorf("expected 2 skipped issues, got %d", im.SkippedIssues())
+	}
+	if len(fake.listQueries) != 1 {
+		t.Errorf("expected open issues to be listed once, got %d", len(fake.listQueries))
+	}
+}
+
+func TestCreateHelmIssue_UpdatesChangedIssue(t *testing.T) {
+	previous := testRelease
+	previous.Latest.Version = "1.5.0"
+	content, err := github.HelmIssueContent(&config.Config{}, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{pages: [][]Issue{
+		{{IID: 3, Title: "[Nova] Update Helm chart: other (1.0.0 → 2.0.0)"}},
+		{{IID: 7, Title: content.Title, Description: content.Body}},
+	}}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.listQueries) != 2 {
+		t.Errorf("expected both pages to be listed, got %d requests", len(fake.listQueries))
+	}
+	if len(fake.created) != 0 || len(fake.updated) != 1 || fake.notes != 1 {
+		t.Fatalf("expected an update with a note, got %d created, %d updated, %d notes", len(fake.created), len(fake.updated), fake.notes)
+	}
+	if want := "[Nova] Update Helm chart: my-release (1.0.0 → 2.0.0)"; fake.updated[0]["title"] != want {
+		t.Errorf("updated title = %q, want %q", fake.updated[0]["title"], want)
+	}
+}
+
+func TestCreateHelmIssue_InstalledVersionChangeUpdatesQuietly(t *testing.T) {
+	previous := testRelease
+	previous.Installed.Version = "0.9.0"
+	content, err := github.HelmIssueContent(&config.Config{}, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{pages: [][]Issue{{{IID: 7, Title: content.Title, Description: content.Body}}}}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.updated) != 1 || fake.notes != 0 {
+		t.Errorf("expected an update without a note, got %d updated, %d notes", len(fake.updated), fake.notes)
+	}
+}
+
+func TestCreateHelmIssue_SkipsDescriptionOnlyChange(t *testing.T) {
+	content, err := github.HelmIssueContent(&config.Config{}, testRelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{pages: [][]Issue{{{IID: 7, Title: content.Title, Description: "Edited by the team.\r\n" + content.Body}}}}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.created) != 0 || len(fake.updated) != 0 {
+		t.Errorf("expected the issue to be left alone, got %d created, %d updated", len(fake.created), len(fake.updated))
+	}
+}
+
+func TestCreateNamespaceIssue_UpdatesChangedBody(t *testing.T) {
+	group := github.NamespaceGroup{Namespace: "payments", Releases: []nova.ReleaseOutput{testRelease}}
+	content := github.NamespaceIssueContent(&config.Config{}, group)
+
+	tests := []struct {
+		name        string
+		description string
+		wantUpdated int
+	}{
+		{"unchanged body is skipped", strings.ReplaceAll(content.Body, "\n", "\r\n"), 0},
+		{"changed body is updated", "old body" + content.Body[strings.Index(content.Body, "<!--"):], 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitLab{pages: [][]Issue{{{IID: 7, Title: content.Title, Description: tt.description}}}}
+			im := newTestIssueManager(t, &config.Config{}, fake)
+
+			if _, err := im.CreateNamespaceIssue(context.Background(), group); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(fake.updated) != tt.wantUpdated || fake.notes != tt.wantUpdated {
+				t.Errorf("expected %d updates with a note, got %d updated, %d notes", tt.wantUpdated, len(fake.updated), fake.notes)
+			}
+		})
+	}
+}
+
+func TestCreateHelmIssue_IgnoresOtherTitlePrefix(t *testing.T) {
+	content, err := github.HelmIssueContent(&config.Config{}, testRelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{pages: [][]Issue{{{IID: 7, Title: content.Title, Description: content.Body}}}}
+	im := newTestIssueManager(t, &config.Config{IssueTitlePrefix: "[Nova prod]"}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.created) != 1 || !strings.HasPrefix(fake.created[0]["title"], "[Nova prod] ") {
+		t.Errorf("expected an issue with the configured prefix to be created, got %v", fake.created)
+	}
+}
+
+func TestCreateHelmIssue_EditedTitle(t *testing.T) {
+	content, err := github.HelmIssueContent(&config.Config{IssueTitlePrefix: "[Nova prod]"}, testRelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{pages: [][]Issue{{{IID: 7, Title: "Upgrade my-release before Q3", Description: content.Body}}}}
+	im := newTestIssueManager(t, &config.Config{IssueTitlePrefix: "[Nova prod]"}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.created) != 0 {
+		t.Errorf("expected the issue with an edited title to be found by its marker, got %d created", len(fake.created))
+	}
+}
+
+func TestCreateHelmIssue_SuppressLabel(t *testing.T) {
+	content, err := github.HelmIssueContent(&config.Config{}, testRelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{pages: [][]Issue{{{IID: 7, Title: "[Nova] outdated", Description: content.Body,
+		Labels: []string{"nova-scan", "wontfix"}}}}}
+	im := newTestIssueManager(t, &config.Config{SuppressLabel: "wontfix"}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.created) != 0 || len(fake.updated) != 0 {
+		t.Errorf("expected the suppressed issue to be left alone, got %d created, %d updated", len(fake.created), len(fake.updated))
+	}
+	if im.SkippedIssues() != 1 {
+		t.Errorf("expected 1 skipped issue, got %d", im.SkippedIssues())
+	}
+}
+
+func TestCreateHelmIssue_SuppressLabelOnClosedIssue(t *testing.T) {
+	content, err := github.HelmIssueContent(&config.Config{}, testRelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitLab{suppressed: []Issue{{IID: 5, Title: "[Nova] outdated", Description: content.Body,
+		Labels: []string{"nova-scan", "wontfix"}}}}
+	im := newTestIssueManager(t, &config.Config{SuppressLabel: "wontfix"}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.created) != 0 {
+		t.Errorf("expected no issue for a component suppressed by a closed issue, got %d created", len(fake.created))
+	}
+	if !strings.Contains(fake.listQueries[0], "labels=nova-scan%2Cwontfix") {
+		t.Errorf("expected issues carrying the suppress label to be listed, got %q", fake.listQueries[0])
+	}
+}
+
+func TestCreateHelmIssue_RetriesTransientFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    []int
+		maxAttempts int
+		wantCreated int
+	}{
+		{"server error is retried", []int{http.StatusBadGateway, http.StatusBadGateway}, 3, 1},
+		{"rate limit is waited out", []int{http.StatusTooManyRequests}, 1, 1},
+		{"attempts are bounded", []int{http.StatusBadGateway, http.StatusBadGateway}, 2, 0},
+		{"client error is not retried", []int{http.StatusNotFound}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitLab{failures: tt.failures}
+			im := newTestIssueManager(t, &config.Config{GitHubMaxAttempts: tt.maxAttempts}, fake)
+
+			_, err := im.CreateHelmIssue(context.Background(), testRelease)
+			if (err == nil) != (tt.wantCreated == 1) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(fake.created) != tt.wantCreated {
+				t.Errorf("expected %d created issues, got %d", tt.wantCreated, len(fake.created))
+			}
+		})
+	}
+}
+
+func TestCreateHelmIssue_DoesNotRetryFailedCreate(t *testing.T) {
+	// GitLab may have created the issue before failing, so a retry could file it twice
+	fake := &fakeGitLab{createFailures: []int{http.StatusBadGateway}}
+	im := newTestIssueManager(t, &config.Config{GitHubMaxAttempts: 3}, fake)
+
+	if _, err := im.CreateHelmIssue(context.Background(), testRelease); err == nil {
+		t.Error("expected the failed create to be returned")
+	}
+	if len(fake.created) != 1 {
+		t.Errorf("expected a single create request, got %d", len(fake.created))
+	}
+}
+
+func TestCreateHelmIssue_DryRun(t *testing.T) {
+	fake := &fakeGitLab{}
+	im := newTestIssueManager(t, &config.Config{DryRun: true}, fake)
+
+	issueURL, err := im.CreateHelmIssue(context.Background(), testRelease)
+	if err != nil || issueURL != "" {
+		t.Fatalf("CreateHelmIssue() = %q, %v; want no issue and no error", issueURL, err)
+	}
+	if len(fake.created) != 0 {
+		t.Errorf("expected no issue in dry-run mode, got %d", len(fake.created))
+	}
+}
+
+func TestCreateHelmIssue_APIError(t *testing.T) {
+	fake := &fakeGitLab{status: http.StatusForbidden}
+	im := newTestIssueManager(t, &config.Config{}, fake)
+
+	_, err := im.CreateHelmIssue(context.Background(), testRelease)
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Errorf("expected the GitLab error to be returned, got %v", err)
+	}
+}
+
+func TestCreateSummaryIssue_Unsupported(t *testing.T) {
+	im := NewIssueManager(&config.Config{}, logging.NewLogger("error", "json"))
+	if _, err := im.CreateSummaryIssue(context.Background(), time.Now()); err == nil {
+		t.Error("expected summary issues to be unsupported")
+	}
+}
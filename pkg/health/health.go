@@ -0,0 +1,104 @@
+// Package health serves the liveness and readiness probes of the scanner in daemon mode.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout bounds how long Serve waits for in-flight probes when stopping.
+const shutdownTimeout = 5 * time.Second
+
+// Checker tracks scan results for the probes: /healthz answers 200 while the process is up,
+// /readyz answers 200 once a scan completed and until failureThreshold scans in a row failed.
+type Checker struct {
+	failureThreshold int
+
+	mu          sync.Mutex
+	scanned     bool
+	failures    int // consecutive failed scans
+	lastFailure time.Time
+}
+
+// NewChecker creates a Checker that turns unready after failureThreshold consecutive failed scans.
+func NewChecker(failureThreshold int) *Checker {
+	return &Checker{failureThreshold: failureThreshold}
+}
+
+// RecordScan records the result of a completed scan.
+func (c *Checker) RecordScan(ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.scanned = true
+	if ok {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	c.lastFailure = time.Now()
+}
+
+// Ready reports whether the scanner is ready, and why not otherwise.
+func (c *Checker) Ready() (bool, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.scanned {
+		return false, "waiting for the first scan"
+	}
+	if c.failures >= c.failureThreshold {
+		return false, fmt.Sprintf("%d consecutive scans failed, last at %s", c.failures, c.lastFailure.UTC().Format(time.RFC3339))
+	}
+	return true, "ok"
+}
+
+// Handler returns the handler serving /healthz and /readyz.
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready, reason := c.Ready()
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprintln(w, reason)
+	})
+	return mux
+}
+
+// Serve serves the probes on addr (e.g. ":8080") until ctx is canceled.
+func (c *Checker) Serve(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return c.serve(ctx, listener)
+}
+
+// serve serves the probes on listener until ctx is canceled.
+func (c *Checker) serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{Handler: c.Handler(), ReadHeaderTimeout: shutdownTimeout}
+
+	errc := make(chan error, 1)
+	go func() { errc <- server.Serve(listener) }()
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("health server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop health server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// probe requests path from the checker's handler and returns the status code and body.
+func probe(t *testing.T, c *Checker, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestChecker_Probes(t *testing.T) {
+	c := NewChecker(2)
+
+	steps := []struct {
+		name      string
+		scan      func()
+		wantReady int
+		wantBody  string
+	}{
+		{"before the first scan", func() {}, http.StatusServiceUnavailable, "waiting for the first scan"},
+		{"after a successful scan", func() { c.RecordScan(true) }, http.StatusOK, "ok"},
+		{"after one failed scan", func() { c.RecordScan(false) }, http.StatusOK, "ok"},
+		{"after failures reaching the threshold", func() { c.RecordScan(false) }, http.StatusServiceUnavailable, "2 consecutive scans failed"},
+		{"after recovering", func() { c.RecordScan(true) }, http.StatusOK, "ok"},
+	}
+	for _, step := range steps {
+		step.scan()
+
+		if code, _ := probe(t, c, "/healthz"); code != http.StatusOK {
+			t.Errorf("%s: /healthz = %d, want 200", step.name, code)
+		}
+		code, body := probe(t, c, "/readyz")
+		if code != step.wantReady || !strings.Contains(body, step.wantBody) {
+			t.Errorf("%s: /readyz = %d %q, want %d %q", step.name, code, body, step.wantReady, step.wantBody)
+		}
+	}
+}
+
+func TestChecker_FirstScanFailed(t *testing.T) {
+	c := NewChecker(1)
+	c.RecordScan(false)
+
+	if code, _ := probe(t, c, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz = %d, want 503 after the only scan failed", code)
+	}
+}
+
+func TestChecker_Serve(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewChecker(1)
+	c.RecordScan(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- c.serve(ctx, listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/readyz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != "ok" {
+		t.Errorf("/readyz = %d %q, want 200 ok", resp.StatusCode, body)
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestChecker_ServeInvalidAddr(t *testing.T) {
+	if err := NewChecker(1).Serve(context.Background(), "not-an-addr"); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
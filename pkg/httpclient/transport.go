@@ -0,0 +1,108 @@
+// Package httpclient builds the transport shared by the scanner's outbound HTTP clients.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+)
+
+// Options configure the transport returned by Transport.
+type Options struct {
+	// ProxyURL is the proxy requests are sent through. Empty uses the proxy from the
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+	ProxyURL string
+	// UserAgent, if set, replaces the User-Agent header of every request, including the one
+	// set by client libraries such as go-github.
+	UserAgent string
+	// CACertFile is a PEM bundle of CAs trusted in addition to the system roots.
+	CACertFile string
+	// InsecureSkipVerify disables verification of server certificates.
+	InsecureSkipVerify bool
+}
+
+// FromConfig returns the transport for the network settings of cfg.
+func FromConfig(cfg *config.Config) http.RoundTripper {
+	return Transport(Options{
+		ProxyURL:           cfg.HTTPProxy,
+		UserAgent:          cfg.UserAgent,
+		CACertFile:         cfg.CACertFile,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	})
+}
+
+// Transport returns an HTTP transport for opts. An invalid ProxyURL or an unreadable
+// CACertFile fails each request rather than bypassing the proxy or the custom CAs.
+func Transport(opts Options) http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if opts.ProxyURL != "" {
+		u, err := url.Parse(opts.ProxyURL)
+		transport.Proxy = func(*http.Request) (*url.URL, error) {
+			return u, err
+		}
+	}
+
+	var base http.RoundTripper = transport
+	if opts.CACertFile != "" || opts.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	}
+	if opts.CACertFile != "" {
+		pool, err := CertPool(opts.CACertFile)
+		if err != nil {
+			base = failingTransport{err: err}
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if opts.UserAgent == "" {
+		return base
+	}
+	return &userAgentTransport{base: base, userAgent: opts.UserAgent}
+}
+
+// CertPool returns the system roots plus the certificates in the PEM file at path, so
+// public endpoints keep working next to the ones behind an internal CA. Where the system
+// roots can't be loaded, the pool holds only the file's certificates.
+func CertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct {
+	err error
+}
+
+// RoundTrip returns the transport's error.
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// userAgentTransport sets the User-Agent header of each request before sending it with base.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip sends a copy of req carrying the User-Agent header.
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(req)
+}
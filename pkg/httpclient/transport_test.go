@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransport_ProxyOverride(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	client := &http.Client{Transport: Transport(Options{ProxyURL: proxy.URL})}
+	resp, err := client.Get("http://api.example.test/repos")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(proxied) != 1 || proxied[0] != "http://api.example.test/repos" {
+		t.Errorf("expected the request to go through the proxy, got %v", proxied)
+	}
+}
+
+func TestTransport_InvalidProxy(t *testing.T) {
+	client := &http.Client{Transport: Transport(Options{ProxyURL: "http://proxy:port"})}
+	if _, err := client.Get("http://api.example.test/"); err == nil {
+		t.Error("expected an invalid proxy URL to fail the request")
+	}
+}
+
+func TestTransport_UserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+
+	for _, userAgent := range []string{"nova-scanner/1.2.3", ""} {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("User-Agent", "go-github/v60")
+		resp, err := (&http.Client{Transport: Transport(Options{UserAgent: userAgent})}).Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if req.Header.Get("User-Agent") != "go-github/v60" {
+			t.Error("expected the caller's request to be left unchanged")
+		}
+	}
+
+	if want := []string{"nova-scanner/1.2.3", "go-github/v60"}; len(userAgents) != 2 || userAgents[0] != want[0] || userAgents[1] != want[1] {
+		t.Errorf("expected user agents %v, got %v", want, userAgents)
+	}
+}
+
+// writeCACert writes the certificate of server to a PEM file and returns its path.
+func writeCACert(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTransport_CACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport := Transport(Options{CACertFile: writeCACert(t, server)})
+	tlsConfig := transport.(*http.Transport).TLSClientConfig
+	if tlsConfig == nil || tlsConfig.RootCAs == nil {
+		t.Fatal("expected the TLS config to carry the custom CA pool")
+	}
+	if tlsConfig.InsecureSkipVerify {
+		t.Error("expected certificates to be verified")
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the server certificate to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := (&http.Client{Transport: Transport(Options{})}).Get(server.URL); err == nil {
+		t.Error("expected the server certificate to be rejected without the CA bundle")
+	}
+}
+
+func TestCertPool_KeepsSystemRoots(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	path := writeCACert(t, server)
+	pool, err := CertPool(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, err := x509.SystemCertPool()
+	if err != nil {
+		t.Skipf("system roots unavailable: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.AppendCertsFromPEM(data)
+	if !pool.Equal(want) {
+		t.Error("expected the pool to hold the system roots plus the CA bundle")
+	}
+}
+
+func TestTransport_InvalidCACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{path, filepath.Join(t.TempDir(), "missing.pem")} {
+		client := &http.Client{Transport: Transport(Options{CACertFile: file, InsecureSkipVerify: true})}
+		if _, err := client.Get(server.URL); err == nil {
+			t.Errorf("expected requests to fail with CA bundle %s", file)
+		}
+	}
+}
+
+func TestTransport_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := (&http.Client{Transport: Transport(Options{InsecureSkipVerify: true})}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected an untrusted certificate to be accepted, got %v", err)
+	}
+	resp.Body.Close()
+}
@@ -1,6 +1,10 @@
 package logging
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -12,12 +16,28 @@ import (
 type Logger struct {
 	zerolog.Logger
 	traceID string
+	// componentLevels overrides the level of component loggers, by component.
+	componentLevels map[string]zerolog.Level
+	// out is the log destination; events, if set, also receives every scan event.
+	out    io.Writer
+	events io.Writer
 }
 
-// NewLogger creates a new structured logger with the specified level.
-func NewLogger(level string) *Logger {
+// NewLogger creates a new structured logger with the specified level and format writing to stdout.
+func NewLogger(level, format string) *Logger {
+	return NewLoggerWithWriter(level, format, os.Stdout)
+}
+
+// NewLoggerWithWriter creates a new structured logger with the specified level and format writing to w.
+// Format "console" writes human-readable lines; anything else writes JSON.
+func NewLoggerWithWriter(level, format string, w io.Writer) *Logger {
 	zerolog.TimeFieldFormat = time.RFC3339
 
+	if format == "console" {
+		_, isFile := w.(*os.File)
+		w = zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339, NoColor: !isFile}
+	}
+
 	lvl, err := zerolog.ParseLevel(level)
 	if err != nil {
 		lvl = zerolog.InfoLevel
@@ -25,7 +45,7 @@ func NewLogger(level string) *Logger {
 
 	traceID := uuid.New().String()[:8]
 
-	logger := zerolog.New(os.Stdout).
+	logger := zerolog.New(w).
 		Level(lvl).
 		With().
 		Timestamp().
@@ -35,7 +55,26 @@ func NewLogger(level string) *Logger {
 	return &Logger{
 		Logger:  logger,
 		traceID: traceID,
+		out:     w,
+	}
+}
+
+// Output returns the writer for a log destination ("stdout" or "stderr").
+// Unknown destinations fall back to stdout.
+func Output(destination string) io.Writer {
+	if destination == "stderr" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// OpenLogFile opens path for appending log lines, creating it if needed.
+func OpenLogFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
+	return f, nil
 }
 
 // TraceID returns the current trace ID.
@@ -43,17 +82,111 @@ func (l *Logger) TraceID() string {
 	return l.traceID
 }
 
-// WithComponent returns a new logger with the component field set.
+// WithComponentLevels returns a copy of the logger whose component loggers (see WithComponent)
+// log at the given level instead of the global one, by component name, e.g. "github": "debug".
+// Unknown level names are ignored.
+func (l *Logger) WithComponentLevels(levels map[string]string) *Logger {
+	componentLevels := make(map[string]zerolog.Level, len(levels))
+	for component, level := range levels {
+		if lvl, err := zerolog.ParseLevel(level); err == nil && level != "" {
+			componentLevels[component] = lvl
+		}
+	}
+	return &Logger{
+		Logger:          l.Logger,
+		traceID:         l.traceID,
+		componentLevels: componentLevels,
+		out:             l.out,
+		events:          l.events,
+	}
+}
+
+// WithComponent returns a new logger with the component field set, logging at the
+// component's level if one is configured (see WithComponentLevels).
 func (l *Logger) WithComponent(component string) *Logger {
+	logger := l.With().Str("component", component).Logger()
+	if lvl, ok := l.componentLevels[component]; ok {
+		logger = logger.Level(lvl)
+	}
+	return &Logger{
+		Logger:          logger,
+		traceID:         l.traceID,
+		componentLevels: l.componentLevels,
+		out:             l.out,
+		events:          l.events,
+	}
+}
+
+// WithKubeContext returns a new logger with the kube_context field set.
+func (l *Logger) WithKubeContext(kubeContext string) *Logger {
 	return &Logger{
-		Logger:  l.With().Str("component", component).Logger(),
-		traceID: l.traceID,
+		Logger:          l.With().Str("kube_context", kubeContext).Logger(),
+		traceID:         l.traceID,
+		componentLevels: l.componentLevels,
+		out:             l.out,
+		events:          l.events,
 	}
 }
 
+// WithLogFile returns a copy of the logger that also writes every log line to w as JSON,
+// whatever the format: console formatting only applies to the logger's own output.
+func (l *Logger) WithLogFile(w io.Writer) *Logger {
+	out := zerolog.MultiLevelWriter(l.out, w)
+	return &Logger{
+		Logger:          l.Output(out),
+		traceID:         l.traceID,
+		componentLevels: l.componentLevels,
+		out:             out,
+		events:          l.events,
+	}
+}
+
+// WithEventLog returns a copy of the logger that also writes every scan event (the helpers
+// below, each tagged with an "event" field) to w as JSON lines, whatever the log level.
+func (l *Logger) WithEventLog(w io.Writer) *Logger {
+	return &Logger{
+		Logger:          l.Logger,
+		traceID:         l.traceID,
+		componentLevels: l.componentLevels,
+		out:             l.out,
+		events:          w,
+	}
+}
+
+// Events returns the logger for scan events: it logs at the logger's level and, with an event
+// log (see WithEventLog), writes every event to it as well.
+func (l *Logger) Events() *zerolog.Logger {
+	if l.events == nil {
+		return &l.Logger
+	}
+	logger := l.Output(eventWriter{out: l.out, level: l.GetLevel(), events: l.events}).Level(zerolog.TraceLevel)
+	return &logger
+}
+
+// eventWriter writes log lines to the event log and, at or above level, to out.
+type eventWriter struct {
+	out    io.Writer
+	level  zerolog.Level
+	events io.Writer
+}
+
+func (w eventWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel writes p, a JSON log line, to out if level is enabled there and to the event log.
+func (w eventWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level >= w.level {
+		if _, err := w.out.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return w.events.Write(p)
+}
+
 // ScanStart logs the start of a scan operation.
 func (l *Logger) ScanStart(scanType string) {
-	l.Info().
+	l.Events().Info().
 		Str("event", "scan_start").
 		Str("scan_type", scanType).
 		Msg("Starting scan")
@@ -61,7 +194,7 @@ func (l *Logger) ScanStart(scanType string) {
 
 // ScanEnd logs the end of a scan operation with duration and results.
 func (l *Logger) ScanEnd(scanType string, duration time.Duration, total, outdated int) {
-	l.Info().
+	l.Events().Info().
 		Str("event", "scan_end").
 		Str("scan_type", scanType).
 		Dur("duration", duration).
@@ -72,7 +205,7 @@ func (l *Logger) ScanEnd(scanType string, duration time.Duration, total, outdate
 
 // OutdatedFound logs when an outdated component is detected.
 func (l *Logger) OutdatedFound(componentType, name, namespace, currentVersion, latestVersion string) {
-	l.Warn().
+	l.Events().Warn().
 		Str("event", "outdated_found").
 		Str("component_type", componentType).
 		Str("name", name).
@@ -82,48 +215,165 @@ func (l *Logger) OutdatedFound(componentType, name, namespace, currentVersion, l
 		Msg("Outdated component detected")
 }
 
-// IssueCreated logs when a GitHub issue is created.
+// IssueCreated logs when an issue is created.
 func (l *Logger) IssueCreated(issueType, title, url string) {
-	l.Info().
+	l.Events().Info().
 		Str("event", "issue_created").
 		Str("issue_type", issueType).
 		Str("title", title).
 		Str("url", url).
-		Msg("GitHub issue created")
+		Msg("Issue created")
 }
 
-// IssueSkipped logs when a GitHub issue is skipped (e.g., duplicate).
+// IssueSkipped logs when an issue is skipped (e.g., duplicate).
 func (l *Logger) IssueSkipped(issueType, title, reason string) {
-	l.Debug().
+	l.Events().Debug().
 		Str("event", "issue_skipped").
 		Str("issue_type", issueType).
 		Str("title", title).
 		Str("reason", reason).
-		Msg("GitHub issue skipped")
+		Msg("Issue skipped")
+}
+
+// IssueSuppressed logs when an outdated component is left alone because its issue carries the suppress label.
+func (l *Logger) IssueSuppressed(issueType, title string, number int, label string) {
+	l.Events().Info().
+		Str("event", "issue_suppressed").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Int("issue_number", number).
+		Str("label", label).
+		Msg("Issue suppressed by label")
 }
 
 // IssueDryRun logs when an issue would be created in dry-run mode.
 func (l *Logger) IssueDryRun(issueType, title string) {
-	l.Info().
+	l.Events().Info().
 		Str("event", "issue_dry_run").
 		Str("issue_type", issueType).
 		Str("title", title).
-		Msg("Would create GitHub issue (dry-run mode)")
+		Msg("Would create issue (dry-run mode)")
+}
+
+// IssueUpdated logs when an existing issue is updated to a newer version.
+func (l *Logger) IssueUpdated(issueType, title, url string) {
+	l.Events().Info().
+		Str("event", "issue_updated").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Str("url", url).
+		Msg("Issue updated")
+}
+
+// IssueUpdateDryRun logs when an issue would be updated in dry-run mode.
+func (l *Logger) IssueUpdateDryRun(issueType, title string, number int) {
+	l.Events().Info().
+		Str("event", "issue_update_dry_run").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Int("issue_number", number).
+		Msg("Would update issue (dry-run mode)")
+}
+
+// IssueClosed logs when an issue is closed.
+func (l *Logger) IssueClosed(issueType, title, url string) {
+	l.Events().Info().
+		Str("event", "issue_closed").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Str("url", url).
+		Msg("Issue closed")
+}
+
+// IssueCloseDryRun logs when an issue would be closed in dry-run mode. It is logged next to
+// IssueWouldClose, which adds the reason, for consumers of the original event.
+func (l *Logger) IssueCloseDryRun(issueType, title string, number int) {
+	l.Events().Info().
+		Str("event", "issue_close_dry_run").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Int("issue_number", number).
+		Msg("Would close issue (dry-run mode)")
+}
+
+// IssueWouldClose logs when an issue would be closed in dry-run mode, and why.
+func (l *Logger) IssueWouldClose(issueType, title string, number int, reason string) {
+	l.Events().Info().
+		Str("event", "issue_would_close").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Int("issue_number", number).
+		Str("reason", reason).
+		Msg("Would close issue (dry-run mode)")
+}
+
+// IssueReopened logs when a closed issue is reopened because the component is still outdated.
+func (l *Logger) IssueReopened(issueType, title, url string) {
+	l.Events().Info().
+		Str("event", "issue_reopened").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Str("url", url).
+		Msg("Issue reopened")
+}
+
+// IssueReopenDryRun logs when an issue would be reopened in dry-run mode.
+func (l *Logger) IssueReopenDryRun(issueType, title string, number int) {
+	l.Events().Info().
+		Str("event", "issue_reopen_dry_run").
+		Str("issue_type", issueType).
+		Str("title", title).
+		Int("issue_number", number).
+		Msg("Would reopen issue (dry-run mode)")
 }
 
 // MetricsPushed logs when metrics are pushed to the pushgateway.
 func (l *Logger) MetricsPushed(url string) {
-	l.Info().
+	l.Events().Info().
 		Str("event", "metrics_pushed").
 		Str("pushgateway_url", url).
 		Msg("Metrics pushed to Pushgateway")
 }
 
-// ScanError logs a scan error.
-func (l *Logger) ScanError(scanType string, err error) {
-	l.Error().
+// MetricsDryRun logs the metrics that would have been pushed in dry-run mode.
+func (l *Logger) MetricsDryRun(url, metrics string) {
+	l.Events().Info().
+		Str("event", "metrics_dry_run").
+		Str("pushgateway_url", url).
+		Str("metrics", metrics).
+		Msg("Would push metrics to Pushgateway (dry-run mode)")
+}
+
+// OutdatedDiff logs the components that became outdated or were resolved since the previous run.
+func (l *Logger) OutdatedDiff(newlyOutdated, resolved []string) {
+	l.Events().Info().
+		Str("event", "outdated_diff").
+		Strs("newly_outdated", newlyOutdated).
+		Strs("resolved", resolved).
+		Msg("Compared outdated components with the previous run")
+}
+
+// ScanSummary logs the totals of a complete run as a single event.
+func (l *Logger) ScanSummary(outdatedHelm, outdatedContainers, skippedContainers, issuesCreated, issuesSkipped int, duration time.Duration) {
+	l.Events().Info().
+		Str("event", "scan_summary").
+		Int("outdated_helm", outdatedHelm).
+		Int("outdated_containers", outdatedContainers).
+		Int("skipped_containers", skippedContainers).
+		Int("issues_created", issuesCreated).
+		Int("issues_skipped", issuesSkipped).
+		Dur("duration", duration).
+		Msg("Nova scanner completed")
+}
+
+// ScanError logs a scan error with its failure class (see nova.ErrorReason), flagging errors
+// caused by a timeout.
+func (l *Logger) ScanError(scanType, reason string, err error) {
+	l.Events().Error().
 		Str("event", "scan_error").
 		Str("scan_type", scanType).
+		Str("reason", reason).
+		Bool("timeout", errors.Is(err, context.DeadlineExceeded)).
 		Err(err).
 		Msg("Scan failed")
 }
@@ -2,8 +2,13 @@ package logging
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,7 +31,7 @@ func TestNewLogger(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			logger := NewLogger(tt.level)
+			logger := NewLogger(tt.level, "json")
 			if logger == nil {
 				t.Fatal("expected non-nil logger")
 			}
@@ -41,7 +46,7 @@ func TestNewLogger(t *testing.T) {
 }
 
 func TestLogger_TraceID(t *testing.T) {
-	logger := NewLogger("info")
+	logger := NewLogger("info", "json")
 	traceID := logger.TraceID()
 
 	if traceID == "" {
@@ -53,7 +58,7 @@ func TestLogger_TraceID(t *testing.T) {
 }
 
 func TestLogger_WithComponent(t *testing.T) {
-	logger := NewLogger("info")
+	logger := NewLogger("info", "json")
 	componentLogger := logger.WithComponent("test-component")
 
 	if componentLogger == nil {
@@ -70,7 +75,7 @@ func TestLogger_OutputFormat(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	logger := NewLogger("info")
+	logger := NewLogger("info", "json")
 	logger.Info().Msg("test message")
 
 	w.Close()
@@ -101,12 +106,170 @@ func TestLogger_OutputFormat(t *testing.T) {
 	}
 }
 
+func TestLogger_ConsoleFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithWriter("info", "console", &buf).WithComponent("nova")
+	logger.Info().Msg("test message")
+
+	output := buf.String()
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &logEntry); err == nil {
+		t.Fatalf("console output should not be JSON, got %s", output)
+	}
+	for _, want := range []string{"INF", "test message", "trace_id=" + logger.TraceID(), "component=nova"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected console output to contain %q, got %q", want, output)
+		}
+	}
+}
+
+func TestLogger_ComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	root := NewLoggerWithWriter("info", "json", &buf).
+		WithComponentLevels(map[string]string{"github": "debug", "nova": "warn", "slack": "loud"})
+
+	root.Debug().Msg("root debug")
+	root.WithComponent("github").Debug().Msg("github debug")
+	root.WithKubeContext("prod").WithComponent("github").Debug().Msg("github prod debug")
+	root.WithComponent("nova").Info().Msg("nova info")
+	root.WithComponent("nova").Warn().Msg("nova warn")
+	root.WithComponent("slack").Info().Msg("slack info")
+	root.WithComponent("slack").Debug().Msg("slack debug")
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		got = append(got, entry["message"].(string))
+	}
+	want := []string{"github debug", "github prod debug", "nova warn", "slack info"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected messages %v, got %v", want, got)
+	}
+}
+
+func TestLogger_LogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scanner.log")
+	if err := os.WriteFile(path, []byte("{\"message\":\"previous run\"}\n"), 0o640); err != nil {
+		t.Fatalf("failed to seed log file: %v", err)
+	}
+
+	f, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The console format applies to stdout only; the file always gets JSON
+	var stdout bytes.Buffer
+	logger := NewLoggerWithWriter("info", "console", &stdout).WithLogFile(f)
+	logger.WithComponent("nova").Info().Msg("audit entry")
+	f.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected log line appended after existing content, got %q", data)
+	}
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &logEntry); err != nil {
+		t.Fatalf("log file should contain JSON: %v\nOutput: %s", err, lines[1])
+	}
+	if logEntry["message"] != "audit entry" || logEntry["component"] != "nova" {
+		t.Errorf("expected message 'audit entry' from nova, got %v", logEntry)
+	}
+	if !strings.Contains(stdout.String(), "audit entry") || json.Valid(stdout.Bytes()) {
+		t.Errorf("expected a console log line on stdout as well, got %q", stdout.String())
+	}
+}
+
+func TestLogger_EventLog(t *testing.T) {
+	var stdout, events bytes.Buffer
+	logger := NewLoggerWithWriter("warn", "console", &stdout).WithEventLog(&events)
+
+	// A simulated run: events at every level plus a plain log line
+	logger.ScanStart("helm")
+	scanLogger := logger.WithComponent("nova").WithKubeContext("prod")
+	scanLogger.OutdatedFound("helm", "my-release", "default", "1.0.0", "2.0.0")
+	scanLogger.IssueSkipped("helm", "[Nova] Update Helm chart: my-release", "duplicate")
+	logger.IssueCreated("helm", "[Nova] Update Helm chart: other", "https://github.com/o/r/issues/1")
+	logger.Info().Msg("not an event")
+	logger.ScanSummary(2, 0, 0, 1, 1, time.Second)
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(events.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("event log should contain JSON lines: %v\nOutput: %s", err, line)
+		}
+		if entry["trace_id"] != logger.TraceID() {
+			t.Errorf("expected trace_id %s, got %v", logger.TraceID(), entry["trace_id"])
+		}
+		if entry["event"] == "outdated_found" && (entry["component"] != "nova" || entry["kube_context"] != "prod") {
+			t.Errorf("expected the logger's fields on the event, got %v", entry)
+		}
+		got = append(got, fmt.Sprint(entry["event"]))
+	}
+	want := []string{"scan_start", "outdated_found", "issue_skipped", "issue_created", "scan_summary"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected events %v, got %v", want, got)
+	}
+
+	// The console keeps its level: only the warning makes it there
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "Outdated component detected") {
+		t.Errorf("expected only the outdated_found warning on stdout, got %q", stdout.String())
+	}
+}
+
+func TestOpenLogFile_Error(t *testing.T) {
+	if _, err := OpenLogFile(filepath.Join(t.TempDir(), "missing", "scanner.log")); err == nil {
+		t.Error("expected error for unwritable log file path")
+	}
+}
+
+func TestLogger_StderrSeparation(t *testing.T) {
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	outR, outW, _ := os.Pipe()
+	errR, errW, _ := os.Pipe()
+	os.Stdout, os.Stderr = outW, errW
+
+	logger := NewLoggerWithWriter("info", "json", Output("stderr"))
+	logger.Info().Msg("scan progress")
+	os.Stdout.WriteString("# Nova Scanner Results\n")
+
+	outW.Close()
+	errW.Close()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+
+	var stdout, stderr bytes.Buffer
+	stdout.ReadFrom(outR)
+	stderr.ReadFrom(errR)
+
+	if stdout.String() != "# Nova Scanner Results\n" {
+		t.Errorf("expected stdout to contain only the report, got %q", stdout.String())
+	}
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal(stderr.Bytes(), &logEntry); err != nil {
+		t.Fatalf("stderr should contain a JSON log line: %v\nOutput: %s", err, stderr.String())
+	}
+	if logEntry["message"] != "scan progress" {
+		t.Errorf("expected message 'scan progress', got %v", logEntry["message"])
+	}
+}
+
 func TestLogger_ScanStart(t *testing.T) {
 	oldStdout := os.Stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	logger := NewLogger("info")
+	logger := NewLogger("info", "json")
 	logger.ScanStart("helm")
 
 	w.Close()
@@ -134,7 +297,7 @@ func TestLogger_ScanEnd(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	logger := NewLogger("info")
+	logger := NewLogger("info", "json")
 	logger.ScanEnd("container", 5*time.Second, 10, 3)
 
 	w.Close()
@@ -163,12 +326,38 @@ func TestLogger_ScanEnd(t *testing.T) {
 	}
 }
 
+func TestLogger_ScanSummary(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithWriter("info", "json", &buf)
+	logger.ScanSummary(4, 3, 2, 5, 1, 90*time.Second)
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"event":               "scan_summary",
+		"outdated_helm":       float64(4),
+		"outdated_containers": float64(3),
+		"skipped_containers":  float64(2),
+		"issues_created":      float64(5),
+		"issues_skipped":      float64(1),
+		"duration":            float64(90000),
+	}
+	for key, want := range expected {
+		if logEntry[key] != want {
+			t.Errorf("expected %s %v, got %v", key, want, logEntry[key])
+		}
+	}
+}
+
 func TestLogger_OutdatedFound(t *testing.T) {
 	oldStdout := os.Stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	logger := NewLogger("warn") // warn level to capture warn logs
+	logger := NewLogger("warn", "json") // warn level to capture warn logs
 	logger.OutdatedFound("helm", "my-release", "default", "1.0.0", "2.0.0")
 
 	w.Close()
@@ -202,7 +391,7 @@ func TestLogger_IssueCreated(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	logger := NewLogger("info")
+	logger := NewLogger("info", "json")
 	logger.IssueCreated("helm", "Test Issue", "https://github.com/test/repo/issues/1")
 
 	w.Close()
@@ -230,7 +419,7 @@ func TestLogger_IssueSkipped(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	logger := NewLogger("debug") // debug level to capture debug logs
+	logger := NewLogger("debug", "json") // debug level to capture debug logs
 	logger.IssueSkipped("helm", "Test Issue", "duplicate")
 
 	w.Close()
@@ -252,3 +441,37 @@ func TestLogger_IssueSkipped(t *testing.T) {
 		t.Errorf("expected reason 'duplicate', got %v", logEntry["reason"])
 	}
 }
+
+func TestLogger_ScanError_Timeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		reason  string
+		err     error
+		timeout bool
+	}{
+		{"timeout", "timeout", fmt.Errorf("nova scan timed out: %w", context.DeadlineExceeded), true},
+		{"failure", "cluster_unreachable", errors.New("exit status 1"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := NewLoggerWithWriter("info", "json", &buf)
+			logger.ScanError("helm", tt.reason, tt.err)
+
+			var logEntry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if logEntry["event"] != "scan_error" {
+				t.Errorf("expected event 'scan_error', got %v", logEntry["event"])
+			}
+			if logEntry["timeout"] != tt.timeout {
+				t.Errorf("expected timeout %v, got %v", tt.timeout, logEntry["timeout"])
+			}
+			if logEntry["reason"] != tt.reason {
+				t.Errorf("expected reason %q, got %v", tt.reason, logEntry["reason"])
+			}
+		})
+	}
+}
@@ -1,132 +1,336 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 )
 
 // Metrics holds all Prometheus metrics for the nova-scanner.
 type Metrics struct {
-	// Gauges
-	OutdatedHelmChartsTotal  prometheus.Gauge
-	OutdatedContainersTotal  prometheus.Gauge
-	ScanLastSuccessTimestamp prometheus.Gauge
+	// Gauges (outdated totals are labeled by kube context)
+	OutdatedHelmChartsTotal       *prometheus.GaugeVec
+	OutdatedContainersTotal       *prometheus.GaugeVec
+	OutdatedHelmByNamespace       *prometheus.GaugeVec // by context and namespace
+	OutdatedContainersByNamespace *prometheus.GaugeVec // by context and namespace of the affected workloads
+	SkippedContainersTotal        *prometheus.GaugeVec
+	UnknownSeverityTotal          *prometheus.GaugeVec // by context and scan type
+	ScanLastSuccessTimestamp      *prometheus.GaugeVec // by scan type
+	GitHubRateLimitRemaining      prometheus.Gauge
+	GitHubRateLimit               prometheus.Gauge
+	NewlyOutdatedTotal            prometheus.Gauge
+	NewlyResolvedTotal            prometheus.Gauge
+	OutdatedAgeSeconds            *prometheus.GaugeVec
 
 	// Info metrics (GaugeVec set to 1)
 	HelmChartVersionInfo *prometheus.GaugeVec
 	ContainerVersionInfo *prometheus.GaugeVec
+	ScannerInfo          *prometheus.GaugeVec
 
 	// Histogram
 	ScanDurationSeconds *prometheus.HistogramVec
 
 	// Counters
-	IssuesCreatedTotal *prometheus.CounterVec
-	ScanErrorsTotal    prometheus.Counter
+	IssuesCreatedTotal  *prometheus.CounterVec
+	ScanErrorsTotal     *prometheus.CounterVec
+	InvalidRecordsTotal *prometheus.CounterVec
 
-	registry *prometheus.Registry
-	pushURL  string
-	jobName  string
+	registry  *prometheus.Registry
+	heartbeat *prometheus.Registry // ScanLastSuccessTimestamp only, see PushHeartbeat
+	pushURL   string
+	jobName   string
+	dryRun    io.Writer    // when set, Push renders metrics here instead of pushing
+	client    *http.Client // nil = http.DefaultClient
+
+	// Pushgateway credentials: basic auth or a bearer token
+	username    string
+	password    string
+	bearerToken string
 }
 
-// NewMetrics creates a new Metrics instance with all metrics registered.
-func NewMetrics(pushgatewayURL, jobName string) *Metrics {
+// DefaultPrefix starts every metric name unless another prefix is given.
+const DefaultPrefix = "nova"
+
+// NewMetrics creates a new Metrics instance with all metrics registered. Metric names start
+// with prefix and an underscore, e.g. "nova_scan_info" (empty = DefaultPrefix).
+func NewMetrics(pushgatewayURL, jobName, prefix string) *Metrics {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
 	registry := prometheus.NewRegistry()
 
 	m := &Metrics{
-		OutdatedHelmChartsTotal: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "nova_outdated_helm_charts_total",
-			Help: "Total number of outdated Helm releases detected",
+		OutdatedHelmChartsTotal: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "outdated_helm_charts_total",
+				Help:      "Total number of outdated Helm releases detected",
+			},
+			[]string{"context"},
+		),
+		OutdatedContainersTotal: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "outdated_containers_total",
+				Help:      "Total number of outdated container images detected",
+			},
+			[]string{"context"},
+		),
+		OutdatedHelmByNamespace: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "outdated_helm_charts_by_namespace",
+				Help:      "Number of outdated Helm releases detected per namespace",
+			},
+			[]string{"context", "namespace"},
+		),
+		OutdatedContainersByNamespace: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "outdated_containers_by_namespace",
+				Help:      "Number of outdated container images detected per namespace of their workloads",
+			},
+			[]string{"context", "namespace"},
+		),
+		SkippedContainersTotal: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "skipped_containers_total",
+				Help:      "Number of outdated container images skipped because their namespace has outdated Helm releases",
+			},
+			[]string{"context"},
+		),
+		UnknownSeverityTotal: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "unknown_severity_total",
+				Help:      "Number of outdated components whose upgrade severity is unknown because their versions are not semver",
+			},
+			[]string{"context", "type"},
+		),
+		ScanLastSuccessTimestamp: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "scan_last_success_timestamp",
+				Help:      "Unix timestamp of the last successful scan, by scan type",
+			},
+			[]string{"type"},
+		),
+		GitHubRateLimitRemaining: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "github_rate_limit_remaining",
+			Help:      "GitHub API requests remaining in the current rate limit window",
+		}),
+		GitHubRateLimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "github_rate_limit",
+			Help:      "GitHub API requests allowed per rate limit window",
 		}),
-		OutdatedContainersTotal: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "nova_outdated_containers_total",
-			Help: "Total number of outdated container images detected",
+		NewlyOutdatedTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "newly_outdated_total",
+			Help:      "Number of components outdated now but not in the previous run",
 		}),
-		ScanLastSuccessTimestamp: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "nova_scan_last_success_timestamp",
-			Help: "Unix timestamp of the last successful scan",
+		NewlyResolvedTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "newly_resolved_total",
+			Help:      "Number of components outdated in the previous run but not anymore",
 		}),
+		OutdatedAgeSeconds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "outdated_age_seconds",
+				Help:      "Seconds since an outdated component was first detected (requires a state file)",
+			},
+			[]string{"type", "context", "namespace", "name"},
+		),
 		HelmChartVersionInfo: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
-				Name: "nova_helm_chart_version_info",
-				Help: "Information about Helm chart versions (value is always 1)",
+				Namespace: prefix,
+				Name:      "helm_chart_version_info",
+				Help:      "Information about Helm chart versions (value is always 1)",
 			},
-			[]string{"release", "namespace", "chart", "current_version", "latest_version", "deprecated"},
+			[]string{"context", "release", "namespace", "chart", "current_version", "latest_version", "deprecated", "helm_version"},
 		),
 		ContainerVersionInfo: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
-				Name: "nova_container_version_info",
-				Help: "Information about container image versions (value is always 1)",
+				Namespace: prefix,
+				Name:      "container_version_info",
+				Help:      "Information about container image versions (value is always 1)",
+			},
+			[]string{"context", "image", "current_tag", "latest_tag"},
+		),
+		ScannerInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: prefix,
+				Name:      "scan_info",
+				Help:      "Scanner version and configuration summary (value is always 1)",
 			},
-			[]string{"image", "current_tag", "latest_tag"},
+			[]string{"version", "min_severity", "scan_helm", "scan_containers"},
 		),
 		ScanDurationSeconds: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
-				Name:    "nova_scan_duration_seconds",
-				Help:    "Duration of scans in seconds",
-				Buckets: prometheus.ExponentialBuckets(1, 2, 8), // 1s to ~4m
+				Namespace: prefix,
+				Name:      "scan_duration_seconds",
+				Help:      "Duration of scans in seconds",
+				Buckets:   prometheus.ExponentialBuckets(1, 2, 8), // 1s to ~4m
 			},
-			[]string{"type"},
+			[]string{"type", "context"},
 		),
 		IssuesCreatedTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "nova_issues_created_total",
-				Help: "Total number of GitHub issues created",
+				Namespace: prefix,
+				Name:      "issues_created_total",
+				Help:      "Total number of GitHub issues created",
 			},
 			[]string{"type"},
 		),
-		ScanErrorsTotal: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "nova_scan_errors_total",
-			Help: "Total number of scan errors",
-		}),
-		registry: registry,
-		pushURL:  pushgatewayURL,
-		jobName:  jobName,
+		ScanErrorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: prefix,
+				Name:      "scan_errors_total",
+				Help:      "Total number of scan errors",
+			},
+			[]string{"reason"},
+		),
+		InvalidRecordsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: prefix,
+				Name:      "invalid_records_total",
+				Help:      "Total number of malformed records in nova's output that were skipped",
+			},
+			[]string{"type"},
+		),
+		registry:  registry,
+		heartbeat: prometheus.NewRegistry(),
+		pushURL:   pushgatewayURL,
+		jobName:   jobName,
 	}
+	m.heartbeat.MustRegister(m.ScanLastSuccessTimestamp)
 
 	// Register all metrics
 	registry.MustRegister(
 		m.OutdatedHelmChartsTotal,
 		m.OutdatedContainersTotal,
+		m.OutdatedHelmByNamespace,
+		m.OutdatedContainersByNamespace,
+		m.SkippedContainersTotal,
+		m.UnknownSeverityTotal,
 		m.ScanLastSuccessTimestamp,
+		m.GitHubRateLimitRemaining,
+		m.GitHubRateLimit,
+		m.NewlyOutdatedTotal,
+		m.NewlyResolvedTotal,
+		m.OutdatedAgeSeconds,
 		m.HelmChartVersionInfo,
 		m.ContainerVersionInfo,
+		m.ScannerInfo,
 		m.ScanDurationSeconds,
 		m.IssuesCreatedTotal,
 		m.ScanErrorsTotal,
+		m.InvalidRecordsTotal,
 	)
 
 	return m
 }
 
-// RecordHelmScan records metrics for a completed Helm scan.
-func (m *Metrics) RecordHelmScan(outdated int, duration time.Duration) {
-	m.OutdatedHelmChartsTotal.Set(float64(outdated))
-	m.ScanDurationSeconds.WithLabelValues("helm").Observe(duration.Seconds())
-	m.ScanLastSuccessTimestamp.SetToCurrentTime()
+// RecordHelmScan records metrics for a completed Helm scan in a kube context.
+func (m *Metrics) RecordHelmScan(kubeContext string, outdated int, duration time.Duration) {
+	m.OutdatedHelmChartsTotal.WithLabelValues(kubeContext).Set(float64(outdated))
+	m.ScanDurationSeconds.WithLabelValues("helm", kubeContext).Observe(duration.Seconds())
+	m.RecordScanSuccess("helm")
+}
+
+// RecordContainerScan records metrics for a completed container scan in a kube context.
+func (m *Metrics) RecordContainerScan(kubeContext string, outdated int, duration time.Duration) {
+	m.OutdatedContainersTotal.WithLabelValues(kubeContext).Set(float64(outdated))
+	m.ScanDurationSeconds.WithLabelValues("container", kubeContext).Observe(duration.Seconds())
+	m.RecordScanSuccess("container")
+}
+
+// RecordScanSuccess sets the last success timestamp of a scan type ("helm" or "container") to now.
+func (m *Metrics) RecordScanSuccess(scanType string) {
+	m.ScanLastSuccessTimestamp.WithLabelValues(scanType).SetToCurrentTime()
+}
+
+// RecordOutdatedHelmByNamespace records the number of outdated Helm releases per namespace in a kube context.
+func (m *Metrics) RecordOutdatedHelmByNamespace(kubeContext string, counts map[string]int) {
+	for namespace, count := range counts {
+		m.OutdatedHelmByNamespace.WithLabelValues(kubeContext, namespace).Set(float64(count))
+	}
+}
+
+// RecordOutdatedContainersByNamespace records the number of outdated container images per
+// namespace in a kube context.
+func (m *Metrics) RecordOutdatedContainersByNamespace(kubeContext string, counts map[string]int) {
+	for namespace, count := range counts {
+		m.OutdatedContainersByNamespace.WithLabelValues(kubeContext, namespace).Set(float64(count))
+	}
+}
+
+// RecordSkippedContainers records the number of containers skipped by Helm dedup in a kube context.
+func (m *Metrics) RecordSkippedContainers(kubeContext string, count int) {
+	m.SkippedContainersTotal.WithLabelValues(kubeContext).Set(float64(count))
 }
 
-// RecordContainerScan records metrics for a completed container scan.
-func (m *Metrics) RecordContainerScan(outdated int, duration time.Duration) {
-	m.OutdatedContainersTotal.Set(float64(outdated))
-	m.ScanDurationSeconds.WithLabelValues("container").Observe(duration.Seconds())
-	m.ScanLastSuccessTimestamp.SetToCurrentTime()
+// RecordUnknownSeverity records the number of outdated components of a scan type ("helm" or
+// "container") in a kube context whose upgrade severity is unknown.
+func (m *Metrics) RecordUnknownSeverity(kubeContext, scanType string, count int) {
+	m.UnknownSeverityTotal.WithLabelValues(kubeContext, scanType).Set(float64(count))
 }
 
-// RecordHelmChartInfo records version info for a Helm release.
-func (m *Metrics) RecordHelmChartInfo(release, namespace, chart, currentVersion, latestVersion string, deprecated bool) {
+// RecordHelmChartInfo records version info for a Helm release. helmVersion is the Helm major
+// version nova reports for the release, empty if unknown.
+func (m *Metrics) RecordHelmChartInfo(kubeContext, release, namespace, chart, currentVersion, latestVersion string, deprecated bool, helmVersion string) {
 	deprecatedStr := "false"
 	if deprecated {
 		deprecatedStr = "true"
 	}
-	m.HelmChartVersionInfo.WithLabelValues(release, namespace, chart, currentVersion, latestVersion, deprecatedStr).Set(1)
+	m.HelmChartVersionInfo.WithLabelValues(kubeContext, release, namespace, chart, currentVersion, latestVersion, deprecatedStr, helmVersion).Set(1)
 }
 
 // RecordContainerInfo records version info for a container image.
-func (m *Metrics) RecordContainerInfo(image, currentTag, latestTag string) {
-	m.ContainerVersionInfo.WithLabelValues(image, currentTag, latestTag).Set(1)
+func (m *Metrics) RecordContainerInfo(kubeContext, image, currentTag, latestTag string) {
+	m.ContainerVersionInfo.WithLabelValues(kubeContext, image, currentTag, latestTag).Set(1)
+}
+
+// RecordScannerInfo records the scanner version and configuration summary.
+// It describes the process rather than a scan, so Reset leaves it in place.
+func (m *Metrics) RecordScannerInfo(version, minSeverity string, scanHelm, scanContainers bool) {
+	m.ScannerInfo.Reset()
+	m.ScannerInfo.WithLabelValues(version, minSeverity, strconv.FormatBool(scanHelm), strconv.FormatBool(scanContainers)).Set(1)
+}
+
+// RecordRateLimit records the GitHub API rate limit quota reported by the latest response.
+func (m *Metrics) RecordRateLimit(remaining, limit int) {
+	m.GitHubRateLimitRemaining.Set(float64(remaining))
+	m.GitHubRateLimit.Set(float64(limit))
+}
+
+// RecordInvalidRecords adds the malformed records of a scan ("helm" or "container") that were skipped.
+func (m *Metrics) RecordInvalidRecords(scanType string, count int) {
+	m.InvalidRecordsTotal.WithLabelValues(scanType).Add(float64(count))
+}
+
+// RecordOutdatedDiff records how many components became outdated or were resolved since the previous run.
+func (m *Metrics) RecordOutdatedDiff(newlyOutdated, resolved int) {
+	m.NewlyOutdatedTotal.Set(float64(newlyOutdated))
+	m.NewlyResolvedTotal.Set(float64(resolved))
+}
+
+// RecordOutdatedAge records how long a component ("helm" or "container") has been outdated.
+// Images have no namespace; name is the release or image name.
+func (m *Metrics) RecordOutdatedAge(componentType, kubeContext, namespace, name string, age time.Duration) {
+	m.OutdatedAgeSeconds.WithLabelValues(componentType, kubeContext, namespace, name).Set(age.Seconds())
 }
 
 // RecordIssueCreated increments the issues created counter.
@@ -134,27 +338,149 @@ func (m *Metrics) RecordIssueCreated(issueType string) {
 	m.IssuesCreatedTotal.WithLabelValues(issueType).Inc()
 }
 
-// RecordError increments the error counter.
-func (m *Metrics) RecordError() {
-	m.ScanErrorsTotal.Inc()
+// RecordError increments the error counter for a failure class (see nova.ErrorReason).
+func (m *Metrics) RecordError(reason string) {
+	m.ScanErrorsTotal.WithLabelValues(reason).Inc()
 }
 
-// Reset clears the version info metrics before a new scan.
+// Reset clears the per-context and version info metrics before a new scan.
 func (m *Metrics) Reset() {
+	m.OutdatedHelmChartsTotal.Reset()
+	m.OutdatedContainersTotal.Reset()
+	m.OutdatedHelmByNamespace.Reset()
+	m.OutdatedContainersByNamespace.Reset()
+	m.SkippedContainersTotal.Reset()
+	m.UnknownSeverityTotal.Reset()
 	m.HelmChartVersionInfo.Reset()
 	m.ContainerVersionInfo.Reset()
+	m.OutdatedAgeSeconds.Reset()
+}
+
+// SetDryRun makes Push write the metrics it would push to w instead of sending them.
+func (m *Metrics) SetDryRun(w io.Writer) {
+	m.dryRun = w
+}
+
+// SetBasicAuth configures basic auth credentials for the Pushgateway.
+func (m *Metrics) SetBasicAuth(username, password string) {
+	m.username = username
+	m.password = password
+}
+
+// SetBearerToken configures a bearer token sent in the Authorization header to the Pushgateway.
+func (m *Metrics) SetBearerToken(token string) {
+	m.bearerToken = token
+}
+
+// SetTransport makes Push send its requests through transport, e.g. one using a proxy.
+func (m *Metrics) SetTransport(transport http.RoundTripper) {
+	m.client = &http.Client{Transport: transport}
+}
+
+// Render writes all gathered metrics to w in the Prometheus text exposition format.
+func (m *Metrics) Render(w io.Writer) error {
+	return render(w, m.registry)
+}
+
+// render writes the metrics gathered from g to w in the Prometheus text exposition format.
+func render(w io.Writer, g prometheus.Gatherer) error {
+	families, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+			return fmt.Errorf("failed to render metrics: %w", err)
+		}
+	}
+	return nil
 }
 
-// Push pushes all metrics to the Pushgateway.
-func (m *Metrics) Push() error {
+// Push pushes all metrics to the Pushgateway, or renders them to the dry-run writer.
+// Canceling ctx aborts the push.
+func (m *Metrics) Push(ctx context.Context) error {
+	return m.push(ctx, m.registry)
+}
+
+// PushHeartbeat pushes only the last success timestamps, as a dead man's switch for alerting
+// when the scanner stops running. It leaves the metrics of earlier pushes to the job alone.
+func (m *Metrics) PushHeartbeat(ctx context.Context) error {
+	return m.push(ctx, m.heartbeat)
+}
+
+// push pushes the metrics gathered from g to the Pushgateway, or renders them to the dry-run writer.
+// The last success timestamps go to a group per scan type rather than the job's group, which
+// each push replaces: a run whose scan of one type failed thus keeps that type's last success.
+func (m *Metrics) push(ctx context.Context, g prometheus.Gatherer) error {
 	if m.pushURL == "" {
 		return nil
 	}
 
-	pusher := push.New(m.pushURL, m.jobName).Gatherer(m.registry)
-	if err := pusher.Push(); err != nil {
-		return fmt.Errorf("failed to push metrics: %w", err)
+	if m.dryRun != nil {
+		return render(m.dryRun, g)
+	}
+
+	timestamps, err := m.heartbeat.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+	if g != m.heartbeat {
+		if err := m.pusher(withoutFamilies(g, timestamps)).PushContext(ctx); err != nil {
+			return fmt.Errorf("failed to push metrics: %w", err)
+		}
+	}
+	for _, family := range timestamps {
+		for _, metric := range family.GetMetric() {
+			scanType, single := splitGroupingLabel(family, metric, "type")
+			pusher := m.pusher(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+				return []*dto.MetricFamily{single}, nil
+			})).Grouping("type", scanType)
+			if err := pusher.PushContext(ctx); err != nil {
+				return fmt.Errorf("failed to push metrics: %w", err)
+			}
+		}
 	}
 
 	return nil
 }
+
+// pusher returns a Pusher of the metrics gathered from g to the job's group, with the
+// configured client and credentials.
+func (m *Metrics) pusher(g prometheus.Gatherer) *push.Pusher {
+	pusher := push.New(m.pushURL, m.jobName).Gatherer(g)
+	if m.client != nil {
+		pusher = pusher.Client(m.client)
+	}
+	if m.username != "" {
+		pusher = pusher.BasicAuth(m.username, m.password)
+	}
+	if m.bearerToken != "" {
+		pusher = pusher.Header(http.Header{"Authorization": []string{"Bearer " + m.bearerToken}})
+	}
+	return pusher
+}
+
+// withoutFamilies returns a gatherer of the metric families of g not named in excluded.
+func withoutFamilies(g prometheus.Gatherer, excluded []*dto.MetricFamily) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+		return slices.DeleteFunc(families, func(family *dto.MetricFamily) bool {
+			return slices.ContainsFunc(excluded, func(e *dto.MetricFamily) bool { return e.GetName() == family.GetName() })
+		}), err
+	})
+}
+
+// splitGroupingLabel returns the value of label in metric and a copy of family holding just
+// metric without label, which the Pushgateway adds back from the grouping key.
+func splitGroupingLabel(family *dto.MetricFamily, metric *dto.Metric, label string) (string, *dto.MetricFamily) {
+	var value string
+	single := &dto.Metric{Gauge: metric.Gauge, Counter: metric.Counter, Untyped: metric.Untyped, TimestampMs: metric.TimestampMs}
+	for _, pair := range metric.GetLabel() {
+		if pair.GetName() == label {
+			value = pair.GetValue()
+			continue
+		}
+		single.Label = append(single.Label, pair)
+	}
+	return value, &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type, Metric: []*dto.Metric{single}}
+}
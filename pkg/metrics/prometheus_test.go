@@ -1,6 +1,15 @@
 package metrics
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,7 +18,7 @@ import (
 )
 
 func TestNewMetrics(t *testing.T) {
-	m := NewMetrics("http://localhost:9091", "test-job")
+	m := NewMetrics("http://localhost:9091", "test-job", "")
 
 	if m == nil {
 		t.Fatal("expected non-nil Metrics")
@@ -25,40 +34,137 @@ func TestNewMetrics(t *testing.T) {
 	}
 }
 
+func TestNewMetrics_Prefix(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		want   string
+	}{
+		{"", "nova_scan_info"},
+		{"nova_staging", "nova_staging_scan_info"},
+	} {
+		m := NewMetrics("", "test", tt.prefix)
+		m.RecordScannerInfo("1.0.0", "minor", true, false)
+		m.RecordHelmScan("", 1, time.Second)
+
+		families, err := m.registry.Gather()
+		if err != nil {
+			t.Fatalf("failed to gather metrics: %v", err)
+		}
+		var names []string
+		for _, family := range families {
+			names = append(names, family.GetName())
+			if !strings.HasPrefix(family.GetName(), strings.TrimSuffix(tt.want, "scan_info")) {
+				t.Errorf("prefix %q: unexpected metric name %s", tt.prefix, family.GetName())
+			}
+		}
+		if !slices.Contains(names, tt.want) {
+			t.Errorf("prefix %q: expected %s among %v", tt.prefix, tt.want, names)
+		}
+	}
+}
+
 func TestMetrics_RecordHelmScan(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
-	m.RecordHelmScan(5, 10*time.Second)
+	m.RecordHelmScan("", 5, 10*time.Second)
 
 	// Check outdated count
-	val := getGaugeValue(t, m.OutdatedHelmChartsTotal)
+	val := getGaugeValue(t, m.OutdatedHelmChartsTotal.WithLabelValues(""))
 	if val != 5 {
 		t.Errorf("expected OutdatedHelmChartsTotal to be 5, got %f", val)
 	}
 
 	// Check that last success timestamp was set
-	ts := getGaugeValue(t, m.ScanLastSuccessTimestamp)
+	ts := getGaugeValue(t, m.ScanLastSuccessTimestamp.WithLabelValues("helm"))
 	if ts <= 0 {
 		t.Error("expected ScanLastSuccessTimestamp to be set")
 	}
 }
 
 func TestMetrics_RecordContainerScan(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
-	m.RecordContainerScan(3, 5*time.Second)
+	m.RecordContainerScan("", 3, 5*time.Second)
 
-	val := getGaugeValue(t, m.OutdatedContainersTotal)
+	val := getGaugeValue(t, m.OutdatedContainersTotal.WithLabelValues(""))
 	if val != 3 {
 		t.Errorf("expected OutdatedContainersTotal to be 3, got %f", val)
 	}
 }
 
+func TestMetrics_ScanLastSuccessTimestamp_ByType(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordHelmScan("", 1, time.Second)
+	helm := getGaugeValue(t, m.ScanLastSuccessTimestamp.WithLabelValues("helm"))
+	if helm <= 0 {
+		t.Fatal("expected the helm timestamp to be set")
+	}
+	ch := make(chan prometheus.Metric, 10)
+	m.ScanLastSuccessTimestamp.Collect(ch)
+	close(ch)
+	if len(ch) != 1 {
+		t.Errorf("expected only the helm timestamp before a container scan, got %d series", len(ch))
+	}
+
+	m.ScanLastSuccessTimestamp.WithLabelValues("helm").Set(1000)
+	m.RecordContainerScan("", 1, time.Second)
+	if got := getGaugeValue(t, m.ScanLastSuccessTimestamp.WithLabelValues("helm")); got != 1000 {
+		t.Errorf("expected a container scan to leave the helm timestamp alone, got %f", got)
+	}
+	if got := getGaugeValue(t, m.ScanLastSuccessTimestamp.WithLabelValues("container")); got < helm {
+		t.Errorf("expected the container timestamp to be set, got %f", got)
+	}
+
+	// Timestamps survive the per-cycle reset in daemon mode
+	m.Reset()
+	ch = make(chan prometheus.Metric, 10)
+	m.ScanLastSuccessTimestamp.Collect(ch)
+	close(ch)
+	if len(ch) != 2 {
+		t.Errorf("expected both timestamps to survive Reset, got %d series", len(ch))
+	}
+}
+
+func TestMetrics_RecordSkippedContainers(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordSkippedContainers("prod", 4)
+
+	val := getGaugeValue(t, m.SkippedContainersTotal.WithLabelValues("prod"))
+	if val != 4 {
+		t.Errorf("expected SkippedContainersTotal to be 4, got %f", val)
+	}
+}
+
+func TestMetrics_RecordScan_PerContext(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordHelmScan("prod", 4, time.Second)
+	m.RecordHelmScan("staging", 1, time.Second)
+	m.RecordContainerScan("prod", 2, time.Second)
+
+	if val := getGaugeValue(t, m.OutdatedHelmChartsTotal.WithLabelValues("prod")); val != 4 {
+		t.Errorf("expected prod OutdatedHelmChartsTotal to be 4, got %f", val)
+	}
+	if val := getGaugeValue(t, m.OutdatedHelmChartsTotal.WithLabelValues("staging")); val != 1 {
+		t.Errorf("expected staging OutdatedHelmChartsTotal to be 1, got %f", val)
+	}
+	if val := getGaugeValue(t, m.OutdatedContainersTotal.WithLabelValues("prod")); val != 2 {
+		t.Errorf("expected prod OutdatedContainersTotal to be 2, got %f", val)
+	}
+
+	m.RecordHelmChartInfo("prod", "my-release", "default", "my-chart", "1.0.0", "2.0.0", false, "3")
+	if _, err := m.HelmChartVersionInfo.GetMetricWithLabelValues("prod", "my-release", "default", "my-chart", "1.0.0", "2.0.0", "false", "3"); err != nil {
+		t.Errorf("expected helm chart info labeled with context: %v", err)
+	}
+}
+
 func TestMetrics_RecordHelmChartInfo(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
-	m.RecordHelmChartInfo("my-release", "default", "my-chart", "1.0.0", "2.0.0", false)
-	m.RecordHelmChartInfo("deprecated-release", "kube-system", "old-chart", "0.1.0", "1.0.0", true)
+	m.RecordHelmChartInfo("", "my-release", "default", "my-chart", "1.0.0", "2.0.0", false, "3")
+	m.RecordHelmChartInfo("", "deprecated-release", "kube-system", "old-chart", "0.1.0", "1.0.0", true, "2")
 
 	// Collect metrics
 	ch := make(chan prometheus.Metric, 10)
@@ -73,13 +179,33 @@ func TestMetrics_RecordHelmChartInfo(t *testing.T) {
 	if count != 2 {
 		t.Errorf("expected 2 helm chart info metrics, got %d", count)
 	}
+	if _, err := m.HelmChartVersionInfo.GetMetricWithLabelValues("", "deprecated-release", "kube-system", "old-chart", "0.1.0", "1.0.0", "true", "2"); err != nil {
+		t.Errorf("expected helm chart info labeled with the helm version: %v", err)
+	}
+}
+
+func TestMetrics_RecordUnknownSeverity(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordUnknownSeverity("prod", "container", 2)
+	if val := getGaugeValue(t, m.UnknownSeverityTotal.WithLabelValues("prod", "container")); val != 2 {
+		t.Errorf("expected 2 containers of unknown severity, got %f", val)
+	}
+
+	m.Reset()
+	ch := make(chan prometheus.Metric, 10)
+	m.UnknownSeverityTotal.Collect(ch)
+	close(ch)
+	if len(ch) != 0 {
+		t.Errorf("expected no unknown severity series after Reset, got %d", len(ch))
+	}
 }
 
 func TestMetrics_RecordContainerInfo(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
-	m.RecordContainerInfo("nginx", "1.20", "1.25")
-	m.RecordContainerInfo("redis", "6.0", "7.0")
+	m.RecordContainerInfo("", "nginx", "1.20", "1.25")
+	m.RecordContainerInfo("", "redis", "6.0", "7.0")
 
 	ch := make(chan prometheus.Metric, 10)
 	m.ContainerVersionInfo.Collect(ch)
@@ -96,7 +222,7 @@ func TestMetrics_RecordContainerInfo(t *testing.T) {
 }
 
 func TestMetrics_RecordIssueCreated(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
 	m.RecordIssueCreated("helm")
 	m.RecordIssueCreated("helm")
@@ -115,25 +241,129 @@ func TestMetrics_RecordIssueCreated(t *testing.T) {
 	}
 }
 
+func TestMetrics_RecordRateLimit(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordRateLimit(4321, 5000)
+
+	if got := getGaugeValue(t, m.GitHubRateLimitRemaining); got != 4321 {
+		t.Errorf("expected rate limit remaining to be 4321, got %f", got)
+	}
+	if got := getGaugeValue(t, m.GitHubRateLimit); got != 5000 {
+		t.Errorf("expected rate limit to be 5000, got %f", got)
+	}
+}
+
 func TestMetrics_RecordError(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
-	m.RecordError()
-	m.RecordError()
-	m.RecordError()
+	m.RecordError("cluster_unreachable")
+	m.RecordError("cluster_unreachable")
+	m.RecordError("parse")
 
-	val := getCounterValueSimple(t, m.ScanErrorsTotal)
-	if val != 3 {
-		t.Errorf("expected error count to be 3, got %f", val)
+	if val := getCounterValue(t, m.ScanErrorsTotal, "cluster_unreachable"); val != 2 {
+		t.Errorf("expected cluster_unreachable error count to be 2, got %f", val)
+	}
+	if val := getCounterValue(t, m.ScanErrorsTotal, "parse"); val != 1 {
+		t.Errorf("expected parse error count to be 1, got %f", val)
+	}
+}
+
+func TestMetrics_RecordInvalidRecords(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordInvalidRecords("container", 2)
+	m.RecordInvalidRecords("container", 1)
+	m.RecordInvalidRecords("helm", 0)
+
+	if val := getCounterValue(t, m.InvalidRecordsTotal, "container"); val != 3 {
+		t.Errorf("expected 3 invalid container records, got %f", val)
+	}
+	if val := getCounterValue(t, m.InvalidRecordsTotal, "helm"); val != 0 {
+		t.Errorf("expected 0 invalid helm records, got %f", val)
+	}
+}
+
+func TestMetrics_RecordOutdatedAge(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordOutdatedAge("helm", "prod", "default", "app", 36*time.Hour)
+	m.RecordOutdatedAge("container", "prod", "", "nginx", time.Minute)
+
+	if val := getGaugeValue(t, m.OutdatedAgeSeconds.WithLabelValues("helm", "prod", "default", "app")); val != 129600 {
+		t.Errorf("expected helm age of 129600s, got %f", val)
+	}
+	if val := getGaugeValue(t, m.OutdatedAgeSeconds.WithLabelValues("container", "prod", "", "nginx")); val != 60 {
+		t.Errorf("expected container age of 60s, got %f", val)
+	}
+
+	// Components no longer outdated must not keep reporting their age
+	m.Reset()
+	ch := make(chan prometheus.Metric, 10)
+	m.OutdatedAgeSeconds.Collect(ch)
+	close(ch)
+	if len(ch) != 0 {
+		t.Errorf("expected no outdated ages after reset, got %d", len(ch))
+	}
+}
+
+func TestMetrics_RecordScannerInfo(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordScannerInfo("v1.2.3", "major", true, false)
+	m.Reset() // process-level info survives the per-scan reset
+
+	val := getGaugeValue(t, m.ScannerInfo.WithLabelValues("v1.2.3", "major", "true", "false"))
+	if val != 1 {
+		t.Errorf("expected ScannerInfo to be 1, got %f", val)
+	}
+
+	var buf bytes.Buffer
+	if err := m.Render(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `nova_scan_info{min_severity="major",scan_containers="false",scan_helm="true",version="v1.2.3"} 1`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in rendered metrics, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMetrics_RecordOutdatedByNamespace(t *testing.T) {
+	m := NewMetrics("", "test", "")
+
+	m.RecordOutdatedHelmByNamespace("prod", map[string]int{"team-a": 2, "team-b": 1})
+	m.RecordOutdatedContainersByNamespace("prod", map[string]int{"team-a": 3})
+
+	if val := getGaugeValue(t, m.OutdatedHelmByNamespace.WithLabelValues("prod", "team-a")); val != 2 {
+		t.Errorf("expected 2 outdated releases in team-a, got %f", val)
+	}
+	if val := getGaugeValue(t, m.OutdatedHelmByNamespace.WithLabelValues("prod", "team-b")); val != 1 {
+		t.Errorf("expected 1 outdated release in team-b, got %f", val)
+	}
+	if val := getGaugeValue(t, m.OutdatedContainersByNamespace.WithLabelValues("prod", "team-a")); val != 3 {
+		t.Errorf("expected 3 outdated images in team-a, got %f", val)
+	}
+
+	// Namespaces without outdated components in the next run must not keep their series
+	m.Reset()
+	m.RecordOutdatedHelmByNamespace("prod", map[string]int{"team-a": 1})
+	m.RecordOutdatedContainersByNamespace("prod", nil)
+
+	ch := make(chan prometheus.Metric, 10)
+	m.OutdatedHelmByNamespace.Collect(ch)
+	m.OutdatedContainersByNamespace.Collect(ch)
+	close(ch)
+	if len(ch) != 1 {
+		t.Errorf("expected only the team-a helm series after reset, got %d series", len(ch))
 	}
 }
 
 func TestMetrics_Reset(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
 	// Add some metrics
-	m.RecordHelmChartInfo("release1", "ns1", "chart1", "1.0", "2.0", false)
-	m.RecordContainerInfo("image1", "1.0", "2.0")
+	m.RecordHelmChartInfo("", "release1", "ns1", "chart1", "1.0", "2.0", false, "3")
+	m.RecordContainerInfo("", "image1", "1.0", "2.0")
 
 	// Reset
 	m.Reset()
@@ -154,15 +384,182 @@ func TestMetrics_Reset(t *testing.T) {
 }
 
 func TestMetrics_Push_NoURL(t *testing.T) {
-	m := NewMetrics("", "test")
+	m := NewMetrics("", "test", "")
 
 	// Should not error when pushURL is empty
-	err := m.Push()
+	err := m.Push(context.Background())
 	if err != nil {
 		t.Errorf("expected no error when pushURL is empty, got %v", err)
 	}
 }
 
+func TestMetrics_Push_CanceledContext(t *testing.T) {
+	var pushed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushed = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := NewMetrics(server.URL, "test", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := m.Push(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if pushed {
+		t.Error("expected no push with a canceled context")
+	}
+}
+
+func TestMetrics_PushHeartbeat(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := NewMetrics(server.URL, "test", "")
+	m.RecordHelmChartInfo("", "release", "default", "chart", "1.0.0", "2.0.0", false, "3")
+	m.RecordScanSuccess("helm")
+	if err := m.PushHeartbeat(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Contains(body, []byte("nova_scan_last_success_timestamp")) {
+		t.Errorf("expected the timestamp to be pushed, got %q", body)
+	}
+	if bytes.Contains(body, []byte("nova_helm_chart_version_info")) {
+		t.Errorf("expected only the timestamp to be pushed, got %q", body)
+	}
+}
+
+func TestMetrics_Push_TimestampsPerScanType(t *testing.T) {
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies[r.Method+" "+r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The container scan failed this run, so only the helm timestamp is set
+	m := NewMetrics(server.URL, "test", "")
+	m.RecordHelmScan("", 1, time.Second)
+	if err := m.Push(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("expected a push to the job's group and one to the helm group, got %v", bodies)
+	}
+	if job := bodies["PUT /metrics/job/test"]; !strings.Contains(job, "nova_outdated_helm_charts_total") ||
+		strings.Contains(job, "nova_scan_last_success_timestamp") {
+		t.Errorf("expected the job's group without the timestamps, got %q", job)
+	}
+	if helm := bodies["PUT /metrics/job/test/type/helm"]; !strings.Contains(helm, "nova_scan_last_success_timestamp") {
+		t.Errorf("expected the helm timestamp in its own group, got %q", helm)
+	}
+}
+
+func TestMetrics_Push_DryRun(t *testing.T) {
+	var pushed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushed = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := NewMetrics(server.URL, "test", "")
+	m.RecordHelmScan("", 3, time.Second)
+	m.RecordHelmChartInfo("", "release", "default", "chart", "1.0.0", "2.0.0", false, "3")
+
+	var buf bytes.Buffer
+	m.SetDryRun(&buf)
+	if err := m.Push(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pushed {
+		t.Error("expected no push to the Pushgateway in dry-run")
+	}
+	for _, name := range []string{"nova_outdated_helm_charts_total", "nova_helm_chart_version_info", "nova_scan_duration_seconds"} {
+		if !strings.Contains(buf.String(), name) {
+			t.Errorf("expected rendered metrics to include %s, got:\n%s", name, buf.String())
+		}
+	}
+}
+
+func TestMetrics_Push(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := NewMetrics(server.URL, "test", "")
+	if err := m.Push(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPath != "/metrics/job/test" {
+		t.Errorf("expected push to /metrics/job/test, got %q", gotPath)
+	}
+}
+
+func TestMetrics_Push_Transport(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	m := NewMetrics("http://pushgateway.example.test:9091", "test", "")
+	m.SetTransport(&http.Transport{Proxy: http.ProxyURL(proxyURL)})
+	if err := m.Push(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxied != "http://pushgateway.example.test:9091/metrics/job/test" {
+		t.Errorf("expected the push to go through the proxy, got %q", proxied)
+	}
+}
+
+func TestMetrics_Push_Auth(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *Metrics)
+		want  string
+	}{
+		{"basic auth", func(m *Metrics) { m.SetBasicAuth("nova", "s3cret") }, "Basic bm92YTpzM2NyZXQ="},
+		{"bearer token", func(m *Metrics) { m.SetBearerToken("abc123") }, "Bearer abc123"},
+		{"no credentials", func(m *Metrics) {}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAuth = r.Header.Get("Authorization")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			m := NewMetrics(server.URL, "test", "")
+			tt.setup(m)
+			if err := m.Push(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotAuth != tt.want {
+				t.Errorf("expected Authorization %q, got %q", tt.want, gotAuth)
+			}
+		})
+	}
+}
+
 // Helper functions
 
 func getGaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
@@ -201,19 +598,3 @@ func getCounterValue(t *testing.T, counterVec *prometheus.CounterVec, labelValue
 
 	return m.GetCounter().GetValue()
 }
-
-func getCounterValueSimple(t *testing.T, counter prometheus.Counter) float64 {
-	t.Helper()
-
-	ch := make(chan prometheus.Metric, 1)
-	counter.Collect(ch)
-	close(ch)
-
-	metric := <-ch
-	var m dto.Metric
-	if err := metric.Write(&m); err != nil {
-		t.Fatalf("failed to write metric: %v", err)
-	}
-
-	return m.GetCounter().GetValue()
-}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// notifyTimeout bounds each notification request.
+const notifyTimeout = 5 * time.Second
+
+// Summary describes the outcome of a scan run.
+type Summary struct {
+	HelmReleases      []nova.ReleaseOutput   `json:"helmReleases"`
+	Containers        []nova.ContainerOutput `json:"containers"`
+	SkippedContainers int                    `json:"skippedContainers"` // outdated images left to their Helm release's update
+	CreatedIssueURLs  []string               `json:"createdIssueUrls"`
+}
+
+// Notifier sends a scan summary to an external system.
+type Notifier interface {
+	Notify(ctx context.Context, summary Summary) error
+}
+
+// NewNotifiers returns a notifier for each sink configured in cfg.
+func NewNotifiers(cfg *config.Config, logger *logging.Logger) []Notifier {
+	var notifiers []Notifier
+	if cfg.SlackWebhookURL != "" {
+		notifiers = append(notifiers, NewSlackNotifier(cfg, logger))
+	}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(cfg, logger))
+	}
+	return notifiers
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/httpclient"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+)
+
+// SlackNotifier posts scan summaries to a Slack incoming webhook.
+type SlackNotifier struct {
+	webhookURL string
+	dryRun     bool
+	client     *http.Client
+	logger     *logging.Logger
+}
+
+// slackMessage is the incoming webhook payload.
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// NewSlackNotifier creates a new SlackNotifier instance.
+func NewSlackNotifier(cfg *config.Config, logger *logging.Logger) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: cfg.SlackWebhookURL,
+		dryRun:     cfg.DryRun,
+		client:     &http.Client{Timeout: notifyTimeout, Transport: httpclient.FromConfig(cfg)},
+		logger:     logger.WithComponent("slack"),
+	}
+}
+
+// Notify posts a summary of the scan run to Slack.
+// In dry-run mode the payload is logged instead of sent.
+func (n *SlackNotifier) Notify(ctx context.Context, summary Summary) error {
+	payload, err := json.Marshal(slackMessage{Text: formatSlackText(summary)})
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+
+	if n.dryRun {
+		n.logger.Info().
+			RawJSON("payload", payload).
+			Msg("Would send Slack notification (dry-run mode)")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send slack notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	}
+
+	n.logger.Info().Msg("Slack notification sent")
+	return nil
+}
+
+func formatSlackText(summary Summary) string {
+	var sb strings.Builder
+	sb.WriteString("*Nova scan completed*\n")
+	sb.WriteString(fmt.Sprintf("• Outdated Helm releases: %d\n", len(summary.HelmReleases)))
+	sb.WriteString(fmt.Sprintf("• Outdated container images: %d\n", len(summary.Containers)))
+
+	if len(summary.CreatedIssueURLs) > 0 {
+		sb.WriteString(fmt.Sprintf("\n*New issues (%d):*\n", len(summary.CreatedIssueURLs)))
+		for _, url := range summary.CreatedIssueURLs {
+			sb.WriteString(fmt.Sprintf("• <%s>\n", url))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func testSummary() Summary {
+	return Summary{
+		HelmReleases: []nova.ReleaseOutput{
+			{ReleaseName: "ingress", Namespace: "ingress-system"},
+			{ReleaseName: "cert-manager", Namespace: "cert-manager"},
+		},
+		Containers: []nova.ContainerOutput{
+			{Name: "nginx", CurrentTag: "1.20", LatestTag: "1.25"},
+		},
+		CreatedIssueURLs: []string{"https://github.com/owner/repo/issues/1"},
+	}
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var received map[string]interface{}
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("payload should be valid JSON: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(&config.Config{SlackWebhookURL: server.URL}, logging.NewLogger("error", "json"))
+	if err := notifier.Notify(context.Background(), testSummary()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("expected JSON content type, got %q", contentType)
+	}
+	if len(received) != 1 {
+		t.Errorf("expected payload with only a text field, got %v", received)
+	}
+	text, ok := received["text"].(string)
+	if !ok {
+		t.Fatalf("expected text field in payload, got %v", received)
+	}
+	for _, want := range []string{
+		"Outdated Helm releases: 2",
+		"Outdated container images: 1",
+		"<https://github.com/owner/repo/issues/1>",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected text to contain %q, got %q", want, text)
+		}
+	}
+}
+
+func TestSlackNotifier_DryRun(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{SlackWebhookURL: server.URL, DryRun: true}
+	notifier := NewSlackNotifier(cfg, logging.NewLogger("error", "json"))
+	if err := notifier.Notify(context.Background(), testSummary()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected no request in dry-run mode")
+	}
+}
+
+func TestSlackNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(&config.Config{SlackWebhookURL: server.URL}, logging.NewLogger("error", "json"))
+	if err := notifier.Notify(context.Background(), testSummary()); err == nil {
+		t.Fatal("expected error for non-200 response")
+	}
+}
+
+func TestFormatSlackText_NoIssues(t *testing.T) {
+	text := formatSlackText(Summary{})
+
+	if !strings.Contains(text, "Outdated Helm releases: 0") {
+		t.Errorf("expected zero Helm count, got %q", text)
+	}
+	if strings.Contains(text, "New issues") {
+		t.Errorf("expected no issue section, got %q", text)
+	}
+}
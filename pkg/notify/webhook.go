@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/httpclient"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+)
+
+// WebhookNotifier posts the full scan summary as JSON to a generic HTTP endpoint.
+type WebhookNotifier struct {
+	url     string
+	headers map[string]string
+	dryRun  bool
+	client  *http.Client
+	logger  *logging.Logger
+}
+
+// webhookPayload is the JSON document sent once per scan run.
+type webhookPayload struct {
+	Timestamp          time.Time `json:"timestamp"`
+	OutdatedHelmCount  int       `json:"outdatedHelmCount"`
+	OutdatedImageCount int       `json:"outdatedImageCount"`
+	Summary
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier instance.
+func NewWebhookNotifier(cfg *config.Config, logger *logging.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:     cfg.WebhookURL,
+		headers: cfg.WebhookHeaders,
+		dryRun:  cfg.DryRun,
+		client:  &http.Client{Timeout: notifyTimeout, Transport: httpclient.FromConfig(cfg)},
+		logger:  logger.WithComponent("webhook"),
+	}
+}
+
+// Notify posts the scan summary to the webhook, retrying once on a 5xx response.
+// In dry-run mode the payload is logged instead of sent.
+func (n *WebhookNotifier) Notify(ctx context.Context, summary Summary) error {
+	payload, err := json.Marshal(webhookPayload{
+		Timestamp:          time.Now().UTC(),
+		OutdatedHelmCount:  len(summary.HelmReleases),
+		OutdatedImageCount: len(summary.Containers),
+		Summary:            summary,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	if n.dryRun {
+		n.logger.Info().
+			RawJSON("payload", payload).
+			Msg("Would send webhook notification (dry-run mode)")
+		return nil
+	}
+
+	status, err := n.post(ctx, payload)
+	if err == nil && status >= 500 {
+		n.logger.Debug().Int("status", status).Msg("Webhook returned server error, retrying")
+		status, err = n.post(ctx, payload)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send webhook notification: %w", err)
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("webhook returned status %d", status)
+	}
+
+	n.logger.Info().Msg("Webhook notification sent")
+	return nil
+}
+
+// post sends the payload once and returns the response status code.
+func (n *WebhookNotifier) post(ctx context.Context, payload []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
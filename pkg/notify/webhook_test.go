@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var received map[string]interface{}
+	var authHeader string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		authHeader = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("payload should be valid JSON: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		WebhookURL:     server.URL,
+		WebhookHeaders: map[string]string{"Authorization": "Bearer secret"},
+	}
+	notifier := NewWebhookNotifier(cfg, logging.NewLogger("error", "json"))
+	if err := notifier.Notify(context.Background(), testSummary()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected exactly one request, got %d", requests)
+	}
+	if authHeader != "Bearer secret" {
+		t.Errorf("expected configured Authorization header, got %q", authHeader)
+	}
+	if received["outdatedHelmCount"] != float64(2) {
+		t.Errorf("expected outdatedHelmCount 2, got %v", received["outdatedHelmCount"])
+	}
+	if received["outdatedImageCount"] != float64(1) {
+		t.Errorf("expected outdatedImageCount 1, got %v", received["outdatedImageCount"])
+	}
+	for _, key := range []string{"timestamp", "helmReleases", "containers", "createdIssueUrls"} {
+		if _, ok := received[key]; !ok {
+			t.Errorf("expected payload key %q", key)
+		}
+	}
+	releases, _ := received["helmReleases"].([]interface{})
+	if len(releases) != 2 {
+		t.Errorf("expected 2 helm releases in payload, got %d", len(releases))
+	}
+}
+
+func TestWebhookNotifier_RetriesOnceOn5xx(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(&config.Config{WebhookURL: server.URL}, logging.NewLogger("error", "json"))
+	if err := notifier.Notify(context.Background(), testSummary()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected one retry, got %d requests", requests)
+	}
+}
+
+func TestWebhookNotifier_FailsAfterRetry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(&config.Config{WebhookURL: server.URL}, logging.NewLogger("error", "json"))
+	if err := notifier.Notify(context.Background(), testSummary()); err == nil {
+		t.Fatal("expected error after retry")
+	}
+	if requests != 2 {
+		t.Errorf("expected exactly two attempts, got %d", requests)
+	}
+}
+
+func TestWebhookNotifier_NoRetryOn4xx(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(&config.Config{WebhookURL: server.URL}, logging.NewLogger("error", "json"))
+	if err := notifier.Notify(context.Background(), testSummary()); err == nil {
+		t.Fatal("expected error for 401 response")
+	}
+	if requests != 1 {
+		t.Errorf("expected no retry on 4xx, got %d requests", requests)
+	}
+}
+
+func TestNewNotifiers(t *testing.T) {
+	logger := logging.NewLogger("error", "json")
+
+	if n := NewNotifiers(&config.Config{}, logger); len(n) != 0 {
+		t.Errorf("expected no notifiers, got %d", len(n))
+	}
+
+	cfg := &config.Config{SlackWebhookURL: "https://hooks.slack.com/x", WebhookURL: "https://example.com/hook"}
+	if n := NewNotifiers(cfg, logger); len(n) != 2 {
+		t.Errorf("expected 2 notifiers, got %d", len(n))
+	}
+}
@@ -0,0 +1,57 @@
+package nova
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// calverPattern matches calendar versions: year.month with an optional day and suffix,
+// e.g. 2024.06.18, v2024.6 or 2024.06.18-1.
+var calverPattern = regexp.MustCompile(`^v?(\d{4})\.(\d{1,2})(?:\.(\d{1,2}))?([-+.].*)?$`)
+
+// calver is a parsed calendar version.
+type calver struct {
+	year, month, day int
+	suffix           string
+}
+
+// parseCalver parses a calendar version, reporting false if the version is not calver.
+func parseCalver(version string) (calver, bool) {
+	m := calverPattern.FindStringSubmatch(version)
+	if m == nil {
+		return calver{}, false
+	}
+	v := calver{suffix: m[4]}
+	v.year, _ = strconv.Atoi(m[1])
+	v.month, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.day, _ = strconv.Atoi(m[3])
+	}
+	if v.month < 1 || v.month > 12 || v.day > 31 {
+		return calver{}, false
+	}
+	return v, true
+}
+
+// calverBump returns the most significant date component ("year", "month" or "day") that
+// increased from current to latest. A re-release of the same date with a different suffix
+// counts as a day bump. Returns "" when latest is not newer.
+func calverBump(current, latest calver) string {
+	switch {
+	case latest.year != current.year:
+		if latest.year > current.year {
+			return "year"
+		}
+	case latest.month != current.month:
+		if latest.month > current.month {
+			return "month"
+		}
+	case latest.day != current.day:
+		if latest.day > current.day {
+			return "day"
+		}
+	case latest.suffix != current.suffix:
+		return "day"
+	}
+	return ""
+}
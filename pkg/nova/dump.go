@@ -0,0 +1,51 @@
+package nova
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// unsafeFileChars matches characters kept out of dump file names, e.g. the slashes and colons
+// of EKS context ARNs.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// novaInvocation is the argv recorded next to a dumped nova output.
+type novaInvocation struct {
+	Binary string   `json:"binary"`
+	Args   []string `json:"args"`
+}
+
+// dumpPaths returns the files receiving nova's raw stdout and argv for a scan in dir, named
+// after the scan type and the kube context, if any: e.g. nova-helm-prod.out and nova-helm-prod.argv.json.
+func (s *Scanner) dumpPaths(dir, scanType string) (output, argv string) {
+	name := "nova-" + scanType
+	if kubeContext := s.KubeContext(); kubeContext != "" {
+		name += "-" + unsafeFileChars.ReplaceAllString(kubeContext, "_")
+	}
+	return filepath.Join(dir, name+".out"), filepath.Join(dir, name+".argv.json")
+}
+
+// dumpOutput writes nova's raw stdout and the arguments it was run with to the dumpNovaOutput
+// directory, replacing the files of the previous scan.
+func (s *Scanner) dumpOutput(scanType string, args []string, output []byte) error {
+	dir := s.config.DumpNovaOutput
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create nova output dump directory: %w", err)
+	}
+
+	outputPath, argvPath := s.dumpPaths(dir, scanType)
+	if err := os.WriteFile(outputPath, output, 0o640); err != nil {
+		return fmt.Errorf("failed to dump nova output: %w", err)
+	}
+	argv, err := json.MarshalIndent(novaInvocation{Binary: s.binary, Args: args}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode nova arguments: %w", err)
+	}
+	if err := os.WriteFile(argvPath, append(argv, '\n'), 0o640); err != nil {
+		return fmt.Errorf("failed to dump nova arguments: %w", err)
+	}
+	return nil
+}
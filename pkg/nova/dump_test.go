@@ -0,0 +1,68 @@
+package nova
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+)
+
+func TestScanner_DumpNovaOutput(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dump")
+	raw := "{\"helm_releases\":[{\"release\":\"app\",\"namespace\":\"default\",\"outdated\":true,\n" +
+		"  \"Installed\":{\"version\":\"1.0.0\"},\"Latest\":{\"version\":\"2.0.0\"}}]}\n"
+	cfg := &config.Config{MinSeverity: "minor", Context: "arn:aws:eks:eu-west-1:123:cluster/prod", DumpNovaOutput: dir}
+	var args []string
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(raw, &args)))
+
+	result, err := scanner.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 1 {
+		t.Errorf("expected the output to be parsed as well, got %d outdated releases", len(result.Outdated))
+	}
+
+	output, err := os.ReadFile(filepath.Join(dir, "nova-helm-arn_aws_eks_eu-west-1_123_cluster_prod.out"))
+	if err != nil {
+		t.Fatalf("expected the output dump: %v", err)
+	}
+	if string(output) != raw {
+		t.Errorf("expected the raw nova output, got %q", output)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "nova-helm-arn_aws_eks_eu-west-1_123_cluster_prod.argv.json"))
+	if err != nil {
+		t.Fatalf("expected the argv dump: %v", err)
+	}
+	var argv novaInvocation
+	if err := json.Unmarshal(data, &argv); err != nil {
+		t.Fatalf("argv dump should be JSON: %v\n%s", err, data)
+	}
+	if argv.Binary != "nova" || !slices.Equal(argv.Args, args) {
+		t.Errorf("expected nova %v, got %s %v", args, argv.Binary, argv.Args)
+	}
+}
+
+func TestScanner_DumpNovaOutput_FailedScan(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{MinSeverity: "minor", DumpNovaOutput: dir}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(
+		CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			return []byte(`{"container_images":[`), errors.New("exit status 1")
+		})))
+
+	if _, err := scanner.ScanContainers(context.Background(), nil); err == nil {
+		t.Fatal("expected the scan to fail")
+	}
+	output, err := os.ReadFile(filepath.Join(dir, "nova-container.out"))
+	if err != nil || string(output) != `{"container_images":[` {
+		t.Errorf("expected the partial output of the failed scan, got %q, %v", output, err)
+	}
+}
@@ -0,0 +1,115 @@
+package nova
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Scan failure classes, wrapped by the errors of NewScanner and the scan methods so callers
+// can tell them apart with errors.Is. ErrScanTimeout is another one.
+var (
+	// ErrNovaNotFound is returned when the nova executable cannot be found or started.
+	ErrNovaNotFound = errors.New("nova binary not found")
+	// ErrClusterUnreachable is returned when nova cannot connect to the Kubernetes API server.
+	ErrClusterUnreachable = errors.New("kubernetes cluster unreachable")
+	// ErrClusterTLS is returned when nova cannot verify the Kubernetes API server's certificate,
+	// e.g. because the kubeconfig lacks the cluster's CA.
+	ErrClusterTLS = errors.New("kubernetes cluster certificate not trusted")
+	// ErrClusterUnauthorized is returned when the Kubernetes API server rejects nova's credentials.
+	ErrClusterUnauthorized = errors.New("kubernetes cluster access denied")
+	// ErrKubeconfig is returned when the kubeconfig or the selected context is invalid.
+	ErrKubeconfig = errors.New("invalid kubeconfig")
+	// ErrParse is returned when nova's output cannot be parsed.
+	ErrParse = errors.New("failed to parse nova output")
+	// ErrArtifactHubRateLimited is returned when ArtifactHub rate-limits nova's chart polling.
+	ErrArtifactHubRateLimited = errors.New("artifacthub rate limit exceeded")
+)
+
+// artifactHubRateLimitSignatures are lowercase stderr fragments of a rate-limited request. They
+// only count in stderr mentioning ArtifactHub, since the Kubernetes API server throttles too.
+var artifactHubRateLimitSignatures = []string{"429", "too many requests", "rate limit"}
+
+// stderrSignatures maps lowercase nova/client-go stderr fragments to the failure they indicate.
+// The first matching entry wins, so more specific signatures come first.
+var stderrSignatures = []struct {
+	fragment string
+	err      error
+}{
+	{"executable file not found", ErrNovaNotFound},
+	{"fork/exec", ErrNovaNotFound},
+	{"certificate signed by unknown authority", ErrClusterTLS},
+	{"x509: certificate", ErrClusterTLS},
+	{"unauthorized", ErrClusterUnauthorized},
+	{"forbidden", ErrClusterUnauthorized},
+	{"provide credentials", ErrClusterUnauthorized},
+	{"context was not found", ErrKubeconfig},
+	{"invalid configuration", ErrKubeconfig},
+	{"no configuration has been provided", ErrKubeconfig},
+	{"kubernetes cluster unreachable", ErrClusterUnreachable},
+	{"unable to connect to the server", ErrClusterUnreachable},
+	{"connection refused", ErrClusterUnreachable},
+	{"no such host", ErrClusterUnreachable},
+	{"i/o timeout", ErrClusterUnreachable},
+	{"tls handshake timeout", ErrClusterUnreachable},
+	{"no route to host", ErrClusterUnreachable},
+}
+
+// classifyCommandError returns the failure class of a failed nova invocation, judged by the
+// error and the stderr captured with it, or nil if it matches no known signature.
+func classifyCommandError(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrNovaNotFound
+	}
+
+	text := err.Error()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		text += "\n" + string(exitErr.Stderr)
+	}
+	return classifyStderr(text)
+}
+
+// classifyStderr returns the failure class indicated by nova's stderr, or nil if unknown.
+func classifyStderr(stderr string) error {
+	stderr = strings.ToLower(stderr)
+	if strings.Contains(stderr, "artifacthub") {
+		for _, fragment := range artifactHubRateLimitSignatures {
+			if strings.Contains(stderr, fragment) {
+				return ErrArtifactHubRateLimited
+			}
+		}
+	}
+	for _, sig := range stderrSignatures {
+		if strings.Contains(stderr, sig.fragment) {
+			return sig.err
+		}
+	}
+	return nil
+}
+
+// ErrorReason returns a short label for the failure class of a scan error, for metrics and
+// logs: "timeout", "nova_not_found", "cluster_unreachable", "cluster_tls",
+// "cluster_unauthorized", "kubeconfig", "artifacthub_rate_limited", "parse" or "unknown".
+func ErrorReason(err error) string {
+	switch {
+	case errors.Is(err, ErrScanTimeout):
+		return "timeout"
+	case errors.Is(err, ErrNovaNotFound):
+		return "nova_not_found"
+	case errors.Is(err, ErrClusterUnreachable):
+		return "cluster_unreachable"
+	case errors.Is(err, ErrClusterTLS):
+		return "cluster_tls"
+	case errors.Is(err, ErrClusterUnauthorized):
+		return "cluster_unauthorized"
+	case errors.Is(err, ErrKubeconfig):
+		return "kubeconfig"
+	case errors.Is(err, ErrArtifactHubRateLimited):
+		return "artifacthub_rate_limited"
+	case errors.Is(err, ErrParse):
+		return "parse"
+	default:
+		return "unknown"
+	}
+}
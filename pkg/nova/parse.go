@@ -0,0 +1,122 @@
+package nova
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+)
+
+// maxRecordSnippet bounds how much of an invalid record is logged.
+const maxRecordSnippet = 200
+
+// rawNovaOutput is NovaOutput with its records left undecoded, so each record is decoded and
+// validated on its own and one malformed record doesn't fail the whole scan.
+type rawNovaOutput struct {
+	HelmReleases []json.RawMessage `json:"helm_releases"`
+	Containers   []json.RawMessage `json:"container_images"`
+	Helm         []json.RawMessage `json:"helm"`
+}
+
+// parseHelmOutput decodes the Helm releases in nova's output, skipping invalid records.
+// Returns the valid releases and the number of skipped records.
+func parseHelmOutput(output []byte, logger *logging.Logger) ([]ReleaseOutput, int, error) {
+	var raw rawNovaOutput
+	if err := json.Unmarshal(output, &raw); err != nil {
+		// Try parsing as array directly (older Nova versions)
+		if err2 := json.Unmarshal(output, &raw.HelmReleases); err2 != nil {
+			return nil, 0, fmt.Errorf("%w: %w", ErrParse, err)
+		}
+	}
+	if len(raw.HelmReleases) == 0 {
+		raw.HelmReleases = raw.Helm
+	}
+
+	releases, invalid := decodeRecords(raw.HelmReleases, "helm", logger, func(r *ReleaseOutput) error {
+		r.normalize()
+		return r.validate()
+	})
+	return releases, invalid, nil
+}
+
+// parseContainerOutput decodes the container images in nova's output, skipping invalid records.
+// Returns the valid containers and the number of skipped records.
+func parseContainerOutput(output []byte, logger *logging.Logger) ([]ContainerOutput, int, error) {
+	var raw rawNovaOutput
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+
+	containers, invalid := decodeRecords(raw.Containers, "container", logger, func(c *ContainerOutput) error {
+		return c.validate()
+	})
+	return containers, invalid, nil
+}
+
+// decodeRecords decodes each record and passes it to check, logging a warning for every record
+// that fails to decode or check, and a summary if any did. Returns the valid records and the
+// number of invalid ones.
+func decodeRecords[T any](raw []json.RawMessage, scanType string, logger *logging.Logger, check func(*T) error) ([]T, int) {
+	var records []T
+	invalid := 0
+	for i, data := range raw {
+		var record T
+		err := json.Unmarshal(data, &record)
+		if err == nil {
+			err = check(&record)
+		}
+		if err != nil {
+			invalid++
+			logger.Warn().
+				Err(err).
+				Str("scan_type", scanType).
+				Int("index", i).
+				Str("record", recordSnippet(data)).
+				Msg("Skipping invalid nova record")
+			continue
+		}
+		records = append(records, record)
+	}
+
+	if invalid > 0 {
+		logger.Warn().
+			Str("scan_type", scanType).
+			Int("invalid", invalid).
+			Int("valid", len(records)).
+			Msg("Skipped invalid nova records")
+	}
+	return records, invalid
+}
+
+// recordSnippet returns the start of a raw record for logging.
+func recordSnippet(data json.RawMessage) string {
+	if len(data) > maxRecordSnippet {
+		return string(data[:maxRecordSnippet]) + "..."
+	}
+	return string(data)
+}
+
+// validate rejects release records that can't be reported: without a name, or outdated
+// without both versions.
+func (r ReleaseOutput) validate() error {
+	if r.ReleaseName == "" {
+		return errors.New("missing release name")
+	}
+	if r.IsOld && (r.Installed.Version == "" || r.Latest.Version == "") {
+		return fmt.Errorf("outdated release %s is missing its installed or latest version", r.ReleaseName)
+	}
+	return nil
+}
+
+// validate rejects container records that can't be reported: without an image name, or
+// outdated without both tags.
+func (c ContainerOutput) validate() error {
+	if c.Name == "" {
+		return errors.New("missing image name")
+	}
+	if c.IsOld && (c.CurrentTag == "" || c.LatestTag == "") {
+		return fmt.Errorf("outdated image %s is missing its current or latest version", c.Name)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package nova
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+)
+
+// ContextResult holds the scan results for one kube context.
+type ContextResult struct {
+	Context string // kube context scanned (empty = current context)
+
+	// Helm and Containers are nil when the scan is disabled or failed.
+	Helm       *HelmScanResult
+	Containers *ContainerScanResult
+
+	HelmErr       error
+	ContainersErr error
+}
+
+// RunResult aggregates the results of scanning every configured kube context.
+type RunResult struct {
+	Contexts []ContextResult
+
+	// Summary counts across all contexts
+	OutdatedHelm       int
+	OutdatedContainers int
+	SkippedContainers  int
+	Duration           time.Duration
+}
+
+// Releases returns the outdated Helm releases of all contexts.
+func (r *RunResult) Releases() []ReleaseOutput {
+	var releases []ReleaseOutput
+	for _, c := range r.Contexts {
+		if c.Helm != nil {
+			releases = append(releases, c.Helm.Outdated...)
+		}
+	}
+	return releases
+}
+
+// Containers returns the outdated container images of all contexts.
+func (r *RunResult) Containers() []ContainerOutput {
+	var containers []ContainerOutput
+	for _, c := range r.Contexts {
+		if c.Containers != nil {
+			containers = append(containers, c.Containers.Outdated...)
+		}
+	}
+	return containers
+}
+
+// Run scans all configured kube contexts with a new Scanner. It does not create issues or record metrics,
+// so it can be embedded in other services.
+func Run(ctx context.Context, cfg *config.Config, logger *logging.Logger, opts ...Option) (*RunResult, error) {
+	scanner, err := NewScanner(cfg, logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+	return scanner.Run(ctx)
+}
+
+// Run performs the enabled Helm and container scans for every configured kube context,
+// skipping containers covered by outdated Helm releases in the same context.
+// A failed scan does not stop the others: the partial result is returned together with
+// the joined scan errors.
+func (s *Scanner) Run(ctx context.Context) (*RunResult, error) {
+	start := time.Now()
+	result := &RunResult{}
+	var errs []error
+
+	for _, scanner := range s.ContextScanners() {
+		c := ContextResult{Context: scanner.KubeContext()}
+
+		if s.config.ScanHelm {
+			c.Helm, c.HelmErr = scanner.ScanHelm(ctx)
+			if c.HelmErr != nil {
+				errs = append(errs, fmt.Errorf("helm scan failed: %w", c.HelmErr))
+			} else {
+				result.OutdatedHelm += len(c.Helm.Outdated)
+			}
+		}
+
+		// The Helm results deduplicate the containers; without them nothing is skipped
+		if s.config.ScanContainers {
+			c.Containers, c.ContainersErr = scanner.ScanContainers(ctx, c.Helm)
+			if c.ContainersErr != nil {
+				errs = append(errs, fmt.Errorf("container scan failed: %w", c.ContainersErr))
+			} else {
+				result.OutdatedContainers += len(c.Containers.Outdated)
+				result.SkippedContainers += len(c.Containers.Skipped)
+			}
+		}
+
+		result.Contexts = append(result.Contexts, c)
+	}
+
+	result.Duration = time.Since(start)
+	return result, errors.Join(errs...)
+}
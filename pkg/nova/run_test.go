@@ -0,0 +1,145 @@
+package nova
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+)
+
+const (
+	runHelmOutput = `{"helm_releases":[
+		{"release":"api","chartName":"api","namespace":"apps","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0"},"outdated":true},
+		{"release":"db","chartName":"postgres","namespace":"data","Installed":{"version":"2.0.0"},"Latest":{"version":"2.0.0"},"outdated":false}
+	]}`
+	runContainerOutput = `{"container_images":[
+		{"name":"redis","current_version":"7.0.0","latest_version":"7.2.0","outdated":true,
+		 "affectedWorkloads":[{"name":"cache","namespace":"apps"}]},
+		{"name":"nginx","current_version":"1.24.0","latest_version":"1.25.0","outdated":true,
+		 "affectedWorkloads":[{"name":"web","namespace":"web"}]}
+	]}`
+)
+
+// modeRunner returns canned nova output per scan mode, failing the modes listed in failing.
+func modeRunner(failing ...string) CommandRunner {
+	return CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		for _, mode := range []string{"--helm", "--containers"} {
+			if slices.Contains(args, mode) && slices.Contains(failing, mode) {
+				return nil, errors.New("nova failed")
+			}
+		}
+		if slices.Contains(args, "--helm") {
+			return []byte(runHelmOutput), nil
+		}
+		return []byte(runContainerOutput), nil
+	})
+}
+
+func TestScanner_Run(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", ScanHelm: true, ScanContainers: true}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = modeRunner()
+
+	result, err := scanner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Contexts) != 1 {
+		t.Fatalf("expected 1 context result, got %d", len(result.Contexts))
+	}
+	if result.OutdatedHelm != 1 || result.OutdatedContainers != 1 || result.SkippedContainers != 1 {
+		t.Errorf("expected 1 outdated release, 1 outdated and 1 skipped container, got %d, %d and %d",
+			result.OutdatedHelm, result.OutdatedContainers, result.SkippedContainers)
+	}
+	if releases := result.Releases(); len(releases) != 1 || releases[0].ReleaseName != "api" {
+		t.Errorf("expected outdated release api, got %v", releases)
+	}
+	// redis runs in the namespace of the outdated api release, so only nginx is reported
+	if containers := result.Containers(); len(containers) != 1 || containers[0].Name != "nginx" {
+		t.Errorf("expected outdated container nginx, got %v", containers)
+	}
+}
+
+func TestScanner_Run_MultiContext(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", ScanHelm: true, Contexts: []string{"prod", "staging"}}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = modeRunner()
+
+	result, err := scanner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Contexts) != 2 || result.Contexts[0].Context != "prod" || result.Contexts[1].Context != "staging" {
+		t.Fatalf("expected results for prod and staging, got %+v", result.Contexts)
+	}
+	if result.Contexts[0].Containers != nil {
+		t.Error("expected no container results when container scanning is disabled")
+	}
+	if result.OutdatedHelm != 2 {
+		t.Errorf("expected outdated releases summed across contexts (2), got %d", result.OutdatedHelm)
+	}
+	if releases := result.Releases(); releases[1].Context != "staging" {
+		t.Errorf("expected releases tagged with their context, got %q", releases[1].Context)
+	}
+}
+
+func TestScanner_Run_PartialFailure(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", ScanHelm: true, ScanContainers: true}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = modeRunner("--helm")
+
+	result, err := scanner.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected the helm scan error")
+	}
+	if result == nil || result.Contexts[0].HelmErr == nil {
+		t.Fatalf("expected a partial result recording the helm error, got %+v", result)
+	}
+	// Without Helm results nothing is deduplicated, and the container scan still runs
+	if result.OutdatedContainers != 2 {
+		t.Errorf("expected 2 outdated containers, got %d", result.OutdatedContainers)
+	}
+}
+
+func TestRun_WithCommandRunner(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", ScanHelm: true}
+
+	result, err := Run(context.Background(), cfg, logging.NewLogger("error", "json"), WithCommandRunner(modeRunner()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.OutdatedHelm != 1 {
+		t.Errorf("expected 1 outdated release, got %d", result.OutdatedHelm)
+	}
+}
+
+func TestScanner_Run_SkipAllHelmManagedContainers(t *testing.T) {
+	containers := `{"container_images":[
+		{"name":"postgres","current_version":"15.0.0","latest_version":"16.2.0","outdated":true,
+		 "affectedWorkloads":[{"name":"db","namespace":"data","kind":"StatefulSet","container":"postgresql"}]},
+		{"name":"nginx","current_version":"1.24.0","latest_version":"1.25.0","outdated":true,
+		 "affectedWorkloads":[{"name":"web","namespace":"web","kind":"Deployment","container":"nginx"}]}
+	]}`
+	cfg := &config.Config{MinSeverity: "minor", ScanHelm: true, ScanContainers: true, SkipAllHelmManagedContainers: true}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if slices.Contains(args, "--helm") {
+			return []byte(runHelmOutput), nil
+		}
+		return []byte(containers), nil
+	})
+
+	result, err := scanner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// postgres belongs to the up-to-date db release, so only nginx is reported
+	if containers := result.Containers(); len(containers) != 1 || containers[0].Name != "nginx" {
+		t.Errorf("expected outdated container nginx, got %v", containers)
+	}
+}
@@ -1,22 +1,91 @@
 package nova
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/artifacthub"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/httpclient"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrScanTimeout is returned when a nova invocation exceeds the configured scan timeout.
+var ErrScanTimeout = errors.New("nova scan timed out")
+
 // Scanner wraps Nova CLI functionality.
 type Scanner struct {
-	config *config.Config
-	logger *logging.Logger
+	config   *config.Config
+	logger   *logging.Logger
+	security securityReporter // nil unless ArtifactHub security escalation is enabled
+	dates    releaseDater     // nil unless minReleaseAge is set and ArtifactHub polling is enabled
+	versions versionCounter   // nil unless minVersionsBehind is set and ArtifactHub polling is enabled
+	run      CommandRunner
+	binary   string // resolved path of the nova executable
+
+	// kubeContext is set on per-context scanners in multi-context mode; results are tagged with it.
+	kubeContext string
+}
+
+// CommandRunner executes a command and returns its stdout.
+// The default runner executes nova on the host; tests and embedders can supply their own.
+type CommandRunner interface {
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// CommandRunnerFunc adapts a function to the CommandRunner interface.
+type CommandRunnerFunc func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// Run calls f(ctx, name, args...).
+func (f CommandRunnerFunc) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return f(ctx, name, args...)
+}
+
+// execCommand runs a command on the host.
+var execCommand = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+})
+
+// Option configures a Scanner.
+type Option func(*Scanner)
+
+// WithCommandRunner makes the scanner run nova through runner instead of executing it on the host.
+// The nova binary is then not required to be on the PATH.
+func WithCommandRunner(runner CommandRunner) Option {
+	return func(s *Scanner) {
+		s.run = runner
+	}
+}
+
+// lookPath resolves executables; replaced in tests that don't need a real nova.
+var lookPath = exec.LookPath
+
+// securityReporter looks up ArtifactHub security reports for chart versions.
+type securityReporter interface {
+	SecuritySummary(ctx context.Context, chartName, version string) (*artifacthub.SecurityReportSummary, error)
+}
+
+// versionCounter counts the chart versions published between two versions (0 = unknown).
+type versionCounter interface {
+	VersionsBehind(ctx context.Context, chartName, installedVersion, latestVersion string) (int, error)
+}
+
+// releaseDater looks up when chart versions were published (zero time = unknown).
+type releaseDater interface {
+	ReleaseTime(ctx context.Context, chartName, version string) (time.Time, error)
 }
 
 // ReleaseOutput represents a Helm release from Nova's output.
@@ -33,6 +102,56 @@ type ReleaseOutput struct {
 	Deprecated  bool        `json:"deprecated"`
 	HelmVersion string      `json:"helmVersion"`
 	Overridden  bool        `json:"overridden"`
+
+	// Fields added by newer Nova versions; empty in the legacy schema.
+	OutdatedApp   bool           `json:"outdatedApp"`
+	RepositoryURL string         `json:"repositoryURL"`
+	Status        *ReleaseStatus `json:"status,omitempty"`
+
+	// Context is the kube context the release was found in (multi-context mode only).
+	Context string `json:"-"`
+
+	// SecurityAlert is set by the scanner when ArtifactHub security reports escalated the severity.
+	SecurityAlert bool `json:"-"`
+
+	// Severity is set by the scanner for calverCharts, whose upgrades aren't semver bumps (see UpgradeSeverity).
+	Severity int `json:"-"`
+
+	// FirstSeen is when the release was first detected as outdated (stateFile only).
+	FirstSeen time.Time `json:"-"`
+}
+
+// UpgradeSeverity returns the severity of the release upgrade: the calver severity set by the
+// scanner, or else the semver bump as returned by VersionSeverity.
+func (r ReleaseOutput) UpgradeSeverity() int {
+	if r.Severity > 0 {
+		return r.Severity
+	}
+	return VersionSeverity(r.Installed.Version, r.Latest.Version)
+}
+
+// IsHelmV2 reports whether nova found the release installed by Helm v2 (Tiller), given as "2"
+// or "v2" by helmVersion.
+func (r ReleaseOutput) IsHelmV2() bool {
+	version := strings.TrimPrefix(strings.ToLower(r.HelmVersion), "v")
+	return version == "2" || strings.HasPrefix(version, "2.")
+}
+
+// ReleaseStatus is the nested release status reported by newer Nova versions.
+type ReleaseStatus struct {
+	Outdated    bool `json:"outdated"`
+	OutdatedApp bool `json:"outdatedApp"`
+	Deprecated  bool `json:"deprecated"`
+}
+
+// normalize folds the nested status of the newer schema into the legacy top-level fields.
+func (r *ReleaseOutput) normalize() {
+	if r.Status == nil {
+		return
+	}
+	r.IsOld = r.IsOld || r.Status.Outdated
+	r.OutdatedApp = r.OutdatedApp || r.Status.OutdatedApp
+	r.Deprecated = r.Deprecated || r.Status.Deprecated
 }
 
 // VersionInfo holds version details.
@@ -46,8 +165,21 @@ type ContainerOutput struct {
 	Name              string           `json:"name"`
 	CurrentTag        string           `json:"current_version"`
 	LatestTag         string           `json:"latest_version"`
+	LatestMinorTag    string           `json:"latest_minor_version"` // latest tag within the current major version
 	IsOld             bool             `json:"outdated"`
 	AffectedWorkloads []WorkloadOutput `json:"affectedWorkloads"`
+
+	// Context is the kube context the image was found in (multi-context mode only).
+	Context string `json:"-"`
+
+	// DesiredTag is set when desiredImageVersions pins the upgrade target; LatestTag then holds it.
+	DesiredTag string `json:"-"`
+
+	// DigestPinned is set by the scanner when the image is referenced by digest rather than a tag.
+	DigestPinned bool `json:"-"`
+
+	// FirstSeen is when the image was first detected as outdated (stateFile only).
+	FirstSeen time.Time `json:"-"`
 }
 
 // WorkloadOutput represents a Kubernetes workload.
@@ -62,13 +194,29 @@ type WorkloadOutput struct {
 type NovaOutput struct {
 	HelmReleases []ReleaseOutput   `json:"helm_releases"`
 	Containers   []ContainerOutput `json:"container_images"`
+
+	// Helm holds the releases in the newer schema, which renamed helm_releases.
+	Helm []ReleaseOutput `json:"helm"`
 }
 
 // HelmScanResult contains the results of a Helm scan.
 type HelmScanResult struct {
-	AllReleases []ReleaseOutput
-	Outdated    []ReleaseOutput
-	Duration    time.Duration
+	AllReleases    []ReleaseOutput // only outdated releases when includeAllReleases is disabled
+	Outdated       []ReleaseOutput
+	InvalidRecords int // malformed records in nova's output, skipped
+	Duration       time.Duration
+}
+
+// IsHelmManaged reports whether a workload belongs to one of the scanned releases. Nova doesn't
+// report workload labels, so this relies on the workload being named exactly like a release in
+// its namespace; a prefix match would let release "app" claim an unrelated "app-other".
+func (r *HelmScanResult) IsHelmManaged(workload WorkloadOutput) bool {
+	for _, release := range r.AllReleases {
+		if release.Namespace == workload.Namespace && workload.Name == release.ReleaseName {
+			return true
+		}
+	}
+	return false
 }
 
 // OutdatedNamespaces returns a set of namespaces that have outdated Helm releases.
@@ -80,82 +228,299 @@ func (r *HelmScanResult) OutdatedNamespaces() map[string]bool {
 	return namespaces
 }
 
+// OutdatedByNamespace returns the number of outdated Helm releases per namespace.
+func (r *HelmScanResult) OutdatedByNamespace() map[string]int {
+	counts := make(map[string]int)
+	for _, release := range r.Outdated {
+		counts[release.Namespace]++
+	}
+	return counts
+}
+
+// UnknownSeverity returns the number of outdated Helm releases whose upgrade severity is unknown.
+func (r *HelmScanResult) UnknownSeverity() int {
+	unknown := 0
+	for _, release := range r.Outdated {
+		if release.UpgradeSeverity() == SeverityUnknown {
+			unknown++
+		}
+	}
+	return unknown
+}
+
 // ContainerScanResult contains the results of a container scan.
 type ContainerScanResult struct {
-	AllContainers []ContainerOutput
-	Outdated      []ContainerOutput
-	Skipped       []ContainerOutput // Containers skipped due to Helm deduplication
-	Duration      time.Duration
+	AllContainers  []ContainerOutput
+	Outdated       []ContainerOutput
+	Skipped        []ContainerOutput // Containers skipped due to Helm deduplication
+	InvalidRecords int               // malformed records in nova's output, skipped
+	Duration       time.Duration
+}
+
+// OutdatedByNamespace returns the number of outdated container images per namespace of their
+// workloads. An image used in several namespaces counts in each; images without workload
+// information count under the empty namespace.
+func (r *ContainerScanResult) OutdatedByNamespace() map[string]int {
+	counts := make(map[string]int)
+	for _, container := range r.Outdated {
+		if len(container.AffectedWorkloads) == 0 {
+			counts[""]++
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, w := range container.AffectedWorkloads {
+			if !seen[w.Namespace] {
+				counts[w.Namespace]++
+				seen[w.Namespace] = true
+			}
+		}
+	}
+	return counts
+}
+
+// UnknownSeverity returns the number of outdated container images whose upgrade severity is
+// unknown, e.g. tags like "stable" that aren't semver.
+func (r *ContainerScanResult) UnknownSeverity() int {
+	unknown := 0
+	for _, container := range r.Outdated {
+		if VersionSeverity(container.CurrentTag, container.LatestTag) == SeverityUnknown {
+			unknown++
+		}
+	}
+	return unknown
 }
 
 // NewScanner creates a new Scanner instance.
-func NewScanner(cfg *config.Config, logger *logging.Logger) (*Scanner, error) {
-	return &Scanner{
+func NewScanner(cfg *config.Config, logger *logging.Logger, opts ...Option) (*Scanner, error) {
+	binary := cfg.NovaBinary
+	if binary == "" {
+		binary = "nova"
+	}
+
+	s := &Scanner{
 		config: cfg,
 		logger: logger.WithComponent("nova"),
-	}, nil
+		binary: binary,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Only the host runner needs the binary on the PATH
+	if s.run == nil {
+		path, err := lookPath(binary)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q; install Nova (https://nova.docs.fairwinds.com/installation/) "+
+				"or set novaBinary (NOVA_BINARY) to its path: %w", ErrNovaNotFound, binary, err)
+		}
+		s.binary = path
+		s.run = execCommand
+	}
+	if conflicts := conflictingArgs(cfg.NovaExtraArgs); len(conflicts) > 0 {
+		s.logger.Warn().
+			Strs("flags", conflicts).
+			Msg("novaExtraArgs repeats flags the scanner manages; set the corresponding options instead, nova may reject or misread the scan")
+	}
+	if cfg.PollArtifactHub && (cfg.ArtifactHubSecurity || cfg.MinReleaseAge > 0 || cfg.MinVersionsBehind > 0) {
+		client := artifacthub.NewClient(httpclient.FromConfig(cfg))
+		if cfg.ArtifactHubSecurity {
+			s.security = client
+		}
+		if cfg.MinReleaseAge > 0 {
+			s.dates = client
+		}
+		if cfg.MinVersionsBehind > 0 {
+			s.versions = client
+		}
+	}
+	return s, nil
 }
 
-// ScanHelm scans for outdated Helm releases using Nova CLI.
-func (s *Scanner) ScanHelm(ctx context.Context) (*HelmScanResult, error) {
-	s.logger.ScanStart("helm")
-	start := time.Now()
+// Close releases the resources held by the scanner. It holds none that need releasing today,
+// but callers should defer it so later ones are cleaned up. Close may be called more than once.
+func (s *Scanner) Close() error {
+	return nil
+}
+
+// ContextScanners returns one scanner per configured kube context.
+// Without a contexts list it returns the scanner itself, scanning the single configured context.
+func (s *Scanner) ContextScanners() []*Scanner {
+	if len(s.config.Contexts) == 0 {
+		return []*Scanner{s}
+	}
+
+	scanners := make([]*Scanner, 0, len(s.config.Contexts))
+	for _, kubeContext := range s.config.Contexts {
+		contextScanner := *s
+		contextScanner.kubeContext = kubeContext
+		contextScanner.logger = s.logger.WithKubeContext(kubeContext)
+		scanners = append(scanners, &contextScanner)
+	}
+	return scanners
+}
+
+// KubeContext returns the kube context this scanner targets (empty = current context).
+func (s *Scanner) KubeContext() string {
+	if s.kubeContext != "" {
+		return s.kubeContext
+	}
+	return s.config.Context
+}
+
+// helmArgs builds the nova arguments for a Helm scan.
+func (s *Scanner) helmArgs() []string {
+	args := s.findArgs("--helm")
+
+	// Add include-all to get all releases, not just outdated
+	if s.config.IncludeAllReleases {
+		args = append(args, "--include-all")
+	}
+	return append(args, s.config.NovaExtraArgs...)
+}
+
+// containerArgs builds the nova arguments for a container scan.
+func (s *Scanner) containerArgs() []string {
+	return append(s.findArgs("--containers"), s.config.NovaExtraArgs...)
+}
 
-	// Build Nova command
-	args := []string{"find", "--format", "json", "--helm"}
+// managedFlags are the nova flags the scanner sets itself from its configuration.
+var managedFlags = []string{"--format", "--helm", "--containers", "--poll-artifacthub", "--include-all", "--kubeconfig", "--context"}
+
+// conflictingArgs returns the extra arguments that set a managed flag, in either the
+// "--flag value" or the "--flag=value" form.
+func conflictingArgs(extraArgs []string) []string {
+	var conflicts []string
+	for _, arg := range extraArgs {
+		flag, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(managedFlags, flag) {
+			conflicts = append(conflicts, flag)
+		}
+	}
+	return conflicts
+}
+
+// findArgs returns the `nova find` arguments shared by Helm and container scans,
+// so both honor the same data source and cluster settings.
+func (s *Scanner) findArgs(mode string) []string {
+	args := []string{"find", "--format", "json", mode}
 
 	// Add ArtifactHub polling if enabled
 	if s.config.PollArtifactHub {
 		args = append(args, "--poll-artifacthub")
 	}
 
+	return append(args, s.clusterArgs()...)
+}
+
+// clusterArgs returns the kubeconfig and context arguments shared by all scans.
+func (s *Scanner) clusterArgs() []string {
+	var args []string
+
 	// Add kubeconfig if not running in-cluster
 	if kubeconfig := getKubeconfig(s.config.Kubeconfig); kubeconfig != "" {
 		args = append(args, "--kubeconfig", kubeconfig)
 	}
 
 	// Add context if specified
-	if s.config.Context != "" {
-		args = append(args, "--context", s.config.Context)
+	if kubeContext := s.KubeContext(); kubeContext != "" {
+		args = append(args, "--context", kubeContext)
 	}
+	return args
+}
 
-	// Add include-all to get all releases, not just outdated
-	args = append(args, "--include-all")
+// runNova executes nova with the given arguments, bounded by the configured scan timeout.
+func (s *Scanner) runNova(ctx context.Context, scanType string, args []string) ([]byte, error) {
+	if s.config.ScanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ScanTimeout)
+		defer cancel()
+	}
 
-	cmd := exec.CommandContext(ctx, "nova", args...)
 	s.logger.Debug().Strs("args", args).Msg("Executing nova command")
 
-	output, err := cmd.Output()
+	output, err := s.run.Run(ctx, s.binary, args...)
+	if s.config.DumpNovaOutput != "" {
+		// Dump failed scans too: their partial output is what needs inspecting
+		if dumpErr := s.dumpOutput(scanType, args, output); dumpErr != nil {
+			s.logger.Warn().Err(dumpErr).Str("dir", s.config.DumpNovaOutput).Msg("Failed to dump nova output")
+		}
+	}
 	if err != nil {
-		// Try to get stderr for more context
-		if exitErr, ok := err.(*exec.ExitError); ok {
+		// Log nova's stderr for context, before wrapping hides the *exec.ExitError
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
 			s.logger.Error().
 				Str("stderr", string(exitErr.Stderr)).
 				Strs("args", args).
 				Err(err).
 				Msg("Nova command failed")
 		}
-		s.logger.ScanError("helm", err)
+
+		// A hung nova process is killed when the deadline passes; report it as a timeout
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w after %s: %w", ErrScanTimeout, s.config.ScanTimeout, context.DeadlineExceeded)
+			s.logger.ScanError(scanType, ErrorReason(err), err)
+			return nil, err
+		}
+
+		// Tag known failures (nova missing, cluster unreachable, ...) so callers can tell them apart
+		if class := classifyCommandError(err); class != nil {
+			err = fmt.Errorf("%w: %w", class, err)
+		}
+		if errors.Is(err, ErrArtifactHubRateLimited) {
+			// Nova has no flag to slow down its polling, so point at the settings that reduce it
+			s.logger.Events().Warn().
+				Str("event", "artifacthub_rate_limited").
+				Str("scan_type", scanType).
+				Msg("ArtifactHub rate-limited nova's chart polling; spread out scans with startJitter or a longer scanInterval, or disable pollArtifactHub")
+		}
+
+		s.logger.ScanError(scanType, ErrorReason(err), err)
 		return nil, fmt.Errorf("nova command failed: %w", err)
 	}
 
-	// Parse Nova output
-	var novaOutput NovaOutput
-	if err := json.Unmarshal(output, &novaOutput); err != nil {
-		// Try parsing as array directly (older Nova versions)
-		var releases []ReleaseOutput
-		if err2 := json.Unmarshal(output, &releases); err2 != nil {
-			return nil, fmt.Errorf("failed to parse nova output: %w", err)
-		}
-		novaOutput.HelmReleases = releases
+	return output, nil
+}
+
+// Version returns the output of `nova version`.
+func (s *Scanner) Version(ctx context.Context) (string, error) {
+	output, err := s.run.Run(ctx, s.binary, "version")
+	if err != nil {
+		return "", fmt.Errorf("nova version failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ScanHelm scans for outdated Helm releases using Nova CLI.
+func (s *Scanner) ScanHelm(ctx context.Context) (result *HelmScanResult, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "nova.ScanHelm",
+		trace.WithAttributes(attribute.String("kube.context", s.kubeContext)))
+	defer func() { tracing.End(span, err) }()
+
+	s.logger.ScanStart("helm")
+	start := time.Now()
+
+	output, err := s.runNova(ctx, "helm", s.helmArgs())
+	if err != nil {
+		return nil, err
 	}
 
-	// Filter by ignore lists
+	// Parse Nova output (empty output means there is nothing to report), skipping invalid records
+	var releases []ReleaseOutput
+	var invalid int
+	if isEmptyOutput(output) {
+		s.logger.Debug().Str("scan_type", "helm").Msg("Nova returned no output, treating as no releases")
+	} else if releases, invalid, err = parseHelmOutput(output, s.logger); err != nil {
+		return nil, err
+	}
+
+	// Filter by allowlist and ignore lists
 	var filtered []ReleaseOutput
-	for _, release := range novaOutput.HelmReleases {
-		if s.shouldIgnoreRelease(release) {
+	for _, release := range releases {
+		if !s.isAllowedChart(release.ChartName) || s.shouldIgnoreRelease(release) {
 			continue
 		}
+		release.Context = s.kubeContext
 		filtered = append(filtered, release)
 	}
 
@@ -173,8 +538,63 @@ func (s *Scanner) ScanHelm(ctx context.Context) (*HelmScanResult, error) {
 				continue
 			}
 
-			// Apply severity filtering
-			if s.meetsMinSeverity(release.Installed.Version, release.Latest.Version) {
+			// Overridden versions are usually pinned on purpose
+			if release.Overridden && s.config.SkipOverridden {
+				s.logger.Debug().
+					Str("release", release.ReleaseName).
+					Str("chart", release.ChartName).
+					Msg("Skipping release: version is overridden")
+				continue
+			}
+
+			// Nova only reports the overall latest chart version, so major bumps of pinned charts are dropped
+			if s.isMajorBumpOfPinned(release) {
+				s.logger.Debug().
+					Str("release", release.ReleaseName).
+					Str("chart", release.ChartName).
+					Str("latestVersion", release.Latest.Version).
+					Msg("Skipping release: chart is pinned to its major version")
+				continue
+			}
+
+			// Give new upstream versions time to settle before reporting them
+			if s.isTooNew(ctx, release) {
+				s.logger.Debug().
+					Str("release", release.ReleaseName).
+					Str("chart", release.ChartName).
+					Str("latestVersion", release.Latest.Version).
+					Dur("minReleaseAge", s.config.MinReleaseAge).
+					Msg("Skipping release: latest version is younger than minReleaseAge")
+				continue
+			}
+
+			// Releases only a version or two behind aren't worth an issue yet
+			if s.isTooFewVersionsBehind(ctx, release) {
+				s.logger.Debug().
+					Str("release", release.ReleaseName).
+					Str("chart", release.ChartName).
+					Str("latestVersion", release.Latest.Version).
+					Int("minVersionsBehind", s.config.MinVersionsBehind).
+					Msg("Skipping release: fewer than minVersionsBehind versions behind latest")
+				continue
+			}
+
+			// Apply severity filtering, escalating upgrades that fix known vulnerabilities
+			meetsSeverity := s.meetsMinSeverity(release.Installed.Version, release.Latest.Version)
+			if severity, ok := s.calverSeverity(release); ok {
+				release.Severity = severity
+				meetsSeverity = severity >= s.config.SeverityLevel()
+			}
+			if severity := s.securitySeverity(ctx, release); severity > 0 {
+				release.SecurityAlert = true
+				meetsSeverity = meetsSeverity || severity >= s.config.SeverityLevel()
+			}
+			// Deprecated charts need replacing however small the available bump is
+			if release.Deprecated && s.config.DeprecatedAlwaysReport {
+				meetsSeverity = true
+			}
+
+			if meetsSeverity {
 				outdated = append(outdated, release)
 				s.logger.OutdatedFound(
 					"helm",
@@ -189,71 +609,69 @@ func (s *Scanner) ScanHelm(ctx context.Context) (*HelmScanResult, error) {
 
 	duration := time.Since(start)
 	s.logger.ScanEnd("helm", duration, len(filtered), len(outdated))
+	span.SetAttributes(
+		attribute.Int("nova.releases.total", len(filtered)),
+		attribute.Int("nova.releases.outdated", len(outdated)),
+	)
 
 	return &HelmScanResult{
-		AllReleases: filtered,
-		Outdated:    outdated,
-		Duration:    duration,
+		AllReleases:    filtered,
+		Outdated:       outdated,
+		InvalidRecords: invalid,
+		Duration:       duration,
 	}, nil
 }
 
 // ScanContainers scans for outdated container images using Nova CLI.
-// skipNamespaces contains namespaces with outdated Helm releases - containers in these
-// namespaces will be skipped to avoid duplicate issues (updating the Helm chart will update the containers).
-func (s *Scanner) ScanContainers(ctx context.Context, skipNamespaces map[string]bool) (*ContainerScanResult, error) {
+// helm is the Helm scan of the same context, or nil: containers in namespaces with outdated
+// Helm releases are skipped to avoid duplicate issues (updating the Helm chart will update the
+// containers), and with skipAllHelmManagedContainers so are those of any scanned release.
+func (s *Scanner) ScanContainers(ctx context.Context, helm *HelmScanResult) (result *ContainerScanResult, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "nova.ScanContainers",
+		trace.WithAttributes(attribute.String("kube.context", s.kubeContext)))
+	defer func() { tracing.End(span, err) }()
+
 	s.logger.ScanStart("container")
 	start := time.Now()
 
-	// Build Nova command for container scanning
-	args := []string{"find", "--format", "json", "--containers"}
-
-	// Add kubeconfig if not running in-cluster
-	if kubeconfig := getKubeconfig(s.config.Kubeconfig); kubeconfig != "" {
-		args = append(args, "--kubeconfig", kubeconfig)
-	}
-
-	// Add context if specified
-	if s.config.Context != "" {
-		args = append(args, "--context", s.config.Context)
-	}
-
-	cmd := exec.CommandContext(ctx, "nova", args...)
-
-	output, err := cmd.Output()
+	output, err := s.runNova(ctx, "container", s.containerArgs())
 	if err != nil {
-		// Try to get stderr for more context
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			s.logger.Error().
-				Str("stderr", string(exitErr.Stderr)).
-				Err(err).
-				Msg("Nova command failed")
-		}
-		s.logger.ScanError("container", err)
-		return nil, fmt.Errorf("nova command failed: %w", err)
+		return nil, err
 	}
 
-	// Parse Nova output
-	var novaOutput NovaOutput
-	if err := json.Unmarshal(output, &novaOutput); err != nil {
-		return nil, fmt.Errorf("failed to parse nova output: %w", err)
+	// Parse Nova output (empty output means there is nothing to report), skipping invalid records
+	var containers []ContainerOutput
+	var invalid int
+	if isEmptyOutput(output) {
+		s.logger.Debug().Str("scan_type", "container").Msg("Nova returned no output, treating as no containers")
+	} else if containers, invalid, err = parseContainerOutput(output, s.logger); err != nil {
+		return nil, err
 	}
 
-	// Filter by ignore lists
+	// Filter by allowlist and ignore lists
 	var filtered []ContainerOutput
-	for _, container := range novaOutput.Containers {
-		if s.shouldIgnoreContainer(container) {
+	for _, container := range containers {
+		if !s.isAllowedImage(container.Name) || s.shouldIgnoreContainer(container) || !s.dropIgnoredWorkloads(&container) {
 			continue
 		}
+		container.Context = s.kubeContext
+		container.DigestPinned = isDigestPinned(container)
+		s.applyDesiredImageVersion(&container)
+		s.applyPinMajor(&container)
 		filtered = append(filtered, container)
 	}
 
 	// Filter outdated containers, skipping those in namespaces with outdated Helm releases
+	var skipNamespaces map[string]bool
+	if helm != nil {
+		skipNamespaces = helm.OutdatedNamespaces()
+	}
 	var outdated []ContainerOutput
 	var skipped []ContainerOutput
 	for _, container := range filtered {
 		if container.IsOld {
-			// Check if latest version matches a blacklisted pattern
-			if s.config.ShouldIgnoreVersion(container.LatestTag) {
+			// Check if latest version matches a blacklisted pattern (explicitly pinned tags are kept)
+			if container.DesiredTag == "" && s.config.ShouldIgnoreVersion(container.LatestTag) {
 				s.logger.Debug().
 					Str("image", container.Name).
 					Str("latestTag", container.LatestTag).
@@ -261,6 +679,34 @@ func (s *Scanner) ScanContainers(ctx context.Context, skipNamespaces map[string]
 				continue
 			}
 
+			// Images pinned by digest have no tag to bump; nova's latest tag only adds noise
+			if container.DigestPinned && s.config.SkipDigestPinned {
+				s.logger.Debug().
+					Str("image", container.Name).
+					Str("currentTag", container.CurrentTag).
+					Msg("Skipping container: image is pinned by digest")
+				continue
+			}
+
+			// Non-semver tags can't be classified; skipUnknownSeverity drops them as for Helm releases
+			if s.config.SkipUnknownSeverity && VersionSeverity(container.CurrentTag, container.LatestTag) == SeverityUnknown {
+				s.logger.Debug().
+					Str("image", container.Name).
+					Str("currentTag", container.CurrentTag).
+					Str("latestTag", container.LatestTag).
+					Msg("Skipping container: upgrade severity unknown, versions are not semver")
+				continue
+			}
+
+			// Images deployed by Helm charts are updated with the chart, outdated or not
+			if s.config.SkipAllHelmManagedContainers && isHelmManagedContainer(container, helm) {
+				s.logger.Debug().
+					Str("image", container.Name).
+					Str("reason", "all workloads are managed by Helm").
+					Msg("Skipping container (will be updated with Helm chart)")
+				continue
+			}
+
 			// Check if all affected workloads are in namespaces with outdated Helm releases
 			if s.shouldSkipContainerForHelm(container, skipNamespaces) {
 				skipped = append(skipped, container)
@@ -284,6 +730,11 @@ func (s *Scanner) ScanContainers(ctx context.Context, skipNamespaces map[string]
 
 	duration := time.Since(start)
 	s.logger.ScanEnd("container", duration, len(filtered), len(outdated))
+	span.SetAttributes(
+		attribute.Int("nova.containers.total", len(filtered)),
+		attribute.Int("nova.containers.outdated", len(outdated)),
+		attribute.Int("nova.containers.skipped", len(skipped)),
+	)
 
 	if len(skipped) > 0 {
 		s.logger.Info().
@@ -292,13 +743,96 @@ func (s *Scanner) ScanContainers(ctx context.Context, skipNamespaces map[string]
 	}
 
 	return &ContainerScanResult{
-		AllContainers: filtered,
-		Outdated:      outdated,
-		Skipped:       skipped,
-		Duration:      duration,
+		AllContainers:  filtered,
+		Outdated:       outdated,
+		Skipped:        skipped,
+		InvalidRecords: invalid,
+		Duration:       duration,
 	}, nil
 }
 
+// applyDesiredImageVersion makes a desiredImageVersions pin the container's upgrade target:
+// the container is outdated only while its current tag is below the desired tag.
+// Pins are ignored (nova's latest tag is kept) when either tag is not semver.
+func (s *Scanner) applyDesiredImageVersion(container *ContainerOutput) {
+	desired, ok := s.config.DesiredImageVersions[container.Name]
+	if !ok {
+		return
+	}
+
+	current, err := semver.NewVersion(container.CurrentTag)
+	if err != nil {
+		s.logger.Debug().Str("image", container.Name).Str("currentTag", container.CurrentTag).
+			Msg("Ignoring desired image version: current tag is not semver")
+		return
+	}
+	target, err := semver.NewVersion(desired)
+	if err != nil {
+		s.logger.Debug().Str("image", container.Name).Str("desiredTag", desired).
+			Msg("Ignoring desired image version: desired tag is not semver")
+		return
+	}
+
+	container.DesiredTag = desired
+	container.LatestTag = desired
+	container.IsOld = current.LessThan(target)
+}
+
+// applyPinMajor keeps a pinMajor image on its current major version: a major bump is replaced by
+// nova's latest tag within the current major, and the image is up to date if there is none.
+// Images with a desiredImageVersions pin or non-semver tags are left alone.
+func (s *Scanner) applyPinMajor(container *ContainerOutput) {
+	if container.DesiredTag != "" || !matchesPattern(s.config.PinMajor, container.Name) {
+		return
+	}
+	current, err := semver.NewVersion(container.CurrentTag)
+	if err != nil {
+		return
+	}
+	latest, err := semver.NewVersion(container.LatestTag)
+	if err != nil || latest.Major() <= current.Major() {
+		return
+	}
+
+	container.LatestTag = container.CurrentTag
+	container.IsOld = false
+	if within, err := semver.NewVersion(container.LatestMinorTag); err == nil &&
+		within.Major() == current.Major() && within.GreaterThan(current) {
+		container.LatestTag = container.LatestMinorTag
+		container.IsOld = true
+	}
+}
+
+// isMajorBumpOfPinned reports whether a release of a pinMajor chart is outdated only by a
+// major version bump. Non-semver versions are never treated as major bumps.
+func (s *Scanner) isMajorBumpOfPinned(release ReleaseOutput) bool {
+	if !matchesPattern(s.config.PinMajor, release.ChartName) {
+		return false
+	}
+	current, err := semver.NewVersion(release.Installed.Version)
+	if err != nil {
+		return false
+	}
+	latest, err := semver.NewVersion(release.Latest.Version)
+	return err == nil && latest.Major() > current.Major()
+}
+
+// isEmptyOutput reports whether nova printed nothing but whitespace, which it does
+// when no workloads match (e.g. a namespace filter). JSON null parses as empty already.
+func isEmptyOutput(output []byte) bool {
+	return len(bytes.TrimSpace(output)) == 0
+}
+
+// isDigestPinned reports whether a container image is referenced by digest (image@sha256:...)
+// rather than by tag. Nova reports the digest either in the image name or as the current tag.
+func isDigestPinned(container ContainerOutput) bool {
+	if strings.Contains(container.Name, "@") {
+		return true
+	}
+	algorithm, digest, ok := strings.Cut(container.CurrentTag, ":")
+	return ok && algorithm != "" && len(digest) >= 32 && strings.Trim(digest, "0123456789abcdef") == ""
+}
+
 // shouldSkipContainerForHelm returns true if all workloads for this container
 // are in namespaces that have outdated Helm releases.
 func (s *Scanner) shouldSkipContainerForHelm(container ContainerOutput, skipNamespaces map[string]bool) bool {
@@ -319,9 +853,30 @@ func (s *Scanner) shouldSkipContainerForHelm(container ContainerOutput, skipName
 	return true
 }
 
+// isHelmManagedContainer returns true if all workloads for this container belong to a release
+// of the Helm scan. Containers without workload information or Helm results are not.
+func isHelmManagedContainer(container ContainerOutput, helm *HelmScanResult) bool {
+	if helm == nil || len(container.AffectedWorkloads) == 0 {
+		return false
+	}
+	for _, workload := range container.AffectedWorkloads {
+		if !helm.IsHelmManaged(workload) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Scanner) shouldIgnoreRelease(release ReleaseOutput) bool {
+	if s.shouldIgnoreNamespace(release.Namespace) {
+		return true
+	}
+	// Tiller-managed releases can't be upgraded through Flux or Argo CD
+	if s.config.IgnoreHelmV2 && release.IsHelmV2() {
+		return true
+	}
 	for _, ignore := range s.config.IgnoreReleases {
-		if release.ReleaseName == ignore {
+		if matchRelease(ignore, release) {
 			return true
 		}
 	}
@@ -333,12 +888,132 @@ func (s *Scanner) shouldIgnoreRelease(release ReleaseOutput) bool {
 	return false
 }
 
+// matchRelease reports whether an ignoreReleases entry matches a release: a bare name matches
+// the release in every namespace, "namespace/release" only in that namespace.
+func matchRelease(entry string, release ReleaseOutput) bool {
+	if namespace, name, ok := strings.Cut(entry, "/"); ok {
+		return release.Namespace == namespace && release.ReleaseName == name
+	}
+	return release.ReleaseName == entry
+}
+
+// shouldIgnoreContainer returns true if the image matches an ignoreImages pattern or its
+// registry matches an ignoreRegistries pattern.
 func (s *Scanner) shouldIgnoreContainer(container ContainerOutput) bool {
 	for _, pattern := range s.config.IgnoreImages {
 		if matchGlob(pattern, container.Name) {
 			return true
 		}
 	}
+	registry := imageRegistry(container.Name)
+	for _, pattern := range s.config.IgnoreRegistries {
+		if matchRegistry(pattern, registry) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedChart returns true if onlyCharts is empty or the chart matches one of its patterns.
+func (s *Scanner) isAllowedChart(chartName string) bool {
+	return matchesAllowlist(s.config.OnlyCharts, chartName)
+}
+
+// isAllowedImage returns true if the image passes both the onlyImages and onlyRegistries allowlists.
+func (s *Scanner) isAllowedImage(image string) bool {
+	if !matchesAllowlist(s.config.OnlyImages, image) {
+		return false
+	}
+	if len(s.config.OnlyRegistries) == 0 {
+		return true
+	}
+	registry := imageRegistry(image)
+	for _, pattern := range s.config.OnlyRegistries {
+		if matchRegistry(pattern, registry) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRegistry reports whether a registry host matches a glob such as "*.azurecr.io".
+// Patterns are validated when the config is loaded.
+func matchRegistry(pattern, registry string) bool {
+	matched, _ := path.Match(strings.ToLower(pattern), registry)
+	return matched
+}
+
+// imageRegistry returns the registry host of an image reference, e.g. "ghcr.io" for
+// ghcr.io/org/app:tag. As in Docker, the first path component is only a registry if it
+// contains a "." or ":" or is "localhost"; other images (nginx, library/nginx) are on docker.io.
+func imageRegistry(name string) string {
+	first, _, ok := strings.Cut(name, "/")
+	if !ok || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return "docker.io"
+	}
+	first = strings.ToLower(first)
+	if first == "index.docker.io" || first == "registry-1.docker.io" {
+		return "docker.io"
+	}
+	return first
+}
+
+func matchesAllowlist(patterns []string, name string) bool {
+	return len(patterns) == 0 || matchesPattern(patterns, name)
+}
+
+// matchesPattern reports whether name matches any of the glob patterns.
+func matchesPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldIgnoreNamespace returns true if the namespace matches an ignoreNamespaces pattern.
+func (s *Scanner) shouldIgnoreNamespace(namespace string) bool {
+	for _, pattern := range s.config.IgnoreNamespaces {
+		if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+			// Patterns are validated when the config is loaded
+			if matched, _ := regexp.MatchString(expr, namespace); matched {
+				return true
+			}
+			continue
+		}
+		if matchGlob(pattern, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropIgnoredWorkloads removes the container's workloads in ignored namespaces or of ignored kinds.
+// Returns false if the container had workloads and all of them were dropped.
+func (s *Scanner) dropIgnoredWorkloads(container *ContainerOutput) bool {
+	if (len(s.config.IgnoreNamespaces) == 0 && len(s.config.IgnoreWorkloadKinds) == 0) || len(container.AffectedWorkloads) == 0 {
+		return true
+	}
+
+	var workloads []WorkloadOutput
+	for _, workload := range container.AffectedWorkloads {
+		if !s.shouldIgnoreNamespace(workload.Namespace) && !s.isIgnoredWorkloadKind(workload.Kind) {
+			workloads = append(workloads, workload)
+		}
+	}
+	container.AffectedWorkloads = workloads
+	return len(workloads) > 0
+}
+
+// isIgnoredWorkloadKind reports whether workloads of the given kind (e.g. "CronJob") are ignored.
+// Kinds match case-insensitively.
+func (s *Scanner) isIgnoredWorkloadKind(kind string) bool {
+	for _, ignored := range s.config.IgnoreWorkloadKinds {
+		if strings.EqualFold(ignored, kind) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -418,25 +1093,135 @@ func getKubeconfig(configuredPath string) string {
 }
 
 // meetsMinSeverity checks if the version difference meets the minimum severity threshold.
+// Upgrades of unknown severity are included unless skipUnknownSeverity is set.
 func (s *Scanner) meetsMinSeverity(currentVersion, latestVersion string) bool {
-	current, err := semver.NewVersion(currentVersion)
+	severity := VersionSeverity(currentVersion, latestVersion)
+	if severity == SeverityUnknown {
+		s.logger.Debug().
+			Str("currentVersion", currentVersion).
+			Str("latestVersion", latestVersion).
+			Bool("skipped", s.config.SkipUnknownSeverity).
+			Msg("Upgrade severity unknown: versions are not semver")
+		return !s.config.SkipUnknownSeverity
+	}
+	return severity >= s.config.SeverityLevel()
+}
+
+// calverSeverity classifies the upgrade of a calverCharts release by the changed date component.
+// Returns false when the chart isn't listed or its versions aren't calver, so semver applies.
+func (s *Scanner) calverSeverity(release ReleaseOutput) (int, bool) {
+	if len(s.config.CalverCharts) == 0 || !matchesAllowlist(s.config.CalverCharts, release.ChartName) {
+		return 0, false
+	}
+
+	current, ok := parseCalver(release.Installed.Version)
+	if !ok {
+		return 0, false
+	}
+	latest, ok := parseCalver(release.Latest.Version)
+	if !ok {
+		return 0, false
+	}
+
+	bump := calverBump(current, latest)
+	if bump == "" {
+		return 0, true
+	}
+	return s.config.CalverSeverityLevel(bump), true
+}
+
+// securitySeverity returns the severity escalation derived from ArtifactHub security reports.
+// Returns 0 when escalation is disabled or the reports are unavailable.
+func (s *Scanner) securitySeverity(ctx context.Context, release ReleaseOutput) int {
+	if s.security == nil {
+		return 0
+	}
+
+	current, err := s.security.SecuritySummary(ctx, release.ChartName, release.Installed.Version)
 	if err != nil {
-		// If we can't parse the version, include it
-		return true
+		s.logger.Debug().Err(err).Str("chart", release.ChartName).Msg("ArtifactHub security report unavailable")
+		return 0
 	}
+	latest, err := s.security.SecuritySummary(ctx, release.ChartName, release.Latest.Version)
+	if err != nil {
+		s.logger.Debug().Err(err).Str("chart", release.ChartName).Msg("ArtifactHub security report unavailable")
+		return 0
+	}
+
+	return artifacthub.SecuritySeverity(current, latest)
+}
 
+// isTooNew reports whether the latest version of a release was published less than minReleaseAge ago.
+// Releases without a known publish time are included.
+func (s *Scanner) isTooNew(ctx context.Context, release ReleaseOutput) bool {
+	if s.config.MinReleaseAge <= 0 {
+		return false
+	}
+
+	var released time.Time
+	if s.dates != nil {
+		var err error
+		if released, err = s.dates.ReleaseTime(ctx, release.ChartName, release.Latest.Version); err != nil {
+			s.logger.Debug().Err(err).Str("chart", release.ChartName).Msg("ArtifactHub release date unavailable")
+		}
+	}
+	if released.IsZero() {
+		s.logger.Debug().
+			Str("chart", release.ChartName).
+			Str("latestVersion", release.Latest.Version).
+			Msg("No release timestamp for latest version, ignoring minReleaseAge")
+		return false
+	}
+
+	return time.Since(released) < s.config.MinReleaseAge
+}
+
+// isTooFewVersionsBehind reports whether a release is fewer than minVersionsBehind published
+// versions behind its latest version. Releases without a known version count are included.
+func (s *Scanner) isTooFewVersionsBehind(ctx context.Context, release ReleaseOutput) bool {
+	if s.config.MinVersionsBehind <= 0 {
+		return false
+	}
+
+	var behind int
+	if s.versions != nil {
+		var err error
+		if behind, err = s.versions.VersionsBehind(ctx, release.ChartName, release.Installed.Version, release.Latest.Version); err != nil {
+			s.logger.Debug().Err(err).Str("chart", release.ChartName).Msg("ArtifactHub chart versions unavailable")
+		}
+	}
+	if behind == 0 {
+		s.logger.Debug().
+			Str("chart", release.ChartName).
+			Str("latestVersion", release.Latest.Version).
+			Msg("No version count for release, ignoring minVersionsBehind")
+		return false
+	}
+
+	return behind < s.config.MinVersionsBehind
+}
+
+// SeverityUnknown is the severity of an upgrade whose versions are not semver, so the size of
+// the bump can't be told.
+const SeverityUnknown = -1
+
+// VersionSeverity returns the severity of an upgrade from currentVersion to latestVersion.
+// Returns: 3 = critical (major), 2 = major (minor), 1 = minor (patch), 0 = none,
+// SeverityUnknown = unparseable
+func VersionSeverity(currentVersion, latestVersion string) int {
+	current, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return SeverityUnknown
+	}
 	latest, err := semver.NewVersion(latestVersion)
 	if err != nil {
-		// If we can't parse the version, include it
-		return true
+		return SeverityUnknown
 	}
-
-	severity := calculateSeverity(current, latest)
-	return severity >= s.config.SeverityLevel()
+	return calculateSeverity(current, latest)
 }
 
 // calculateSeverity determines the severity of a version difference.
-// Returns: 3 = critical (major), 2 = major (minor), 1 = minor (patch)
+// Returns: 3 = critical (major), 2 = major (minor), 1 = minor (patch or prerelease)
 func calculateSeverity(current, latest *semver.Version) int {
 	if latest.Major() > current.Major() {
 		return 3 // critical - major version bump
@@ -447,5 +1232,10 @@ func calculateSeverity(current, latest *semver.Version) int {
 	if latest.Patch() > current.Patch() {
 		return 1 // minor - patch version bump
 	}
+	// Same major.minor.patch: prerelease-to-stable or a later prerelease is still an upgrade.
+	// Compare follows semver precedence and ignores build metadata.
+	if latest.Compare(current) > 0 {
+		return 1
+	}
 	return 0
 }
@@ -1,14 +1,92 @@
 package nova
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/artifacthub"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/config"
 	"github.com/olohmann/nova-automated-cluster-scanner/pkg/logging"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+func TestMain(m *testing.M) {
+	// Most tests use a fake command runner, so don't require nova on the PATH
+	lookPath = func(file string) (string, error) { return file, nil }
+	os.Exit(m.Run())
+}
+
+func TestNewScanner_MissingBinary(t *testing.T) {
+	lookPath = exec.LookPath
+	defer func() { lookPath = func(file string) (string, error) { return file, nil } }()
+
+	cfg := &config.Config{MinSeverity: "minor", NovaBinary: "nova-does-not-exist"}
+	_, err := NewScanner(cfg, logging.NewLogger("error", "json"))
+	if err == nil {
+		t.Fatal("expected error for missing nova binary")
+	}
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("expected wrapped exec.ErrNotFound, got %v", err)
+	}
+	if !errors.Is(err, ErrNovaNotFound) {
+		t.Errorf("expected wrapped ErrNovaNotFound, got %v", err)
+	}
+	for _, want := range []string{"nova-does-not-exist", "install Nova", "NOVA_BINARY"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestNewScanner_NovaBinary(t *testing.T) {
+	tests := []struct {
+		name   string
+		binary string
+		want   string
+	}{
+		{"default", "", "nova"},
+		{"custom path", "/opt/bin/nova", "/opt/bin/nova"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner, err := NewScanner(&config.Config{MinSeverity: "minor", NovaBinary: tt.binary}, logging.NewLogger("error", "json"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var gotName string
+			scanner.run = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+				gotName = name
+				return []byte("3.8.0\n"), nil
+			})
+			version, err := scanner.Version(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotName != tt.want {
+				t.Errorf("expected nova invoked as %q, got %q", tt.want, gotName)
+			}
+			if version != "3.8.0" {
+				t.Errorf("expected trimmed version '3.8.0', got %q", version)
+			}
+		})
+	}
+}
+
 func TestNewScanner(t *testing.T) {
 	cfg := &config.Config{
 		ScanHelm:        true,
@@ -16,7 +94,7 @@ func TestNewScanner(t *testing.T) {
 		MinSeverity:     "minor",
 		PollArtifactHub: true,
 	}
-	logger := logging.NewLogger("info")
+	logger := logging.NewLogger("info", "json")
 
 	scanner, err := NewScanner(cfg, logger)
 	if err != nil {
@@ -30,6 +108,20 @@ func TestNewScanner(t *testing.T) {
 	}
 }
 
+func TestScanner_CloseTwice(t *testing.T) {
+	cfg := &config.Config{PollArtifactHub: true, ArtifactHubSecurity: true}
+	scanner, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner("", nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := range 2 {
+		if err := scanner.Close(); err != nil {
+			t.Errorf("Close() call %d error = %v", i+1, err)
+		}
+	}
+}
+
 func TestMatchGlob(t *testing.T) {
 	tests := []struct {
 		pattern string
@@ -82,6 +174,12 @@ func TestCalculateSeverity(t *testing.T) {
 		{"no change", "1.0.0", "1.0.0", 0},
 		{"major jump multiple", "1.5.3", "3.0.0", 3},
 		{"minor with patch", "1.0.0", "1.2.3", 2},
+		{"prerelease to stable", "1.0.0-rc1", "1.0.0", 1},
+		{"prerelease to later prerelease", "1.0.0-rc1", "1.0.0-rc2", 1},
+		{"prerelease to next major", "1.0.0-rc1", "2.0.0", 3},
+		{"stable to prerelease of same version", "1.0.0", "1.0.0-rc1", 0},
+		{"build metadata only", "1.0.0+build.1", "1.0.0+build.2", 0},
+		{"stable to stable patch", "1.2.3", "1.2.4", 1},
 	}
 
 	for _, tt := range tests {
@@ -97,6 +195,58 @@ func TestCalculateSeverity(t *testing.T) {
 	}
 }
 
+func TestVersionSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		want    int
+	}{
+		{"equal", "1.0.0", "1.0.0", 0},
+		{"patch bump", "1.0.0", "1.0.1", 1},
+		{"unparseable current", "stable", "1.0.1", SeverityUnknown},
+		{"unparseable latest", "1.0.0", "mainline", SeverityUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VersionSeverity(tt.current, tt.latest); got != tt.want {
+				t.Errorf("VersionSeverity(%s, %s) = %d, want %d", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_MeetsMinSeverity_SkipUnknown(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "critical", SkipUnknownSeverity: true}
+	scanner := &Scanner{config: cfg, logger: logging.NewLogger("error", "json")}
+
+	if scanner.meetsMinSeverity("stable", "mainline") {
+		t.Error("expected an unknown severity to be skipped with skipUnknownSeverity")
+	}
+	if !scanner.meetsMinSeverity("1.0.0", "2.0.0") {
+		t.Error("expected a major bump to meet the critical threshold")
+	}
+}
+
+func TestScanResults_UnknownSeverity(t *testing.T) {
+	helm := &HelmScanResult{Outdated: []ReleaseOutput{
+		{Installed: VersionInfo{Version: "1.0.0"}, Latest: VersionInfo{Version: "1.0.1"}},
+		{Installed: VersionInfo{Version: "latest"}, Latest: VersionInfo{Version: "1.0.0"}},
+	}}
+	if got := helm.UnknownSeverity(); got != 1 {
+		t.Errorf("expected 1 Helm release of unknown severity, got %d", got)
+	}
+
+	containers := &ContainerScanResult{Outdated: []ContainerOutput{
+		{Name: "nginx", CurrentTag: "stable", LatestTag: "mainline"},
+		{Name: "redis", CurrentTag: "7.0.0", LatestTag: "7.0.0"},
+	}}
+	if got := containers.UnknownSeverity(); got != 1 {
+		t.Errorf("expected 1 container of unknown severity, got %d", got)
+	}
+}
+
 func TestScanner_MeetsMinSeverity(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -128,7 +278,7 @@ func TestScanner_MeetsMinSeverity(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := &config.Config{MinSeverity: tt.minSeverity}
-			logger := logging.NewLogger("error") // suppress logs
+			logger := logging.NewLogger("error", "json") // suppress logs
 			scanner := &Scanner{config: cfg, logger: logger}
 
 			got := scanner.meetsMinSeverity(tt.current, tt.latest)
@@ -140,12 +290,80 @@ func TestScanner_MeetsMinSeverity(t *testing.T) {
 	}
 }
 
+func TestScanner_CalverSeverity(t *testing.T) {
+	defaults := map[string]string{"year": "minor", "month": "patch", "day": "patch"}
+	tests := []struct {
+		name    string
+		chart   string
+		mapping map[string]string
+		current string
+		latest  string
+		want    int
+		wantOK  bool
+	}{
+		{"year bump", "dated", defaults, "2023.12.01", "2024.01.05", 2, true},
+		{"month bump", "dated", defaults, "2024.05.18", "2024.06.18", 1, true},
+		{"day bump", "dated", defaults, "2024.06.18", "2024.06.19", 1, true},
+		{"month bump without day", "dated", defaults, "v2024.5", "v2024.6", 1, true},
+		{"same date rebuild", "dated", defaults, "2024.06.18-1", "2024.06.18-2", 1, true},
+		{"no change", "dated", defaults, "2024.06.18", "2024.06.18", 0, true},
+		{"custom year mapping", "dated", map[string]string{"year": "major", "month": "minor"}, "2023.06.18", "2024.06.18", 3, true},
+		{"custom month mapping", "dated", map[string]string{"year": "major", "month": "minor"}, "2024.05.18", "2024.06.18", 2, true},
+		{"unmapped day defaults to patch", "dated", map[string]string{"year": "major"}, "2024.06.18", "2024.06.20", 1, true},
+		{"chart not listed falls back to semver", "other", defaults, "2024.05.18", "2024.06.18", 0, false},
+		{"semver versions fall back to semver", "dated", defaults, "1.0.0", "2.0.0", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{CalverCharts: []string{"dated"}, CalverSeverity: tt.mapping}
+			scanner := &Scanner{config: cfg, logger: logging.NewLogger("error", "json")}
+			release := ReleaseOutput{ChartName: tt.chart, Installed: VersionInfo{Version: tt.current}, Latest: VersionInfo{Version: tt.latest}}
+
+			got, ok := scanner.calverSeverity(release)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("calverSeverity(%s, %s) = %d, %v, want %d, %v", tt.current, tt.latest, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanHelm_CalverCharts(t *testing.T) {
+	output := `{"helm_releases":[
+		{"release":"monthly","chartName":"dated-chart","namespace":"apps","Installed":{"version":"2024.05.18"},"Latest":{"version":"2024.06.18"},"outdated":true},
+		{"release":"yearly","chartName":"dated-chart","namespace":"apps","Installed":{"version":"2023.12.01"},"Latest":{"version":"2024.01.05"},"outdated":true},
+		{"release":"semver","chartName":"app","namespace":"apps","Installed":{"version":"2024.5.18"},"Latest":{"version":"2024.6.18"},"outdated":true}
+	]}`
+
+	cfg := &config.Config{
+		MinSeverity:    "major",
+		CalverCharts:   []string{"dated-*"},
+		CalverSeverity: map[string]string{"year": "minor", "month": "patch", "day": "patch"},
+	}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+
+	result, err := scanner.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	severities := map[string]int{}
+	for _, release := range result.Outdated {
+		severities[release.ReleaseName] = release.UpgradeSeverity()
+	}
+	// The monthly bump is a patch for a calver chart; the same versions on a semver chart are a minor bump
+	want := map[string]int{"yearly": 2, "semver": 2}
+	if !maps.Equal(severities, want) {
+		t.Errorf("expected release severities %v, got %v", want, severities)
+	}
+}
+
 func TestScanner_ShouldIgnoreRelease(t *testing.T) {
 	cfg := &config.Config{
-		IgnoreReleases: []string{"ignored-release", "another-ignored"},
+		IgnoreReleases: []string{"ignored-release", "another-ignored", "staging/app"},
 		IgnoreCharts:   []string{"ignored-chart"},
 	}
-	logger := logging.NewLogger("error")
+	logger := logging.NewLogger("error", "json")
 	scanner := &Scanner{config: cfg, logger: logger}
 
 	tests := []struct {
@@ -173,6 +391,26 @@ func TestScanner_ShouldIgnoreRelease(t *testing.T) {
 			release: ReleaseOutput{ReleaseName: "another-ignored", ChartName: "some-chart"},
 			want:    true,
 		},
+		{
+			name:    "bare name ignored in every namespace",
+			release: ReleaseOutput{ReleaseName: "ignored-release", Namespace: "prod", ChartName: "my-chart"},
+			want:    true,
+		},
+		{
+			name:    "ignored by namespace and release name",
+			release: ReleaseOutput{ReleaseName: "app", Namespace: "staging", ChartName: "my-chart"},
+			want:    true,
+		},
+		{
+			name:    "same release name in another namespace",
+			release: ReleaseOutput{ReleaseName: "app", Namespace: "prod", ChartName: "my-chart"},
+			want:    false,
+		},
+		{
+			name:    "namespace without the release",
+			release: ReleaseOutput{ReleaseName: "web", Namespace: "staging", ChartName: "my-chart"},
+			want:    false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -185,11 +423,771 @@ func TestScanner_ShouldIgnoreRelease(t *testing.T) {
 	}
 }
 
+func TestScanner_ShouldIgnoreNamespace(t *testing.T) {
+	cfg := &config.Config{IgnoreNamespaces: []string{"kube-system", "pr-*", `re:^review-\d+$`}}
+	scanner := &Scanner{config: cfg, logger: logging.NewLogger("error", "json")}
+
+	tests := []struct {
+		namespace string
+		want      bool
+	}{
+		{"kube-system", true},
+		{"kube-public", false},
+		{"pr-1234-preview", true},
+		{"app-pr-1", false},
+		{"review-42", true},
+		{"review-42-old", false},
+		{"default", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			if got := scanner.shouldIgnoreNamespace(tt.namespace); got != tt.want {
+				t.Errorf("shouldIgnoreNamespace(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+
+	if !scanner.shouldIgnoreRelease(ReleaseOutput{ReleaseName: "app", Namespace: "pr-7"}) {
+		t.Error("expected release in ignored namespace to be ignored")
+	}
+}
+
+func TestScanner_ScanContainers_IgnoreNamespaces(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", IgnoreNamespaces: []string{"pr-*"}}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = fakeRunner(`{"container_images":[
+		{"name":"redis","current_version":"7.0.0","latest_version":"7.2.0","outdated":true,
+		 "affectedWorkloads":[{"name":"cache","namespace":"apps"},{"name":"cache","namespace":"pr-12"}]},
+		{"name":"preview-only","current_version":"1.0.0","latest_version":"2.0.0","outdated":true,
+		 "affectedWorkloads":[{"name":"web","namespace":"pr-12"}]},
+		{"name":"no-workloads","current_version":"1.0.0","latest_version":"2.0.0","outdated":true}
+	]}`, nil)
+
+	result, err := scanner.ScanContainers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 2 {
+		t.Fatalf("expected 2 outdated containers, got %d", len(result.Outdated))
+	}
+	if workloads := result.Outdated[0].AffectedWorkloads; len(workloads) != 1 || workloads[0].Namespace != "apps" {
+		t.Errorf("expected only the apps workload to remain, got %v", workloads)
+	}
+	if result.Outdated[1].Name != "no-workloads" {
+		t.Errorf("expected container without workloads to be kept, got %s", result.Outdated[1].Name)
+	}
+}
+
+func TestScanner_ScanContainers_IgnoreWorkloadKinds(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", IgnoreWorkloadKinds: []string{"cronjob"}}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(`{"container_images":[
+		{"name":"redis","current_version":"7.0.0","latest_version":"7.2.0","outdated":true,
+		 "affectedWorkloads":[{"name":"cache","namespace":"apps","kind":"Deployment"},{"name":"backup","namespace":"apps","kind":"CronJob"}]},
+		{"name":"batch-only","current_version":"1.0.0","latest_version":"2.0.0","outdated":true,
+		 "affectedWorkloads":[{"name":"report","namespace":"apps","kind":"CronJob"}]}
+	]}`, nil)))
+
+	result, err := scanner.ScanContainers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 1 {
+		t.Fatalf("expected only the container with a Deployment to remain, got %v", result.Outdated)
+	}
+	if workloads := result.Outdated[0].AffectedWorkloads; len(workloads) != 1 || workloads[0].Kind != "Deployment" {
+		t.Errorf("expected only the Deployment workload to remain, got %v", workloads)
+	}
+}
+
+func TestScanner_ScanHelm_Allowlist(t *testing.T) {
+	output := `{"helm_releases":[
+		{"release":"cm","chartName":"cert-manager","namespace":"cert-manager","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0"},"outdated":true},
+		{"release":"ingress","chartName":"ingress-nginx","namespace":"ingress","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0"},"outdated":true},
+		{"release":"grafana","chartName":"grafana","namespace":"monitoring","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0"},"outdated":true}
+	]}`
+
+	tests := []struct {
+		name   string
+		only   []string
+		ignore []string
+		want   []string
+	}{
+		{"allowlist only", []string{"cert-manager", "ingress-*"}, nil, []string{"cm", "ingress"}},
+		{"ignore only", nil, []string{"grafana"}, []string{"cm", "ingress"}},
+		{"ignore wins over allowlist", []string{"cert-manager", "ingress-*"}, []string{"ingress-nginx"}, []string{"cm"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor", OnlyCharts: tt.only, IgnoreCharts: tt.ignore}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+			scanner.run = fakeRunner(output, nil)
+
+			result, err := scanner.ScanHelm(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, release := range result.Outdated {
+				got = append(got, release.ReleaseName)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected releases %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanContainers_Allowlist(t *testing.T) {
+	output := `{"container_images":[
+		{"name":"docker.io/library/redis","current_version":"7.0.0","latest_version":"7.2.0","outdated":true},
+		{"name":"docker.io/library/postgres","current_version":"15.0","latest_version":"15.4","outdated":true},
+		{"name":"quay.io/prometheus/node-exporter","current_version":"1.0.0","latest_version":"1.1.0","outdated":true}
+	]}`
+
+	tests := []struct {
+		name   string
+		only   []string
+		ignore []string
+		want   []string
+	}{
+		{"allowlist only", []string{"docker.io/library/*"}, nil,
+			[]string{"docker.io/library/redis", "docker.io/library/postgres"}},
+		{"ignore only", nil, []string{"*/postgres"},
+			[]string{"docker.io/library/redis", "quay.io/prometheus/node-exporter"}},
+		{"ignore wins over allowlist", []string{"docker.io/library/*"}, []string{"*/postgres"},
+			[]string{"docker.io/library/redis"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor", OnlyImages: tt.only, IgnoreImages: tt.ignore}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+			scanner.run = fakeRunner(output, nil)
+
+			result, err := scanner.ScanContainers(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, container := range result.Outdated {
+				got = append(got, container.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected containers %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestImageRegistry(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"nginx", "docker.io"},
+		{"nginx:1.25", "docker.io"},
+		{"library/nginx", "docker.io"},
+		{"bitnami/redis:7.2", "docker.io"},
+		{"docker.io/library/nginx", "docker.io"},
+		{"index.docker.io/library/nginx", "docker.io"},
+		{"ghcr.io/org/app:tag", "ghcr.io"},
+		{"ghcr.io/org/app@sha256:4c5b3c2e1d0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b", "ghcr.io"},
+		{"nginx@sha256:4c5b3c2e1d0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b", "docker.io"},
+		{"myregistry.azurecr.io/team/api:1.0", "myregistry.azurecr.io"},
+		{"registry.local:5000/app", "registry.local:5000"},
+		{"localhost/app", "localhost"},
+		{"Quay.io/prometheus/node-exporter", "quay.io"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := imageRegistry(tt.name); got != tt.want {
+				t.Errorf("imageRegistry(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanContainers_Registries(t *testing.T) {
+	output := `{"container_images":[
+		{"name":"nginx","current_version":"1.24","latest_version":"1.25","outdated":true},
+		{"name":"quay.io/prometheus/node-exporter","current_version":"1.0.0","latest_version":"1.1.0","outdated":true},
+		{"name":"ghcr.io/org/api","current_version":"1.0.0","latest_version":"1.1.0","outdated":true},
+		{"name":"team.azurecr.io/worker","current_version":"1.0.0","latest_version":"1.1.0","outdated":true}
+	]}`
+
+	tests := []struct {
+		name   string
+		only   []string
+		ignore []string
+		want   []string
+	}{
+		{"only own registries", []string{"ghcr.io", "*.azurecr.io"}, nil,
+			[]string{"ghcr.io/org/api", "team.azurecr.io/worker"}},
+		{"ignore public registries", nil, []string{"docker.io", "quay.io"},
+			[]string{"ghcr.io/org/api", "team.azurecr.io/worker"}},
+		{"ignore wins over allowlist", []string{"ghcr.io", "docker.io"}, []string{"docker.io"},
+			[]string{"ghcr.io/org/api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor", OnlyRegistries: tt.only, IgnoreRegistries: tt.ignore}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+			scanner.run = fakeRunner(output, nil)
+
+			result, err := scanner.ScanContainers(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, container := range result.Outdated {
+				got = append(got, container.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected containers %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanContainers_DesiredImageVersions(t *testing.T) {
+	cfg := &config.Config{
+		MinSeverity:           "minor",
+		IgnoreVersionPatterns: []string{"-rc"},
+		DesiredImageVersions: map[string]string{
+			"redis":    "7.2.0",
+			"postgres": "15.4",
+			"nginx":    "1.24.0",
+			"busybox":  "stable",
+			"memcache": "1.7.0-rc1",
+		},
+	}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = fakeRunner(`{"container_images":[
+		{"name":"redis","current_version":"7.0.0","latest_version":"8.0.0","outdated":true},
+		{"name":"postgres","current_version":"15.4","latest_version":"16.1","outdated":true},
+		{"name":"nginx","current_version":"1.25.0","latest_version":"1.27.0","outdated":true},
+		{"name":"busybox","current_version":"1.36","latest_version":"1.37","outdated":true},
+		{"name":"memcache","current_version":"1.6.0","latest_version":"1.6.1","outdated":false}
+	]}`, nil)
+
+	result, err := scanner.ScanContainers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[string]ContainerOutput)
+	for _, container := range result.Outdated {
+		got[container.Name] = container
+	}
+
+	tests := []struct {
+		image      string
+		flagged    bool
+		latestTag  string
+		desiredTag string
+	}{
+		{"redis", true, "7.2.0", "7.2.0"},            // below the desired tag: target is the pin, not nova's latest
+		{"postgres", false, "", ""},                  // at the desired tag
+		{"nginx", false, "", ""},                     // above the desired tag
+		{"busybox", true, "1.37", ""},                // non-semver pin falls back to nova's latest
+		{"memcache", true, "1.7.0-rc1", "1.7.0-rc1"}, // pinned prerelease bypasses ignoreVersionPatterns
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			container, flagged := got[tt.image]
+			if flagged != tt.flagged {
+				t.Fatalf("expected flagged=%v, got %v", tt.flagged, flagged)
+			}
+			if !flagged {
+				return
+			}
+			if container.LatestTag != tt.latestTag || container.DesiredTag != tt.desiredTag {
+				t.Errorf("expected latest %q and desired %q, got %q and %q",
+					tt.latestTag, tt.desiredTag, container.LatestTag, container.DesiredTag)
+			}
+		})
+	}
+}
+
+func TestNewScanner_WithCommandRunner(t *testing.T) {
+	lookPath = exec.LookPath
+	defer func() { lookPath = func(file string) (string, error) { return file, nil } }()
+
+	cfg := &config.Config{MinSeverity: "minor", NovaBinary: "nova-does-not-exist"}
+	var gotName string
+	runner := CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		gotName = name
+		return []byte("3.8.0"), nil
+	})
+
+	scanner, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(runner))
+	if err != nil {
+		t.Fatalf("expected no PATH lookup with a custom runner, got %v", err)
+	}
+	if _, err := scanner.Version(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotName != "nova-does-not-exist" {
+		t.Errorf("expected the runner to receive the configured binary, got %q", gotName)
+	}
+}
+
+func TestScanner_ScanHelm_FakeRunner(t *testing.T) {
+	output := `{"helm_releases":[
+		{"release":"patch","chartName":"patch-chart","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"1.0.1"},"outdated":true},
+		{"release":"minor","chartName":"minor-chart","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0"},"outdated":true},
+		{"release":"major","chartName":"major-chart","namespace":"b","Installed":{"version":"1.0.0"},"Latest":{"version":"2.0.0"},"outdated":true},
+		{"release":"current","chartName":"current-chart","namespace":"b","Installed":{"version":"3.0.0"},"Latest":{"version":"3.0.0"},"outdated":false},
+		{"release":"rc","chartName":"rc-chart","namespace":"b","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0-rc.1"},"outdated":true}
+	]}`
+
+	tests := []struct {
+		name      string
+		cfg       config.Config
+		wantTotal int
+		want      []string
+	}{
+		{"outdated only", config.Config{MinSeverity: "minor"}, 5, []string{"patch", "minor", "major", "rc"}},
+		{"major severity", config.Config{MinSeverity: "major"}, 5, []string{"minor", "major", "rc"}},
+		{"critical severity", config.Config{MinSeverity: "critical"}, 5, []string{"major"}},
+		{"ignored release and chart", config.Config{MinSeverity: "minor", IgnoreReleases: []string{"patch"},
+			IgnoreCharts: []string{"major-chart"}}, 3, []string{"minor", "rc"}},
+		{"ignored namespace", config.Config{MinSeverity: "minor", IgnoreNamespaces: []string{"b"}}, 2, []string{"patch", "minor"}},
+		{"ignored version pattern", config.Config{MinSeverity: "minor", IgnoreVersionPatterns: []string{"-rc"}}, 5,
+			[]string{"patch", "minor", "major"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner, err := NewScanner(&tt.cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := scanner.ScanHelm(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.AllReleases) != tt.wantTotal {
+				t.Errorf("expected %d releases after ignore lists, got %d", tt.wantTotal, len(result.AllReleases))
+			}
+			var got []string
+			for _, release := range result.Outdated {
+				got = append(got, release.ReleaseName)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected outdated %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanHelm_ChartVersionIgnorePatterns(t *testing.T) {
+	// gateway-helm publishes dev builds as -latest versions; only that chart ignores them
+	output := `{"helm_releases":[
+		{"release":"gateway-dev","chartName":"gateway-helm","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"1.3.0-latest"},"outdated":true},
+		{"release":"gateway","chartName":"gateway-helm","namespace":"b","Installed":{"version":"1.0.0"},"Latest":{"version":"1.2.0"},"outdated":true},
+		{"release":"other","chartName":"other-chart","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"1.3.0-latest"},"outdated":true}
+	]}`
+	cfg := &config.Config{
+		MinSeverity:                "minor",
+		ChartVersionIgnorePatterns: map[string][]string{"gateway-helm": {"-latest"}},
+	}
+
+	scanner, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := scanner.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, release := range result.Outdated {
+		got = append(got, release.ReleaseName+"@"+release.Latest.Version)
+	}
+	if want := "gateway@1.2.0,other@1.3.0-latest"; strings.Join(got, ",") != want {
+		t.Errorf("expected outdated %s, got %v", want, got)
+	}
+}
+
+func TestScanner_ScanContainers_FakeRunner(t *testing.T) {
+	output := `{"container_images":[
+		{"name":"k8s.gcr.io/pause","current_version":"3.5","latest_version":"3.9","outdated":true},
+		{"name":"redis","current_version":"7.0.0","latest_version":"7.0.1","outdated":true},
+		{"name":"postgres","current_version":"15.0.0","latest_version":"16.0.0","outdated":true},
+		{"name":"nginx","current_version":"1.25.0","latest_version":"1.25.0","outdated":false}
+	]}`
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{"outdated only", config.Config{MinSeverity: "minor"}, []string{"k8s.gcr.io/pause", "redis", "postgres"}},
+		{"ignored images", config.Config{MinSeverity: "minor", IgnoreImages: []string{"*/pause"}}, []string{"redis", "postgres"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner, err := NewScanner(&tt.cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := scanner.ScanContainers(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, container := range result.Outdated {
+				got = append(got, container.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected outdated %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+func TestScanner_PinMajor(t *testing.T) {
+	helmOutput := `{"helm_releases":[
+		{"release":"db","chartName":"postgresql","namespace":"a","Installed":{"version":"12.1.0"},"Latest":{"version":"12.1.3"},"outdated":true},
+		{"release":"db-next","chartName":"postgresql","namespace":"b","Installed":{"version":"12.1.0"},"Latest":{"version":"13.0.0"},"outdated":true},
+		{"release":"cache","chartName":"redis","namespace":"a","Installed":{"version":"17.0.0"},"Latest":{"version":"18.0.0"},"outdated":true}
+	]}`
+	containerOutput := `{"container_images":[
+		{"name":"postgres","current_version":"15.2.0","latest_version":"16.1.0","latest_minor_version":"15.4.0","outdated":true},
+		{"name":"docker.io/library/mysql","current_version":"8.0.36","latest_version":"9.0.0","latest_minor_version":"8.0.36","outdated":true},
+		{"name":"mongo","current_version":"6.0.1","latest_version":"6.0.5","latest_minor_version":"6.0.5","outdated":true},
+		{"name":"nginx","current_version":"1.24.0","latest_version":"2.0.0","latest_minor_version":"1.25.0","outdated":true}
+	]}`
+	cfg := &config.Config{MinSeverity: "minor", PinMajor: []string{"postgresql", "postgres", "*/mysql", "mongo"}}
+
+	helm, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(helmOutput, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	releases, err := helm.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, release := range releases.Outdated {
+		got = append(got, release.ReleaseName+"@"+release.Latest.Version)
+	}
+	// The pinned chart's patch bump is reported, its major bump isn't; unpinned charts are unaffected
+	if want := "db@12.1.3,cache@18.0.0"; strings.Join(got, ",") != want {
+		t.Errorf("expected outdated releases %s, got %v", want, got)
+	}
+
+	containers, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(containerOutput, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	images, err := containers.ScanContainers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = nil
+	for _, container := range images.Outdated {
+		got = append(got, container.Name+"@"+container.LatestTag)
+	}
+	// postgres is bumped within major 15 and mysql has no newer 8.x tag
+	if want := "postgres@15.4.0,mongo@6.0.5,nginx@2.0.0"; strings.Join(got, ",") != want {
+		t.Errorf("expected outdated images %s, got %v", want, got)
+	}
+}
+
+func TestScanner_SkipOverridden(t *testing.T) {
+	helmOutput := `{"helm_releases":[
+		{"release":"db","chartName":"postgresql","namespace":"a","Installed":{"version":"12.1.0"},"Latest":{"version":"13.0.0"},"outdated":true,"overridden":true},
+		{"release":"cache","chartName":"redis","namespace":"a","Installed":{"version":"17.0.0"},"Latest":{"version":"18.0.0"},"outdated":true}
+	]}`
+
+	for _, tt := range []struct {
+		skipOverridden bool
+		want           string
+	}{
+		{false, "db,cache"},
+		{true, "cache"},
+	} {
+		cfg := &config.Config{MinSeverity: "minor", SkipOverridden: tt.skipOverridden}
+		scanner, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(helmOutput, nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := scanner.ScanHelm(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, release := range result.Outdated {
+			got = append(got, release.ReleaseName)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("skipOverridden=%v: expected outdated releases %s, got %v", tt.skipOverridden, tt.want, got)
+		}
+		if len(result.AllReleases) != 2 {
+			t.Errorf("skipOverridden=%v: expected both releases in AllReleases, got %d", tt.skipOverridden, len(result.AllReleases))
+		}
+	}
+}
+
+func TestScanner_DeprecatedAlwaysReport(t *testing.T) {
+	helmOutput := `{"helm_releases":[
+		{"release":"legacy","chartName":"old-chart","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"1.0.1"},"outdated":true,"deprecated":true},
+		{"release":"cache","chartName":"redis","namespace":"a","Installed":{"version":"17.0.0"},"Latest":{"version":"17.0.1"},"outdated":true}
+	]}`
+
+	for _, tt := range []struct {
+		alwaysReport bool
+		want         string
+	}{
+		{false, ""},
+		{true, "legacy"},
+	} {
+		cfg := &config.Config{MinSeverity: "critical", DeprecatedAlwaysReport: tt.alwaysReport}
+		scanner, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(helmOutput, nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := scanner.ScanHelm(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, release := range result.Outdated {
+			got = append(got, release.ReleaseName)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("deprecatedAlwaysReport=%v: expected outdated releases %q, got %v", tt.alwaysReport, tt.want, got)
+		}
+	}
+}
+
+func TestScanner_IgnoreHelmV2(t *testing.T) {
+	helmOutput := `{"helm_releases":[
+		{"release":"legacy","chartName":"nginx-ingress","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"2.0.0"},"outdated":true,"helmVersion":"2"},
+		{"release":"cache","chartName":"redis","namespace":"a","Installed":{"version":"17.0.0"},"Latest":{"version":"18.0.0"},"outdated":true,"helmVersion":"3"}
+	]}`
+
+	for _, tt := range []struct {
+		ignoreHelmV2 bool
+		want         string
+	}{
+		{false, "legacy,cache"},
+		{true, "cache"},
+	} {
+		cfg := &config.Config{MinSeverity: "minor", IgnoreHelmV2: tt.ignoreHelmV2}
+		scanner, err := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(helmOutput, nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := scanner.ScanHelm(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, release := range result.AllReleases {
+			got = append(got, release.ReleaseName)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("ignoreHelmV2=%v: expected releases %s, got %v", tt.ignoreHelmV2, tt.want, got)
+		}
+	}
+}
+
+func TestReleaseOutput_IsHelmV2(t *testing.T) {
+	for version, want := range map[string]bool{"2": true, "v2": true, "2.17.0": true, "3": false, "v3": false, "": false} {
+		if got := (ReleaseOutput{HelmVersion: version}).IsHelmV2(); got != want {
+			t.Errorf("IsHelmV2(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestIsDigestPinned(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab12", 16)
+	tests := []struct {
+		name      string
+		container ContainerOutput
+		want      bool
+	}{
+		{"tag", ContainerOutput{Name: "nginx", CurrentTag: "1.25.0"}, false},
+		{"registry with port", ContainerOutput{Name: "registry.local:5000/app", CurrentTag: "v2"}, false},
+		{"latest tag", ContainerOutput{Name: "redis", CurrentTag: "latest"}, false},
+		{"digest as current tag", ContainerOutput{Name: "nginx", CurrentTag: digest}, true},
+		{"digest in image name", ContainerOutput{Name: "nginx@" + digest, CurrentTag: ""}, true},
+		{"short hex after colon", ContainerOutput{Name: "nginx", CurrentTag: "sha256:abc"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDigestPinned(tt.container); got != tt.want {
+				t.Errorf("isDigestPinned(%s, %s) = %v, want %v", tt.container.Name, tt.container.CurrentTag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanContainers_DigestPinned(t *testing.T) {
+	output := `{"container_images":[
+		{"name":"redis","current_version":"7.0.0","latest_version":"7.0.1","outdated":true},
+		{"name":"nginx","current_version":"sha256:` + strings.Repeat("0f", 32) + `","latest_version":"1.27.0","outdated":true}
+	]}`
+
+	tests := []struct {
+		name       string
+		skipPinned bool
+		want       []string
+	}{
+		{"skip digest-pinned", true, []string{"redis"}},
+		{"report digest-pinned", false, []string{"redis", "nginx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor", SkipDigestPinned: tt.skipPinned}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+
+			result, err := scanner.ScanContainers(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, container := range result.Outdated {
+				got = append(got, container.Name)
+				if pinned := container.Name == "nginx"; container.DigestPinned != pinned {
+					t.Errorf("expected %s DigestPinned = %v", container.Name, pinned)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected outdated %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanContainers_SkipUnknownSeverity(t *testing.T) {
+	output := `{"container_images":[
+		{"name":"redis","current_version":"7.0.0","latest_version":"7.0.1","outdated":true},
+		{"name":"nginx","current_version":"stable","latest_version":"mainline","outdated":true}
+	]}`
+
+	tests := []struct {
+		name        string
+		skipUnknown bool
+		want        []string
+	}{
+		{"skip unknown severity", true, []string{"redis"}},
+		{"report unknown severity", false, []string{"redis", "nginx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor", SkipUnknownSeverity: tt.skipUnknown}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+
+			result, err := scanner.ScanContainers(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, container := range result.Outdated {
+				got = append(got, container.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected outdated %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanContainers_SkipAllHelmManaged(t *testing.T) {
+	// Trimmed from nova find --containers --format json; workloads carry no labels
+	output := `{"container_images":[
+		{"name":"quay.io/jetstack/cert-manager-controller","current_version":"v1.12.0","latest_version":"v1.14.4",
+		 "latest_minor_version":"v1.12.9","outdated":true,"affectedWorkloads":[
+			{"name":"cert-manager","namespace":"cert-manager","kind":"Deployment","container":"cert-manager-controller"}]},
+		{"name":"quay.io/jetstack/cert-manager-cainjector","current_version":"v1.12.0","latest_version":"v1.14.4",
+		 "latest_minor_version":"v1.12.9","outdated":true,"affectedWorkloads":[
+			{"name":"cert-manager-cainjector","namespace":"cert-manager","kind":"Deployment","container":"cert-manager-cainjector"}]},
+		{"name":"docker.io/library/redis","current_version":"7.0.0","latest_version":"7.2.4",
+		 "latest_minor_version":"7.2.4","outdated":true,"affectedWorkloads":[
+			{"name":"cache-redis-master","namespace":"apps","kind":"StatefulSet","container":"redis"},
+			{"name":"sessions","namespace":"apps","kind":"StatefulSet","container":"redis"}]},
+		{"name":"docker.io/library/busybox","current_version":"1.35","latest_version":"1.36",
+		 "latest_minor_version":"1.36","outdated":true,"affectedWorkloads":null}
+	],"err_images":null}`
+	helm := &HelmScanResult{AllReleases: []ReleaseOutput{
+		{ReleaseName: "cert-manager", ChartName: "cert-manager", Namespace: "cert-manager"},
+		{ReleaseName: "cache", ChartName: "redis", Namespace: "apps"},
+		{ReleaseName: "sessions", ChartName: "redis", Namespace: "other"},
+	}}
+
+	tests := []struct {
+		name string
+		skip bool
+		helm *HelmScanResult
+		want []string
+	}{
+		{"skip fully Helm-managed", true, helm, []string{"quay.io/jetstack/cert-manager-cainjector",
+			"docker.io/library/redis", "docker.io/library/busybox"}},
+		{"report Helm-managed", false, helm, []string{"quay.io/jetstack/cert-manager-controller",
+			"quay.io/jetstack/cert-manager-cainjector", "docker.io/library/redis", "docker.io/library/busybox"}},
+		{"report without Helm results", true, nil, []string{"quay.io/jetstack/cert-manager-controller",
+			"quay.io/jetstack/cert-manager-cainjector", "docker.io/library/redis", "docker.io/library/busybox"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor", SkipAllHelmManagedContainers: tt.skip}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+
+			result, err := scanner.ScanContainers(context.Background(), tt.helm)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, container := range result.Outdated {
+				got = append(got, container.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected outdated %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHelmScanResult_IsHelmManaged(t *testing.T) {
+	helm := &HelmScanResult{AllReleases: []ReleaseOutput{{ReleaseName: "ingress", Namespace: "edge"}}}
+
+	tests := []struct {
+		workload WorkloadOutput
+		want     bool
+	}{
+		{WorkloadOutput{Name: "ingress", Namespace: "edge"}, true},
+		{WorkloadOutput{Name: "ingress-nginx-controller", Namespace: "edge"}, false},
+		{WorkloadOutput{Name: "ingress", Namespace: "apps"}, false},
+		{WorkloadOutput{Name: "ingressgateway", Namespace: "edge"}, false},
+	}
+	for _, tt := range tests {
+		if got := helm.IsHelmManaged(tt.workload); got != tt.want {
+			t.Errorf("IsHelmManaged(%s/%s) = %v, want %v", tt.workload.Namespace, tt.workload.Name, got, tt.want)
+		}
+	}
+}
+
 func TestScanner_ShouldIgnoreContainer(t *testing.T) {
 	cfg := &config.Config{
 		IgnoreImages: []string{"*/pause:*", "*/coredns:*", "nginx:*"},
 	}
-	logger := logging.NewLogger("error")
+	logger := logging.NewLogger("error", "json")
 	scanner := &Scanner{config: cfg, logger: logger}
 
 	tests := []struct {
@@ -251,9 +1249,33 @@ func TestHelmScanResult_OutdatedNamespaces(t *testing.T) {
 	}
 }
 
+func TestScanResult_OutdatedByNamespace(t *testing.T) {
+	helm := &HelmScanResult{
+		Outdated: []ReleaseOutput{
+			{ReleaseName: "release1", Namespace: "ns1"},
+			{ReleaseName: "release2", Namespace: "ns2"},
+			{ReleaseName: "release3", Namespace: "ns1"},
+		},
+	}
+	if got, want := helm.OutdatedByNamespace(), map[string]int{"ns1": 2, "ns2": 1}; !maps.Equal(got, want) {
+		t.Errorf("helm OutdatedByNamespace() = %v, want %v", got, want)
+	}
+
+	containers := &ContainerScanResult{
+		Outdated: []ContainerOutput{
+			{Name: "nginx", AffectedWorkloads: []WorkloadOutput{{Name: "web", Namespace: "ns1"}, {Name: "proxy", Namespace: "ns1"}, {Name: "edge", Namespace: "ns2"}}},
+			{Name: "redis", AffectedWorkloads: []WorkloadOutput{{Name: "cache", Namespace: "ns1"}}},
+			{Name: "busybox"},
+		},
+	}
+	if got, want := containers.OutdatedByNamespace(), map[string]int{"ns1": 2, "ns2": 1, "": 1}; !maps.Equal(got, want) {
+		t.Errorf("container OutdatedByNamespace() = %v, want %v", got, want)
+	}
+}
+
 func TestScanner_ShouldSkipContainerForHelm(t *testing.T) {
 	cfg := &config.Config{}
-	logger := logging.NewLogger("error")
+	logger := logging.NewLogger("error", "json")
 	scanner := &Scanner{config: cfg, logger: logger}
 
 	tests := []struct {
@@ -366,6 +1388,69 @@ func TestReleaseOutput_JSONParsing(t *testing.T) {
 	}
 }
 
+func TestScanner_ScanHelm_OutputSchemas(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantOutdated   bool
+		wantDeprecated bool
+		wantApp        bool
+		wantRepository string
+	}{
+		{
+			name: "legacy object",
+			output: `{"helm_releases":[{"release":"cert-manager","chartName":"cert-manager","namespace":"infra",
+				"Installed":{"version":"1.0.0"},"Latest":{"version":"1.5.0"},"outdated":true,"deprecated":true}]}`,
+			wantOutdated:   true,
+			wantDeprecated: true,
+		},
+		{
+			name: "legacy array",
+			output: `[{"release":"cert-manager","chartName":"cert-manager","namespace":"infra",
+				"Installed":{"version":"1.0.0"},"Latest":{"version":"1.5.0"},"outdated":true}]`,
+			wantOutdated: true,
+		},
+		{
+			name: "nested status",
+			output: `{"helm":[{"release":"cert-manager","chartName":"cert-manager","namespace":"infra",
+				"Installed":{"version":"1.0.0"},"Latest":{"version":"1.5.0"},
+				"repositoryURL":"https://charts.jetstack.io",
+				"status":{"outdated":true,"outdatedApp":true,"deprecated":true}}]}`,
+			wantOutdated:   true,
+			wantDeprecated: true,
+			wantApp:        true,
+			wantRepository: "https://charts.jetstack.io",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor"}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(tt.output, nil)))
+
+			result, err := scanner.ScanHelm(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.AllReleases) != 1 {
+				t.Fatalf("expected 1 release, got %d", len(result.AllReleases))
+			}
+
+			release := result.AllReleases[0]
+			if release.IsOld != tt.wantOutdated || release.Deprecated != tt.wantDeprecated || release.OutdatedApp != tt.wantApp {
+				t.Errorf("expected outdated=%v deprecated=%v outdatedApp=%v, got %v %v %v",
+					tt.wantOutdated, tt.wantDeprecated, tt.wantApp, release.IsOld, release.Deprecated, release.OutdatedApp)
+			}
+			if release.RepositoryURL != tt.wantRepository {
+				t.Errorf("expected repository %q, got %q", tt.wantRepository, release.RepositoryURL)
+			}
+			if len(result.Outdated) != 1 {
+				t.Errorf("expected the release to be reported as outdated, got %d", len(result.Outdated))
+			}
+		})
+	}
+}
+
 func TestContainerOutput_JSONParsing(t *testing.T) {
 	jsonData := `{
 		"name": "nginx",
@@ -403,6 +1488,604 @@ func TestContainerOutput_JSONParsing(t *testing.T) {
 }
 
 // Helper to unmarshal JSON (using encoding/json)
+// fakeSecurityReporter returns canned ArtifactHub security summaries keyed by version.
+type fakeSecurityReporter struct {
+	summaries map[string]*artifacthub.SecurityReportSummary
+	err       error
+}
+
+func (f *fakeSecurityReporter) SecuritySummary(ctx context.Context, chartName, version string) (*artifacthub.SecurityReportSummary, error) {
+	return f.summaries[version], f.err
+}
+
+func TestScanner_SecuritySeverity(t *testing.T) {
+	release := ReleaseOutput{
+		ChartName: "my-chart",
+		Installed: VersionInfo{Version: "1.0.0"},
+		Latest:    VersionInfo{Version: "1.0.1"},
+	}
+
+	tests := []struct {
+		name     string
+		reporter securityReporter
+		want     int
+	}{
+		{"disabled", nil, 0},
+		{
+			name: "critical fixed by upgrade",
+			reporter: &fakeSecurityReporter{summaries: map[string]*artifacthub.SecurityReportSummary{
+				"1.0.0": {Critical: 1},
+				"1.0.1": {},
+			}},
+			want: 3,
+		},
+		{
+			name:     "no reports available",
+			reporter: &fakeSecurityReporter{},
+			want:     0,
+		},
+		{
+			name:     "lookup error degrades gracefully",
+			reporter: &fakeSecurityReporter{err: errors.New("artifacthub unavailable")},
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				config:   &config.Config{MinSeverity: "critical"},
+				logger:   logging.NewLogger("error", "json"),
+				security: tt.reporter,
+			}
+			if got := scanner.securitySeverity(context.Background(), release); got != tt.want {
+				t.Errorf("securitySeverity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewScanner_ArtifactHubSecurity(t *testing.T) {
+	logger := logging.NewLogger("error", "json")
+
+	scanner, _ := NewScanner(&config.Config{PollArtifactHub: true, ArtifactHubSecurity: true}, logger)
+	if scanner.security == nil {
+		t.Error("expected security reporter when ArtifactHub security is enabled")
+	}
+
+	scanner, _ = NewScanner(&config.Config{PollArtifactHub: false, ArtifactHubSecurity: true}, logger)
+	if scanner.security != nil {
+		t.Error("expected no security reporter when ArtifactHub polling is disabled")
+	}
+
+	scanner, _ = NewScanner(&config.Config{PollArtifactHub: true, MinReleaseAge: time.Hour}, logger)
+	if scanner.dates == nil || scanner.security != nil || scanner.versions != nil {
+		t.Error("expected only release date lookups when minReleaseAge is set")
+	}
+
+	scanner, _ = NewScanner(&config.Config{PollArtifactHub: true, MinVersionsBehind: 3}, logger)
+	if scanner.versions == nil || scanner.dates != nil || scanner.security != nil {
+		t.Error("expected only version counts when minVersionsBehind is set")
+	}
+}
+
+// fakeReleaseDater returns canned publish times keyed by chart version.
+type fakeReleaseDater map[string]time.Time
+
+func (f fakeReleaseDater) ReleaseTime(ctx context.Context, chartName, version string) (time.Time, error) {
+	return f[version], nil
+}
+
+func TestScanner_ScanHelm_MinReleaseAge(t *testing.T) {
+	output := `{"helm_releases":[
+		{"release":"fresh","chartName":"fresh","namespace":"apps","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0"},"outdated":true},
+		{"release":"settled","chartName":"settled","namespace":"apps","Installed":{"version":"1.0.0"},"Latest":{"version":"1.2.0"},"outdated":true},
+		{"release":"undated","chartName":"undated","namespace":"apps","Installed":{"version":"1.0.0"},"Latest":{"version":"1.3.0"},"outdated":true}
+	]}`
+	dates := fakeReleaseDater{
+		"1.1.0": time.Now().Add(-24 * time.Hour),
+		"1.2.0": time.Now().Add(-30 * 24 * time.Hour),
+	}
+
+	cfg := &config.Config{MinSeverity: "minor", MinReleaseAge: 7 * 24 * time.Hour}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+	scanner.dates = dates
+
+	result, err := scanner.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var reported []string
+	for _, release := range result.Outdated {
+		reported = append(reported, release.ReleaseName)
+	}
+	// fresh is younger than a week; undated has no timestamp and is reported anyway
+	if want := []string{"settled", "undated"}; !slices.Equal(reported, want) {
+		t.Errorf("expected releases %v, got %v", want, reported)
+	}
+}
+
+// fakeVersionCounter returns canned version counts keyed by chart name.
+type fakeVersionCounter map[string]int
+
+func (f fakeVersionCounter) VersionsBehind(ctx context.Context, chartName, installedVersion, latestVersion string) (int, error) {
+	return f[chartName], nil
+}
+
+func TestScanner_ScanHelm_MinVersionsBehind(t *testing.T) {
+	output := `{"helm_releases":[
+		{"release":"close","chartName":"close","namespace":"apps","Installed":{"version":"1.0.0"},"Latest":{"version":"1.0.1"},"outdated":true},
+		{"release":"far","chartName":"far","namespace":"apps","Installed":{"version":"1.0.0"},"Latest":{"version":"1.5.0"},"outdated":true},
+		{"release":"uncounted","chartName":"uncounted","namespace":"apps","Installed":{"version":"1.0.0"},"Latest":{"version":"1.1.0"},"outdated":true}
+	]}`
+
+	cfg := &config.Config{MinSeverity: "minor", MinVersionsBehind: 3}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"), WithCommandRunner(fakeRunner(output, nil)))
+	scanner.versions = fakeVersionCounter{"close": 1, "far": 5}
+
+	result, err := scanner.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var reported []string
+	for _, release := range result.Outdated {
+		reported = append(reported, release.ReleaseName)
+	}
+	// close is only 1 version behind; uncounted has no version count and is reported anyway
+	if want := []string{"far", "uncounted"}; !slices.Equal(reported, want) {
+		t.Errorf("expected releases %v, got %v", want, reported)
+	}
+}
+
+// fakeRunner returns a CommandRunner that records the args and returns canned output.
+func fakeRunner(output string, gotArgs *[]string) CommandRunner {
+	return CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if gotArgs != nil {
+			*gotArgs = args
+		}
+		return []byte(output), nil
+	})
+}
+
+// blockingRunner returns a CommandRunner that hangs until its context is cancelled, like a stuck nova.
+func blockingRunner() CommandRunner {
+	return CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+}
+
+func TestScanner_ScanTimeout(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", ScanTimeout: 10 * time.Millisecond}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = blockingRunner()
+
+	if _, err := scanner.ScanHelm(context.Background()); !errors.Is(err, ErrScanTimeout) {
+		t.Errorf("expected ErrScanTimeout from ScanHelm, got %v", err)
+	}
+	if _, err := scanner.ScanContainers(context.Background(), nil); !errors.Is(err, ErrScanTimeout) {
+		t.Errorf("expected ErrScanTimeout from ScanContainers, got %v", err)
+	}
+}
+
+func TestScanner_CommandFailure_NotTimeout(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor", ScanTimeout: time.Minute}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return nil, errors.New("exit status 1")
+	})
+
+	_, err := scanner.ScanHelm(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if errors.Is(err, ErrScanTimeout) {
+		t.Errorf("expected a normal failure, got timeout: %v", err)
+	}
+}
+
+func TestClassifyStderr(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{"helm cluster unreachable", `Error: Kubernetes cluster unreachable: Get "https://10.0.0.1:6443/version": dial tcp 10.0.0.1:6443: connect: connection refused`, ErrClusterUnreachable},
+		{"kubectl cannot connect", "Unable to connect to the server: dial tcp: lookup aks-prod.hcp.westeurope.azmk8s.io: no such host", ErrClusterUnreachable},
+		{"dial timeout", "dial tcp 10.0.0.1:443: i/o timeout", ErrClusterUnreachable},
+		{"expired credentials", "error: You must be logged in to the server (Unauthorized)", ErrClusterUnauthorized},
+		{"unknown ca", `Get "https://10.0.0.1:6443/version": tls: failed to verify certificate: x509: certificate signed by unknown authority`, ErrClusterTLS},
+		{"expired serving certificate", "Unable to connect to the server: x509: certificate has expired or is not yet valid", ErrClusterTLS},
+		{"rbac", `secrets is forbidden: User "system:serviceaccount:nova:nova" cannot list resource "secrets"`, ErrClusterUnauthorized},
+		{"unknown context", "error loading config: context was not found for specified context: prod", ErrKubeconfig},
+		{"no kubeconfig", "invalid configuration: no configuration has been provided", ErrKubeconfig},
+		{"binary missing", `exec: "nova": executable file not found in $PATH`, ErrNovaNotFound},
+		{"binary path missing", "fork/exec /opt/bin/nova: no such file or directory", ErrNovaNotFound},
+		{"artifacthub too many requests", `Error: error getting packages from artifacthub: 429 Too Many Requests`, ErrArtifactHubRateLimited},
+		{"artifacthub rate limit", "failed to query ArtifactHub API: rate limit exceeded, retry later", ErrArtifactHubRateLimited},
+		{"api server throttling", "Error from server (TooManyRequests): the server has received too many requests and has asked us to try again later", nil},
+		{"unknown", "panic: runtime error: index out of range", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyStderr(tt.stderr); got != tt.want {
+				t.Errorf("classifyStderr(%q) = %v, want %v", tt.stderr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_CommandFailure_Classified(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantErr    error
+		wantReason string
+	}{
+		{"stderr signature", &exec.ExitError{Stderr: []byte("Error: Kubernetes cluster unreachable: connection refused")},
+			ErrClusterUnreachable, "cluster_unreachable"},
+		{"binary vanished", &exec.Error{Name: "nova", Err: exec.ErrNotFound}, ErrNovaNotFound, "nova_not_found"},
+		{"untrusted certificate", &exec.ExitError{Stderr: []byte("x509: certificate signed by unknown authority")},
+			ErrClusterTLS, "cluster_tls"},
+		{"artifacthub rate limit", &exec.ExitError{Stderr: []byte("Error: error getting packages from artifacthub: 429 Too Many Requests")},
+			ErrArtifactHubRateLimited, "artifacthub_rate_limited"},
+		{"unknown failure", errors.New("exit status 2"), nil, "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor"}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+			scanner.run = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+				return nil, tt.err
+			})
+
+			_, err := scanner.ScanHelm(context.Background())
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if got := ErrorReason(err); got != tt.wantReason {
+				t.Errorf("ErrorReason() = %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestScanner_CommandFailure_LogsStderr(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &config.Config{MinSeverity: "minor"}
+	scanner, _ := NewScanner(cfg, logging.NewLoggerWithWriter("error", "json", &buf))
+	scanner.run = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return nil, &exec.ExitError{Stderr: []byte("Error: Kubernetes cluster unreachable: connection refused")}
+	})
+
+	if _, err := scanner.ScanHelm(context.Background()); !errors.Is(err, ErrClusterUnreachable) {
+		t.Fatalf("expected %v, got %v", ErrClusterUnreachable, err)
+	}
+	if !strings.Contains(buf.String(), `"stderr":"Error: Kubernetes cluster unreachable: connection refused"`) {
+		t.Errorf("expected nova's stderr to be logged, got:\n%s", buf.String())
+	}
+}
+
+func TestScanner_ArtifactHubRateLimitWarning(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &config.Config{MinSeverity: "minor", PollArtifactHub: true}
+	scanner, _ := NewScanner(cfg, logging.NewLoggerWithWriter("warn", "json", &buf))
+	scanner.run = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return nil, &exec.ExitError{Stderr: []byte("Error: error getting packages from artifacthub: 429 Too Many Requests")}
+	})
+
+	if _, err := scanner.ScanHelm(context.Background()); !errors.Is(err, ErrArtifactHubRateLimited) {
+		t.Fatalf("expected %v, got %v", ErrArtifactHubRateLimited, err)
+	}
+	if !strings.Contains(buf.String(), `"event":"artifacthub_rate_limited"`) || !strings.Contains(buf.String(), "pollArtifactHub") {
+		t.Errorf("expected a rate limit warning pointing at the ArtifactHub settings, got:\n%s", buf.String())
+	}
+}
+
+func TestScanner_ScanHelm_SkipsInvalidRecords(t *testing.T) {
+	output := `{"helm_releases":[
+		{"release":"good","chartName":"good-chart","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"2.0.0"},"outdated":true},
+		{"release":"bad-type","chartName":"chart","namespace":"a","Installed":{"version":1.0},"Latest":{"version":"2.0.0"},"outdated":true},
+		{"release":"no-latest","chartName":"chart","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":""},"outdated":true},
+		{"chartName":"nameless","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"1.0.0"}},
+		{"release":"current","chartName":"current-chart","namespace":"a","Installed":{"version":"1.0.0"},"Latest":{"version":"1.0.0"}}
+	]}`
+	cfg := &config.Config{MinSeverity: "minor"}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = fakeRunner(output, nil)
+
+	result, err := scanner.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("expected invalid records to be skipped, got %v", err)
+	}
+	if result.InvalidRecords != 3 {
+		t.Errorf("expected 3 invalid records, got %d", result.InvalidRecords)
+	}
+	if len(result.AllReleases) != 2 || len(result.Outdated) != 1 || result.Outdated[0].ReleaseName != "good" {
+		t.Errorf("expected the valid releases to be processed, got %+v", result)
+	}
+}
+
+func TestScanner_ScanContainers_SkipsInvalidRecords(t *testing.T) {
+	output := `{"container_images":[
+		{"name":"docker.io/library/redis","current_version":"7.0.0","latest_version":"7.2.0","outdated":true},
+		{"name":"docker.io/library/postgres","current_version":15.0,"latest_version":"15.4","outdated":true},
+		{"name":"docker.io/library/nginx","current_version":"1.24","outdated":true},
+		"not an object",
+		{"name":"quay.io/prometheus/node-exporter","current_version":"1.0.0","latest_version":"1.1.0","outdated":true}
+	]}`
+	cfg := &config.Config{MinSeverity: "minor"}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = fakeRunner(output, nil)
+
+	result, err := scanner.ScanContainers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected invalid records to be skipped, got %v", err)
+	}
+	if result.InvalidRecords != 3 {
+		t.Errorf("expected 3 invalid records, got %d", result.InvalidRecords)
+	}
+	var got []string
+	for _, container := range result.Outdated {
+		got = append(got, container.Name)
+	}
+	if want := "docker.io/library/redis,quay.io/prometheus/node-exporter"; strings.Join(got, ",") != want {
+		t.Errorf("expected outdated containers %s, got %v", want, got)
+	}
+}
+
+func TestScanner_InvalidRecordsLogged(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &config.Config{MinSeverity: "minor"}
+	scanner, _ := NewScanner(cfg, logging.NewLoggerWithWriter("warn", "json", &buf))
+	scanner.run = fakeRunner(`{"container_images":[{"name":"","current_version":"1.0"},{"name":"redis","current_version":"7.0.0","latest_version":"7.2.0","outdated":true}]}`, nil)
+
+	if _, err := scanner.ScanContainers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Skipping invalid nova record", "missing image name", `"invalid":1`, `"valid":1`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected log to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestScanner_ParseFailure(t *testing.T) {
+	cfg := &config.Config{MinSeverity: "minor"}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = CommandRunnerFunc(func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte("{not json"), nil
+	})
+
+	if _, err := scanner.ScanHelm(context.Background()); !errors.Is(err, ErrParse) || ErrorReason(err) != "parse" {
+		t.Errorf("expected ErrParse from ScanHelm, got %v", err)
+	}
+	if _, err := scanner.ScanContainers(context.Background(), nil); !errors.Is(err, ErrParse) {
+		t.Errorf("expected ErrParse from ScanContainers, got %v", err)
+	}
+}
+
+func TestScanner_FindArgs(t *testing.T) {
+	scanHelm := func(s *Scanner) error { _, err := s.ScanHelm(context.Background()); return err }
+	scanContainers := func(s *Scanner) error { _, err := s.ScanContainers(context.Background(), nil); return err }
+
+	tests := []struct {
+		name       string
+		poll       bool
+		includeAll bool
+		scan       func(s *Scanner) error
+		want       []string
+		wantNone   []string
+	}{
+		{"helm with polling", true, true, scanHelm,
+			[]string{"--helm", "--poll-artifacthub", "--include-all"}, nil},
+		{"helm outdated only", true, false, scanHelm,
+			[]string{"--helm", "--poll-artifacthub"}, []string{"--include-all"}},
+		{"containers with polling", true, true, scanContainers,
+			[]string{"--containers", "--poll-artifacthub"}, []string{"--include-all"}},
+		{"containers without polling", false, true, scanContainers,
+			[]string{"--containers"}, []string{"--poll-artifacthub"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor", PollArtifactHub: tt.poll, IncludeAllReleases: tt.includeAll, Context: "test"}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+
+			var args []string
+			scanner.run = fakeRunner(`{}`, &args)
+			if err := tt.scan(scanner); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			joined := strings.Join(args, " ")
+			for _, want := range append(tt.want, "--format json", "--context test") {
+				if !strings.Contains(joined, want) {
+					t.Errorf("expected %q in args, got %v", want, args)
+				}
+			}
+			for _, unwanted := range tt.wantNone {
+				if strings.Contains(joined, unwanted) {
+					t.Errorf("expected no %q in args, got %v", unwanted, args)
+				}
+			}
+		})
+	}
+}
+
+func TestScanner_NovaExtraArgs(t *testing.T) {
+	scanHelm := func(s *Scanner) error { _, err := s.ScanHelm(context.Background()); return err }
+	scanContainers := func(s *Scanner) error { _, err := s.ScanContainers(context.Background(), nil); return err }
+
+	for name, scan := range map[string]func(s *Scanner) error{"helm": scanHelm, "container": scanContainers} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cfg := &config.Config{MinSeverity: "minor", IncludeAllReleases: true, Context: "test",
+				NovaExtraArgs: []string{"--wide", "--timeout", "30s"}}
+			var args []string
+			scanner, _ := NewScanner(cfg, logging.NewLoggerWithWriter("warn", "json", &buf), WithCommandRunner(fakeRunner(`{}`, &args)))
+
+			if err := scan(scanner); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n := len(args); n < 3 || strings.Join(args[n-3:], " ") != "--wide --timeout 30s" {
+				t.Errorf("expected the extra args after the managed flags, got %v", args)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("expected no conflict warning, got:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestScanner_NovaExtraArgs_Conflicts(t *testing.T) {
+	if got := conflictingArgs([]string{"--wide", "--format=yaml", "--context", "other", "--timeout=30s"}); !slices.Equal(got, []string{"--format", "--context"}) {
+		t.Errorf("conflictingArgs() = %v, want [--format --context]", got)
+	}
+
+	var buf bytes.Buffer
+	cfg := &config.Config{MinSeverity: "minor", NovaExtraArgs: []string{"--format", "yaml"}}
+	if _, err := NewScanner(cfg, logging.NewLoggerWithWriter("warn", "json", &buf), WithCommandRunner(fakeRunner(`{}`, nil))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "novaExtraArgs") || !strings.Contains(buf.String(), `"flags":["--format"]`) {
+		t.Errorf("expected a warning about the conflicting --format, got:\n%s", buf.String())
+	}
+}
+
+func TestScanner_ContextScanners_Single(t *testing.T) {
+	cfg := &config.Config{Context: "current", MinSeverity: "minor"}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+
+	scanners := scanner.ContextScanners()
+	if len(scanners) != 1 || scanners[0] != scanner {
+		t.Fatalf("expected the scanner itself without a contexts list, got %d scanners", len(scanners))
+	}
+	if got := scanners[0].KubeContext(); got != "current" {
+		t.Errorf("expected KubeContext 'current', got %q", got)
+	}
+
+	var args []string
+	scanner.run = fakeRunner(`{"helm_releases":[{"release":"app","namespace":"default","outdated":true,
+		"Installed":{"version":"1.0.0"},"Latest":{"version":"2.0.0"}}]}`, &args)
+	result, err := scanner.ScanHelm(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(strings.Join(args, " "), "--context current") {
+		t.Errorf("expected --context current in args, got %v", args)
+	}
+	if result.Outdated[0].Context != "" {
+		t.Errorf("expected untagged results in single-context mode, got %q", result.Outdated[0].Context)
+	}
+}
+
+func TestScanner_ContextScanners_Multiple(t *testing.T) {
+	cfg := &config.Config{
+		Context:     "ignored",
+		Contexts:    []string{"prod", "staging"},
+		MinSeverity: "minor",
+	}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+
+	scanners := scanner.ContextScanners()
+	if len(scanners) != 2 {
+		t.Fatalf("expected 2 context scanners, got %d", len(scanners))
+	}
+
+	helmOutput := `{"helm_releases":[{"release":"app","namespace":"default","outdated":true,
+		"Installed":{"version":"1.0.0"},"Latest":{"version":"2.0.0"}}]}`
+	containerOutput := `{"container_images":[{"name":"nginx","current_version":"1.20","latest_version":"1.25","outdated":true}]}`
+
+	for i, want := range []string{"prod", "staging"} {
+		contextScanner := scanners[i]
+		if got := contextScanner.KubeContext(); got != want {
+			t.Errorf("expected KubeContext %q, got %q", want, got)
+		}
+
+		var args []string
+		contextScanner.run = fakeRunner(helmOutput, &args)
+		helm, err := contextScanner.ScanHelm(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(strings.Join(args, " "), "--context "+want) {
+			t.Errorf("expected --context %s in helm args, got %v", want, args)
+		}
+		if helm.Outdated[0].Context != want {
+			t.Errorf("expected release tagged with %q, got %q", want, helm.Outdated[0].Context)
+		}
+
+		contextScanner.run = fakeRunner(containerOutput, &args)
+		containers, err := contextScanner.ScanContainers(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(strings.Join(args, " "), "--context "+want) {
+			t.Errorf("expected --context %s in container args, got %v", want, args)
+		}
+		if containers.Outdated[0].Context != want {
+			t.Errorf("expected container tagged with %q, got %q", want, containers.Outdated[0].Context)
+		}
+	}
+}
+
 func unmarshalJSON(data []byte, v interface{}) error {
 	return json.Unmarshal(data, v)
 }
+
+func TestScanner_EmptyOutput(t *testing.T) {
+	for _, output := range []string{"", " \n\t", "null"} {
+		t.Run(strconv.Quote(output), func(t *testing.T) {
+			cfg := &config.Config{MinSeverity: "minor"}
+			scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+			scanner.run = fakeRunner(output, nil)
+
+			helm, err := scanner.ScanHelm(context.Background())
+			if err != nil {
+				t.Fatalf("expected empty helm result, got error: %v", err)
+			}
+			if len(helm.AllReleases) != 0 || len(helm.Outdated) != 0 {
+				t.Errorf("expected no releases, got %d", len(helm.AllReleases))
+			}
+
+			containers, err := scanner.ScanContainers(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("expected empty container result, got error: %v", err)
+			}
+			if len(containers.AllContainers) != 0 || len(containers.Outdated) != 0 {
+				t.Errorf("expected no containers, got %d", len(containers.AllContainers))
+			}
+		})
+	}
+}
+
+func TestScanner_TracingSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	cfg := &config.Config{MinSeverity: "minor", ScanHelm: true, ScanContainers: true}
+	scanner, _ := NewScanner(cfg, logging.NewLogger("error", "json"))
+	scanner.run = fakeRunner(`{}`, nil)
+
+	if _, err := scanner.ScanHelm(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := scanner.ScanContainers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		names = append(names, span.Name)
+	}
+	if strings.Join(names, ",") != "nova.ScanHelm,nova.ScanContainers" {
+		t.Errorf("expected scan spans, got %v", names)
+	}
+}
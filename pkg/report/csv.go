@@ -0,0 +1,84 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// csvHeader lists the columns written by WriteCSV.
+var csvHeader = []string{"type", "name", "namespace", "current", "latest", "severity", "deprecated"}
+
+// WriteCSV writes one row per outdated Helm release and container image.
+// Container rows list the namespaces of all affected workloads, separated by ";".
+func WriteCSV(w io.Writer, releases []nova.ReleaseOutput, containers []nova.ContainerOutput) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, release := range releases {
+		row := []string{
+			"helm",
+			release.ReleaseName,
+			release.Namespace,
+			release.Installed.Version,
+			release.Latest.Version,
+			severityName(release.UpgradeSeverity()),
+			strconv.FormatBool(release.Deprecated),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	for _, container := range containers {
+		var namespaces []string
+		seen := make(map[string]bool)
+		for _, workload := range container.AffectedWorkloads {
+			if !seen[workload.Namespace] {
+				namespaces = append(namespaces, workload.Namespace)
+				seen[workload.Namespace] = true
+			}
+		}
+
+		row := []string{
+			"container",
+			container.Name,
+			strings.Join(namespaces, ";"),
+			container.CurrentTag,
+			container.LatestTag,
+			severityName(nova.VersionSeverity(container.CurrentTag, container.LatestTag)),
+			"false",
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// severityName maps a version severity to the minSeverity names; unparseable versions are left blank.
+func severityName(severity int) string {
+	switch severity {
+	case 3:
+		return "critical"
+	case 2:
+		return "major"
+	case 1:
+		return "minor"
+	case nova.SeverityUnknown:
+		return "unknown"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func TestWriteCSV(t *testing.T) {
+	releases := append(testReleases(), nova.ReleaseOutput{
+		ReleaseName: "legacy, \"v1\"",
+		ChartName:   "legacy",
+		Namespace:   "apps",
+		Installed:   nova.VersionInfo{Version: "1.0.0"},
+		Latest:      nova.VersionInfo{Version: "1.1.0"},
+		Deprecated:  true,
+	})
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, releases, testContainers()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV output should be parseable: %v", err)
+	}
+
+	want := [][]string{
+		{"type", "name", "namespace", "current", "latest", "severity", "deprecated"},
+		{"helm", "cert-manager", "cert-manager", "1.13.0", "1.13.3", "minor", "false"},
+		{"helm", "ingress", "ingress", "3.0.0", "4.0.0", "critical", "false"},
+		{"helm", "legacy, \"v1\"", "apps", "1.0.0", "1.1.0", "major", "true"},
+		{"container", "docker.io/library/redis", "apps;jobs", "7.0.0", "7.2.0", "major", "false"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d: expected %v, got %v", i, want[i], rows[i])
+		}
+	}
+}
+
+func TestWriteCSV_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buf.String(); got != "type,name,namespace,current,latest,severity,deprecated\n" {
+		t.Errorf("expected only the header, got %q", got)
+	}
+}
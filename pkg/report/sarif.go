@@ -0,0 +1,184 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	toolName           = "nova-scanner"
+	toolInformationURI = "https://github.com/olohmann/nova-automated-cluster-scanner"
+
+	ruleOutdatedHelm      = "outdated-helm-chart"
+	ruleOutdatedContainer = "outdated-container-image"
+)
+
+// SARIFLog is a SARIF 2.1.0 document.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a single analysis run.
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the tool that produced the results.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver describes the tool component and its rules.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a kind of finding.
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFResult is a single finding.
+type SARIFResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    SARIFMessage      `json:"message"`
+	Locations  []SARIFLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// SARIFMessage holds human-readable text.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFLocation points at the Kubernetes resource a result applies to.
+type SARIFLocation struct {
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations"`
+}
+
+// SARIFLogicalLocation identifies a non-file location such as namespace/workload.
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// BuildSARIF builds a SARIF document with one result per outdated Helm release and container image.
+func BuildSARIF(toolVersion string, releases []nova.ReleaseOutput, containers []nova.ContainerOutput) *SARIFLog {
+	results := make([]SARIFResult, 0, len(releases)+len(containers))
+
+	for _, release := range releases {
+		results = append(results, SARIFResult{
+			RuleID: ruleOutdatedHelm,
+			Level:  sarifLevel(release.UpgradeSeverity()),
+			Message: SARIFMessage{Text: fmt.Sprintf("Helm release %s/%s uses chart %s %s; latest is %s",
+				release.Namespace, release.ReleaseName, release.ChartName, release.Installed.Version, release.Latest.Version)},
+			Locations: []SARIFLocation{{LogicalLocations: []SARIFLogicalLocation{
+				logicalLocation(release.Context, release.Namespace, release.ReleaseName, "helmRelease"),
+			}}},
+			Properties: resultProperties(release.Context, release.Installed.Version, release.Latest.Version),
+		})
+	}
+
+	for _, container := range containers {
+		var locations []SARIFLocation
+		for _, workload := range container.AffectedWorkloads {
+			locations = append(locations, SARIFLocation{LogicalLocations: []SARIFLogicalLocation{
+				logicalLocation(container.Context, workload.Namespace, workload.Kind+"/"+workload.Name, "workload"),
+			}})
+		}
+
+		results = append(results, SARIFResult{
+			RuleID: ruleOutdatedContainer,
+			Level:  sarifLevel(nova.VersionSeverity(container.CurrentTag, container.LatestTag)),
+			Message: SARIFMessage{Text: fmt.Sprintf("Container image %s uses tag %s; latest is %s",
+				container.Name, container.CurrentTag, container.LatestTag)},
+			Locations:  locations,
+			Properties: resultProperties(container.Context, container.CurrentTag, container.LatestTag),
+		})
+	}
+
+	return &SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:           toolName,
+				Version:        toolVersion,
+				InformationURI: toolInformationURI,
+				Rules: []SARIFRule{
+					{
+						ID:               ruleOutdatedHelm,
+						Name:             "OutdatedHelmChart",
+						ShortDescription: SARIFMessage{Text: "Helm release uses an outdated chart version"},
+					},
+					{
+						ID:               ruleOutdatedContainer,
+						Name:             "OutdatedContainerImage",
+						ShortDescription: SARIFMessage{Text: "Workload uses an outdated container image"},
+					},
+				},
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// WriteSARIF writes the document as indented JSON.
+func WriteSARIF(w io.Writer, log *SARIFLog) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+	return nil
+}
+
+// sarifLevel maps a version severity to a SARIF level: patch=note, minor=warning, major=error.
+// Unparseable versions are reported as warnings.
+func sarifLevel(severity int) string {
+	switch severity {
+	case 3:
+		return "error"
+	case 1:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// logicalLocation builds a namespace-scoped location, prefixed with the kube context in multi-context mode.
+func logicalLocation(kubeContext, namespace, name, kind string) SARIFLogicalLocation {
+	fullName := namespace + "/" + name
+	if kubeContext != "" {
+		fullName = kubeContext + "/" + fullName
+	}
+	return SARIFLogicalLocation{Name: name, FullyQualifiedName: fullName, Kind: kind}
+}
+
+// resultProperties returns the version details attached to each result.
+func resultProperties(kubeContext, current, latest string) map[string]string {
+	properties := map[string]string{
+		"currentVersion": current,
+		"latestVersion":  latest,
+	}
+	if kubeContext != "" {
+		properties["context"] = kubeContext
+	}
+	return properties
+}
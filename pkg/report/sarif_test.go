@@ -0,0 +1,139 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func testReleases() []nova.ReleaseOutput {
+	return []nova.ReleaseOutput{
+		{
+			ReleaseName: "cert-manager",
+			ChartName:   "cert-manager",
+			Namespace:   "cert-manager",
+			Installed:   nova.VersionInfo{Version: "1.13.0"},
+			Latest:      nova.VersionInfo{Version: "1.13.3"},
+		},
+		{
+			ReleaseName: "ingress",
+			ChartName:   "ingress-nginx",
+			Namespace:   "ingress",
+			Installed:   nova.VersionInfo{Version: "3.0.0"},
+			Latest:      nova.VersionInfo{Version: "4.0.0"},
+			Context:     "prod",
+		},
+	}
+}
+
+func testContainers() []nova.ContainerOutput {
+	return []nova.ContainerOutput{
+		{
+			Name:       "docker.io/library/redis",
+			CurrentTag: "7.0.0",
+			LatestTag:  "7.2.0",
+			AffectedWorkloads: []nova.WorkloadOutput{
+				{Name: "cache", Namespace: "apps", Kind: "StatefulSet", Container: "redis"},
+				{Name: "queue", Namespace: "jobs", Kind: "Deployment", Container: "redis"},
+			},
+		},
+	}
+}
+
+func TestWriteSARIF_Schema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, BuildSARIF("v1.2.3", testReleases(), testContainers())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("SARIF output should be valid JSON: %v", err)
+	}
+	for _, key := range []string{"$schema", "version", "runs"} {
+		if _, ok := doc[key]; !ok {
+			t.Errorf("expected top-level key %q", key)
+		}
+	}
+	if doc["version"] != "2.1.0" {
+		t.Errorf("expected version 2.1.0, got %v", doc["version"])
+	}
+
+	runs := doc["runs"].([]interface{})
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+	run := runs[0].(map[string]interface{})
+	driver := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})
+	if driver["name"] != "nova-scanner" || driver["version"] != "v1.2.3" {
+		t.Errorf("unexpected driver: %v", driver)
+	}
+	if results := run["results"].([]interface{}); len(results) != 3 {
+		t.Errorf("expected one result per outdated item (3), got %d", len(results))
+	}
+}
+
+func TestBuildSARIF_Results(t *testing.T) {
+	log := BuildSARIF("dev", testReleases(), testContainers())
+	results := log.Runs[0].Results
+
+	tests := []struct {
+		name         string
+		result       SARIFResult
+		ruleID       string
+		level        string
+		fullName     string
+		numLocations int
+	}{
+		{"patch helm update", results[0], "outdated-helm-chart", "note", "cert-manager/cert-manager", 1},
+		{"major helm update in context", results[1], "outdated-helm-chart", "error", "prod/ingress/ingress", 1},
+		{"minor container update", results[2], "outdated-container-image", "warning", "apps/StatefulSet/cache", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result.RuleID != tt.ruleID {
+				t.Errorf("expected ruleId %q, got %q", tt.ruleID, tt.result.RuleID)
+			}
+			if tt.result.Level != tt.level {
+				t.Errorf("expected level %q, got %q", tt.level, tt.result.Level)
+			}
+			if len(tt.result.Locations) != tt.numLocations {
+				t.Fatalf("expected %d locations, got %d", tt.numLocations, len(tt.result.Locations))
+			}
+			if got := tt.result.Locations[0].LogicalLocations[0].FullyQualifiedName; got != tt.fullName {
+				t.Errorf("expected location %q, got %q", tt.fullName, got)
+			}
+		})
+	}
+}
+
+func TestBuildSARIF_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, BuildSARIF("dev", nil, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"results": []`)) {
+		t.Errorf("expected empty results array, got %s", buf.String())
+	}
+}
+
+func TestSARIFLevel(t *testing.T) {
+	tests := []struct {
+		severity int
+		want     string
+	}{
+		{3, "error"},
+		{2, "warning"},
+		{1, "note"},
+		{0, "warning"},
+	}
+
+	for _, tt := range tests {
+		if got := sarifLevel(tt.severity); got != tt.want {
+			t.Errorf("sarifLevel(%d) = %q, want %q", tt.severity, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package state persists the outdated components of a scan run, so the next run can
+// report what became outdated or was resolved since.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+// State is the outdated set recorded by a scan run.
+type State struct {
+	ScannedAt time.Time `json:"scannedAt"`
+	// Outdated holds the keys of the outdated components (see HelmKey and ContainerKey), sorted.
+	Outdated []string `json:"outdated"`
+	// FirstSeen holds when each outdated component was first detected, by key.
+	FirstSeen map[string]time.Time `json:"firstSeen,omitempty"`
+}
+
+// Diff is the change in outdated components between two scan runs.
+type Diff struct {
+	// NewlyOutdated are the components outdated now but not in the previous run.
+	NewlyOutdated []string
+	// Resolved are the components outdated in the previous run but not anymore.
+	Resolved []string
+}
+
+// HelmKey identifies an outdated Helm release across runs.
+func HelmKey(kubeContext string, release nova.ReleaseOutput) string {
+	return fmt.Sprintf("helm/%s/%s/%s", kubeContext, release.Namespace, release.ReleaseName)
+}
+
+// ContainerKey identifies an outdated container image across runs.
+func ContainerKey(kubeContext string, container nova.ContainerOutput) string {
+	return fmt.Sprintf("container/%s/%s", kubeContext, container.Name)
+}
+
+// WorkloadKey identifies an outdated container image in one workload across runs, for
+// container issues filed per workload.
+func WorkloadKey(kubeContext string, container nova.ContainerOutput, workload nova.WorkloadOutput) string {
+	return fmt.Sprintf("%s#%s/%s/%s", ContainerKey(kubeContext, container), workload.Namespace, workload.Kind, workload.Name)
+}
+
+// ContainerKeys returns the keys of an outdated container image: its ContainerKey, or with
+// perWorkload set one WorkloadKey per affected workload, so a workload newly running an
+// already outdated image is new too.
+func ContainerKeys(kubeContext string, container nova.ContainerOutput, perWorkload bool) []string {
+	if !perWorkload || len(container.AffectedWorkloads) == 0 {
+		return []string{ContainerKey(kubeContext, container)}
+	}
+	var keys []string
+	for _, workload := range container.AffectedWorkloads {
+		if key := WorkloadKey(kubeContext, container, workload); !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Keys returns the sorted keys of the outdated components in a scan result, with container
+// images keyed per workload if perWorkload is set (see ContainerKeys).
+func Keys(result *nova.RunResult, perWorkload bool) []string {
+	var keys []string
+	for _, ctx := range result.Contexts {
+		if ctx.Helm != nil {
+			for _, release := range ctx.Helm.Outdated {
+				keys = append(keys, HelmKey(ctx.Context, release))
+			}
+		}
+		if ctx.Containers != nil {
+			for _, container := range ctx.Containers.Outdated {
+				keys = append(keys, ContainerKeys(ctx.Context, container, perWorkload)...)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Load reads the state of the previous run. A missing file yields a nil state and no error.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	return &s, nil
+}
+
+// Save writes the state, replacing the file atomically so a crash never leaves it truncated.
+func Save(path string, s State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// Next returns the state of a run at now with the given outdated keys. Components outdated in
+// the previous state keep their first-seen time; new ones are first seen now. Resolved
+// components are dropped, so they start over if they become outdated again. Components of a
+// previous state without first-seen times count as first seen at its scan.
+func Next(previous *State, keys []string, now time.Time) State {
+	next := State{ScannedAt: now, Outdated: keys, FirstSeen: make(map[string]time.Time, len(keys))}
+	for _, key := range keys {
+		next.FirstSeen[key] = now
+		if previous == nil {
+			continue
+		}
+		if seen, ok := previous.FirstSeen[key]; ok {
+			next.FirstSeen[key] = seen
+		} else if _, found := slices.BinarySearch(previous.Outdated, key); found {
+			next.FirstSeen[key] = previous.ScannedAt
+		}
+	}
+	return next
+}
+
+// Without returns a copy of the state with keys left out of the outdated set, so they are newly
+// outdated again in the next run. They keep their first-seen time.
+func (s State) Without(keys []string) State {
+	if len(keys) == 0 {
+		return s
+	}
+	outdated := make([]string, 0, len(s.Outdated))
+	for _, key := range s.Outdated {
+		if !slices.Contains(keys, key) {
+			outdated = append(outdated, key)
+		}
+	}
+	s.Outdated = outdated
+	return s
+}
+
+// Compare returns the components newly outdated or resolved since the previous state.
+// Without a previous state every outdated component is new.
+func Compare(previous *State, current []string) Diff {
+	before := make(map[string]bool)
+	if previous != nil {
+		for _, key := range previous.Outdated {
+			before[key] = true
+		}
+	}
+	now := make(map[string]bool, len(current))
+	for _, key := range current {
+		now[key] = true
+	}
+
+	var diff Diff
+	for _, key := range current {
+		if !before[key] {
+			diff.NewlyOutdated = append(diff.NewlyOutdated, key)
+		}
+	}
+	for key := range before {
+		if !now[key] {
+			diff.Resolved = append(diff.Resolved, key)
+		}
+	}
+	sort.Strings(diff.NewlyOutdated)
+	sort.Strings(diff.Resolved)
+	return diff
+}
+
+// IsNew reports whether the component with the key is newly outdated.
+func (d Diff) IsNew(key string) bool {
+	i := sort.SearchStrings(d.NewlyOutdated, key)
+	return i < len(d.NewlyOutdated) && d.NewlyOutdated[i] == key
+}
@@ -0,0 +1,205 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/olohmann/nova-automated-cluster-scanner/pkg/nova"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name         string
+		previous     *State
+		current      []string
+		wantNew      []string
+		wantResolved []string
+	}{
+		{
+			name:     "no previous state",
+			previous: nil,
+			current:  []string{"helm/prod/default/app", "container/prod/nginx"},
+			wantNew:  []string{"container/prod/nginx", "helm/prod/default/app"},
+		},
+		{
+			name:         "new and resolved",
+			previous:     &State{Outdated: []string{"helm/prod/default/app", "helm/prod/default/db"}},
+			current:      []string{"helm/prod/default/app", "container/prod/nginx"},
+			wantNew:      []string{"container/prod/nginx"},
+			wantResolved: []string{"helm/prod/default/db"},
+		},
+		{
+			name:     "unchanged",
+			previous: &State{Outdated: []string{"helm/prod/default/app"}},
+			current:  []string{"helm/prod/default/app"},
+		},
+		{
+			name:         "everything resolved",
+			previous:     &State{Outdated: []string{"helm/prod/default/app"}},
+			wantResolved: []string{"helm/prod/default/app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := Compare(tt.previous, tt.current)
+			if !reflect.DeepEqual(diff.NewlyOutdated, tt.wantNew) {
+				t.Errorf("NewlyOutdated = %v, want %v", diff.NewlyOutdated, tt.wantNew)
+			}
+			if !reflect.DeepEqual(diff.Resolved, tt.wantResolved) {
+				t.Errorf("Resolved = %v, want %v", diff.Resolved, tt.wantResolved)
+			}
+			for _, key := range tt.wantNew {
+				if !diff.IsNew(key) {
+					t.Errorf("IsNew(%q) = false, want true", key)
+				}
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	day1 := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+
+	first := Next(nil, []string{"helm/prod/default/app", "helm/prod/default/db"}, day1)
+	want := map[string]time.Time{"helm/prod/default/app": day1, "helm/prod/default/db": day1}
+	if !reflect.DeepEqual(first.FirstSeen, want) {
+		t.Errorf("first run FirstSeen = %v, want %v", first.FirstSeen, want)
+	}
+
+	// app stays outdated, db is resolved and nginx is new
+	second := Next(&first, []string{"container/prod/nginx", "helm/prod/default/app"}, day2)
+	want = map[string]time.Time{"container/prod/nginx": day2, "helm/prod/default/app": day1}
+	if !reflect.DeepEqual(second.FirstSeen, want) {
+		t.Errorf("second run FirstSeen = %v, want %v", second.FirstSeen, want)
+	}
+
+	// db outdated again starts over
+	third := Next(&second, []string{"container/prod/nginx", "helm/prod/default/app", "helm/prod/default/db"}, day3)
+	if got := third.FirstSeen["helm/prod/default/db"]; !got.Equal(day3) {
+		t.Errorf("reappeared component first seen %s, want %s", got, day3)
+	}
+	if age := day3.Sub(third.FirstSeen["helm/prod/default/app"]); age != 48*time.Hour {
+		t.Errorf("app outdated for %s, want 48h", age)
+	}
+}
+
+func TestNext_LegacyState(t *testing.T) {
+	scanned := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	previous := &State{ScannedAt: scanned, Outdated: []string{"helm/prod/default/app"}}
+
+	next := Next(previous, []string{"helm/prod/default/app", "helm/prod/default/db"}, scanned.Add(time.Hour))
+	if got := next.FirstSeen["helm/prod/default/app"]; !got.Equal(scanned) {
+		t.Errorf("expected a component of a state without first-seen times to date from its scan, got %s", got)
+	}
+	if got := next.FirstSeen["helm/prod/default/db"]; !got.Equal(scanned.Add(time.Hour)) {
+		t.Errorf("expected a new component to be first seen now, got %s", got)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	result := &nova.RunResult{Contexts: []nova.ContextResult{
+		{
+			Context: "prod",
+			Helm: &nova.HelmScanResult{Outdated: []nova.ReleaseOutput{
+				{ReleaseName: "app", Namespace: "default"},
+			}},
+			Containers: &nova.ContainerScanResult{Outdated: []nova.ContainerOutput{
+				{Name: "docker.io/library/nginx"},
+			}},
+		},
+		{Context: "staging"}, // failed scan
+	}}
+
+	want := []string{"container/prod/docker.io/library/nginx", "helm/prod/default/app"}
+	if got := Keys(result, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestContainerKeys(t *testing.T) {
+	container := nova.ContainerOutput{Name: "redis", AffectedWorkloads: []nova.WorkloadOutput{
+		{Namespace: "apps", Kind: "StatefulSet", Name: "cache", Container: "redis"},
+		{Namespace: "apps", Kind: "StatefulSet", Name: "cache", Container: "sidecar"},
+		{Namespace: "jobs", Kind: "Deployment", Name: "queue", Container: "redis"},
+	}}
+
+	if got, want := ContainerKeys("prod", container, false), []string{"container/prod/redis"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ContainerKeys() per image = %v, want %v", got, want)
+	}
+	want := []string{"container/prod/redis#apps/StatefulSet/cache", "container/prod/redis#jobs/Deployment/queue"}
+	if got := ContainerKeys("prod", container, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("ContainerKeys() per workload = %v, want %v", got, want)
+	}
+	if got, want := ContainerKeys("prod", nova.ContainerOutput{Name: "redis"}, true), []string{"container/prod/redis"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ContainerKeys() without workloads = %v, want %v", got, want)
+	}
+}
+
+func TestSaveLoad_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	saved := State{
+		ScannedAt: time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+		Outdated:  []string{"helm/prod/default/app"},
+		FirstSeen: map[string]time.Time{"helm/prod/default/app": time.Date(2024, 5, 20, 8, 0, 0, 0, time.UTC)},
+	}
+
+	if err := Save(path, saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(*loaded, saved) {
+		t.Errorf("Load() = %+v, want %+v", *loaded, saved)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the state file to remain, got %d entries", len(entries))
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || s != nil {
+		t.Errorf("Load() = %v, %v; want nil, nil", s, err)
+	}
+}
+
+func TestLoad_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for corrupt state file")
+	}
+}
+
+func TestWithout(t *testing.T) {
+	seen := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	s := State{Outdated: []string{"a", "b", "c"}, FirstSeen: map[string]time.Time{"a": seen, "b": seen, "c": seen}}
+
+	got := s.Without([]string{"b"})
+	if !slices.Equal(got.Outdated, []string{"a", "c"}) {
+		t.Errorf("expected b left out, got %v", got.Outdated)
+	}
+	if !got.FirstSeen["b"].Equal(seen) {
+		t.Error("expected b to keep its first-seen time")
+	}
+	if !slices.Equal(s.Outdated, []string{"a", "b", "c"}) {
+		t.Errorf("expected the original state unchanged, got %v", s.Outdated)
+	}
+	if diff := Compare(&got, []string{"a", "b", "c"}); !diff.IsNew("b") || diff.IsNew("a") {
+		t.Errorf("expected only b to be newly outdated next run, got %v", diff.NewlyOutdated)
+	}
+}
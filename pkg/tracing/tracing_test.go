@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_NoEndpoint(t *testing.T) {
+	before := otel.GetTracerProvider()
+
+	shutdown, err := Setup(context.Background(), "", "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected shutdown error: %v", err)
+	}
+	if otel.GetTracerProvider() != before {
+		t.Error("expected the global tracer provider to be left unchanged without an endpoint")
+	}
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "failing")
+	End(span, errors.New("boom"))
+	_, span = provider.Tracer("test").Start(context.Background(), "succeeding")
+	End(span, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 ended spans, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || len(spans[0].Events) != 1 {
+		t.Errorf("expected error status and event, got %v with %d events", spans[0].Status, len(spans[0].Events))
+	}
+	if spans[1].Status.Code != codes.Unset {
+		t.Errorf("expected unset status, got %v", spans[1].Status)
+	}
+}